@@ -15,6 +15,7 @@ import (
 	"github.com/luisfernandomoraes/order-packing-api/internal/config"
 	"github.com/luisfernandomoraes/order-packing-api/internal/domain"
 	"github.com/luisfernandomoraes/order-packing-api/internal/server"
+	"github.com/luisfernandomoraes/order-packing-api/internal/storage"
 )
 
 // @title Order Packing Calculator API
@@ -33,17 +34,65 @@ import (
 // @schemes http https
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "precompute" {
+		if err := runPrecompute(os.Args[2:]); err != nil {
+			log.Fatalf("❌ Failed to precompute answers: %v", err)
+		}
+		return
+	}
+
 	// Load configuration
 	cfg, err := config.Load()
 	if err != nil {
 		log.Fatalf("❌ Failed to load configuration: %v", err)
 	}
 
+	// Open the pack sizes store and restore the last saved pack sizes
+	repository, err := storage.Open(cfg.PackSizesStore, cfg.PackSizesPath)
+	if err != nil {
+		log.Fatalf("❌ Failed to open pack sizes store: %v", err)
+	}
+
+	current, err := loadPackSizes(repository, cfg.DefaultPackSizes)
+	if err != nil {
+		log.Fatalf("❌ Failed to load pack sizes: %v", err)
+	}
+
 	// Initialize domain services
-	calculator := domain.NewPackCalculator(cfg.DefaultPackSizes)
+	var limiter *domain.Limiter
+	if cfg.CalculationCapacity > 0 {
+		limiter = domain.NewLimiter(int64(cfg.CalculationCapacity), cfg.CalculationQueueTimeout)
+	}
+
+	calculator := domain.NewPackCalculator(current.PackSizes,
+		domain.WithResultCache(cfg.CacheSize),
+		domain.WithLimits(domain.Limits{
+			MaxOrder:      cfg.MaxOrder,
+			MaxIterations: cfg.MaxIterations,
+			MaxDuration:   cfg.MaxComputeTime,
+			Limiter:       limiter,
+		}),
+	)
+	calculator.UpdateConfiguration(domain.Configuration{
+		PackSizes: current.PackSizes,
+		Costs:     domain.Costs{PerPack: current.Costs, PerShipment: current.ShipmentCost},
+		ParcelSpec: domain.ParcelSpec{
+			Weights:   current.Weights,
+			Volumes:   current.Volumes,
+			MaxWeight: current.MaxParcelWeight,
+			MaxVolume: current.MaxParcelVolume,
+		},
+		Rules: packRules(current.Rules),
+	})
+
+	if cfg.AnswerTablePath != "" {
+		if err := loadAnswerTable(calculator, cfg.AnswerTablePath); err != nil {
+			log.Fatalf("❌ Failed to load answer table: %v", err)
+		}
+	}
 
 	// Create and start server
-	srv := server.New(cfg, calculator)
+	srv := server.New(cfg, calculator, server.WithPackSizeRepository(repository))
 
 	// Graceful shutdown
 	quit := make(chan os.Signal, 1)
@@ -51,7 +100,7 @@ func main() {
 
 	go func() {
 		log.Printf("🚀 Server starting on port %s", cfg.Port)
-		log.Printf("📦 Default pack sizes: %v", cfg.DefaultPackSizes)
+		log.Printf("📦 Pack sizes: %v (store: %s)", calculator.GetPackSizes(), cfg.PackSizesStore)
 		log.Printf("🌐 API: http://localhost:%s/api", cfg.Port)
 		log.Printf("📚 Swagger docs: http://localhost:%s/swagger/index.html", cfg.Port)
 		log.Printf("💚 Health: http://localhost:%s/health", cfg.Port)
@@ -76,5 +125,37 @@ func main() {
 	}
 
 	cancel()
+
+	if err := repository.Close(); err != nil {
+		log.Printf("❌ Failed to close pack sizes store: %v", err)
+	}
+
 	log.Println("✅ Server stopped gracefully")
 }
+
+// loadPackSizes returns the current pack sizes version from the repository. When
+// nothing was saved yet, the configured defaults are recorded as version 1.
+func loadPackSizes(repository storage.PackSizeRepository, defaults []int) (storage.PackSizeVersion, error) {
+	current, err := repository.Current()
+	if errors.Is(err, storage.ErrNotFound) {
+		current, err = repository.Append(storage.PackSizeChange{
+			PackSizes: defaults,
+			Author:    "system",
+			Reason:    "Initial pack sizes from DEFAULT_PACK_SIZES",
+		})
+	}
+	if err != nil {
+		return storage.PackSizeVersion{}, err
+	}
+
+	return current, nil
+}
+
+// packRules converts the stored pack size rules for the calculator
+func packRules(stored map[int]storage.PackSizeRule) domain.PackRules {
+	rules := make(domain.PackRules, len(stored))
+	for size, rule := range stored {
+		rules[size] = domain.PackRule(rule)
+	}
+	return rules
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/luisfernandomoraes/order-packing-api/internal/domain"
+)
+
+// runPrecompute implements the precompute subcommand, which writes the answer
+// table of every order up to a limit for a set of pack sizes
+func runPrecompute(args []string) error {
+	flags := flag.NewFlagSet("precompute", flag.ContinueOnError)
+	sizes := flags.String("pack-sizes", "250,500,1000,2000,5000", "comma-separated pack sizes")
+	limit := flags.Int("limit", 100000, "largest order to precompute")
+	output := flags.String("output", "data/answers.bin", "file the answer table is written to")
+
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	packSizes, err := parsePackSizes(*sizes)
+	if err != nil {
+		return err
+	}
+
+	answers, err := domain.NewPackCalculator(packSizes).Precompute(context.Background(), *limit)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+
+	written, err := answers.WriteTo(file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	log.Printf("✅ Precomputed orders 0 to %d for pack sizes %v: %s (%d bytes)", answers.Limit(), answers.PackSizes(), *output, written)
+	return nil
+}
+
+// loadAnswerTable reads the answer table at path into the calculator. A table
+// computed for other pack sizes is skipped, as the pack sizes may have been
+// updated since it was generated.
+func loadAnswerTable(calculator *domain.PackCalculator, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	answers, err := domain.ReadAnswerTable(file)
+	if err != nil {
+		return err
+	}
+
+	if err := calculator.LoadAnswerTable(answers); err != nil {
+		if errors.Is(err, domain.ErrAnswerTableMismatch) {
+			log.Printf("⚠️ Answer table skipped: %v", err)
+			return nil
+		}
+		return err
+	}
+
+	log.Printf("📋 Answer table: orders 0 to %d (%s)", answers.Limit(), path)
+	return nil
+}
+
+// parsePackSizes parses comma-separated positive pack sizes
+func parsePackSizes(value string) ([]int, error) {
+	var packSizes []int
+	for _, part := range strings.Split(value, ",") {
+		size, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || size <= 0 {
+			return nil, fmt.Errorf("pack sizes must be positive integers, got: %q", part)
+		}
+		packSizes = append(packSizes, size)
+	}
+	return packSizes, nil
+}
@@ -25,12 +25,14 @@ const docTemplate = `{
     "paths": {
         "/api/calculate": {
             "post": {
-                "description": "Calculates the best package combination to fulfill an order, minimizing items shipped and number of packages",
+                "description": "Calculates the best package combination to fulfill an order, minimizing items shipped and number of packages.\nWhen a sku is given, the order is calculated with that product's pack sizes instead of the global ones.\nAn optional stock object maps pack sizes to the packs available; sizes missing from it are unlimited. The same rules then apply to the packs in stock only.\nThe objective selects what is optimized: min_items_then_packs (default), min_cost or min_items_then_cost. total_cost prices the packs with the configured pack sizes costs.\nThe strategy ranks combinations of the fewest items: fewest_packs (default), larger_packs, fewest_distinct_sizes, surplus_only or lexicographic: followed by comma-separated keys among packs, distinct_sizes and larger_packs.\nWith alternatives set to K (at most 10), the K next best combinations are listed after the result, ranked by the same objective and strategy.\nWith explain, the response carries a trace of the searched range, the nearest reachable totals, the tie rule and the combinations that were rejected. It is available for the default objective and strategy without stock or inventory.\nWith exact the order fails unless a combination ships exactly the order; max_surplus caps the items above the order instead, and with fallback the best result is returned anyway with surplus_exceeded set. These failures return 422 with code no_exact_fit or surplus_exceeded.\nWith mode under_fulfil the most items not exceeding the order are shipped with the fewest packs, and shortfall reports the items left out; it cannot be combined with exact, max_surplus, alternatives or a cost-aware objective.\nOrders above the configured maximum order, and calculations exceeding the compute budget in iterations or time, return 422 with code order_limit_exceeded or compute_budget_exceeded and the limit in the message.\nPack size rules configured with the pack sizes are always respected; orders they cannot fulfill are rejected with 422.\nWhen the pack sizes have a parcel weight or volume limit, parcels groups the chosen packs into shipping parcels within it, heaviest packs first.\nWith use_inventory the order is planned from the unreserved packs of the inventory; with reserve those packs are also reserved and the response carries the reservation_id.\nA text/csv body of order_id,quantity records (header row optional) is calculated as a whole and answered with CSV columns order_id, quantity, total_items, surplus, total_packs, one pack_\u003csize\u003e column per pack size and error.\nJSON requests sent with \"Accept: text/csv\" receive the same CSV layout with a single record.",
                 "consumes": [
-                    "application/json"
+                    "application/json",
+                    "text/csv"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/csv"
                 ],
                 "tags": [
                     "calculate"
@@ -55,7 +57,16 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Bad Request - Invalid order or negative value",
+                        "description": "Bad Request - Invalid order, negative value, unknown objective or invalid strategy",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Product not found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -71,61 +82,122 @@ const docTemplate = `{
                                 "type": "string"
                             }
                         }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity - Order cannot be calculated with the current pack sizes, rules, stock or parcel limits",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable - Server saturated (see Retry-After), calculation cancelled or timed out",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
                     }
                 }
             }
         },
-        "/api/pack-sizes": {
-            "get": {
-                "description": "Returns the currently configured package sizes",
+        "/api/calculate/batch": {
+            "post": {
+                "description": "Calculates the best package combination for every order in the batch. Orders are computed concurrently from a shared calculation table and each one reports its own result or error, in the same order as the request.\nWhen the server is saturated and the batch cannot be admitted, the whole batch fails with 503 and Retry-After.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "pack-sizes"
+                    "calculate"
+                ],
+                "summary": "Calculate optimal package combinations for many orders",
+                "parameters": [
+                    {
+                        "description": "Orders to calculate",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.BatchCalculateRequest"
+                        }
+                    }
                 ],
-                "summary": "Get current package sizes",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/handlers.PackSizesResponse"
+                            "$ref": "#/definitions/handlers.BatchCalculateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid body, empty or oversized batch",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "405": {
+                        "description": "Method Not Allowed",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable - Server saturated (see Retry-After)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     }
                 }
-            },
+            }
+        },
+        "/api/calculate/stream": {
             "post": {
-                "description": "Updates the available package sizes used for calculations",
+                "description": "Reads one CalculateRequest per line (newline-delimited JSON) and streams back one line per non-blank input line, in the same order, flushing each as soon as it is calculated. Successful lines are CalculateResponse objects; failed lines carry the input line number and the same error message the single calculate endpoint would return.",
                 "consumes": [
-                    "application/json"
+                    "application/x-ndjson"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/x-ndjson"
                 ],
                 "tags": [
-                    "pack-sizes"
+                    "calculate"
                 ],
-                "summary": "Update package sizes",
+                "summary": "Calculate optimal package combinations from an NDJSON stream",
                 "parameters": [
                     {
-                        "description": "New pack sizes",
+                        "description": "One order per line",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handlers.PackSizesRequest"
+                            "$ref": "#/definitions/handlers.CalculateRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "One line per order; failed lines are StreamCalculateError",
                         "schema": {
-                            "$ref": "#/definitions/handlers.PackSizesUpdateResponse"
+                            "$ref": "#/definitions/handlers.CalculateResponse"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request - Empty array or non-positive values",
+                    "405": {
+                        "description": "Method Not Allowed",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -136,19 +208,25 @@ const docTemplate = `{
                 }
             }
         },
-        "/health": {
+        "/api/inventory": {
             "get": {
-                "description": "Returns the health status of the API",
+                "description": "Returns the packs on hand, reserved and available for every pack size ever received. The inventory is kept in memory and is lost on restart.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "health"
+                    "inventory"
                 ],
-                "summary": "Health check endpoint",
+                "summary": "Get stock levels",
                 "responses": {
                     "200": {
-                        "description": "status: healthy",
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.InventoryResponse"
+                        }
+                    },
+                    "405": {
+                        "description": "Method Not Allowed",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -158,104 +236,1642 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "handlers.CalculateRequest": {
-            "type": "object",
-            "properties": {
-                "order": {
-                    "type": "integer",
-                    "minimum": 0,
-                    "example": 501
-                }
-            }
         },
-        "handlers.CalculateResponse": {
-            "type": "object",
-            "properties": {
-                "order": {
-                    "type": "integer",
-                    "example": 501
-                },
-                "pack_sizes": {
-                    "type": "array",
-                    "items": {
-                        "type": "integer"
+        "/api/inventory/receive": {
+            "post": {
+                "description": "Adds packs of a size to the stock on hand and returns the new stock levels",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "inventory"
+                ],
+                "summary": "Receive stock",
+                "parameters": [
+                    {
+                        "description": "Packs received",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ReceiveStockRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.InventoryResponse"
+                        }
                     },
-                    "example": [
-                        250,
-                        500,
-                        1000,
-                        2000,
-                        5000
-                    ]
-                },
-                "packs": {
-                    "type": "object",
-                    "additionalProperties": {
-                        "type": "integer"
+                    "400": {
+                        "description": "Bad Request - Invalid body or non-positive pack size or count",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
                     },
-                    "example": {
-                        "250": 1,
-                        "500": 1
+                    "405": {
+                        "description": "Method Not Allowed",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
                     }
-                },
-                "surplus": {
-                    "type": "integer",
-                    "example": 249
-                },
-                "total_items": {
-                    "type": "integer",
-                    "example": 750
-                },
-                "total_packs": {
-                    "type": "integer",
-                    "example": 2
                 }
             }
         },
-        "handlers.PackSizesRequest": {
-            "type": "object",
-            "properties": {
-                "pack_sizes": {
-                    "type": "array",
-                    "items": {
-                        "type": "integer"
+        "/api/inventory/reservations": {
+            "get": {
+                "description": "Returns the reservations that are neither confirmed nor released, oldest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "inventory"
+                ],
+                "summary": "List reservations",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ReservationsResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Reserves the packs of a calculated result. Either every pack is reserved or none is, so concurrent reservations can never claim the same packs.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "inventory"
+                ],
+                "summary": "Reserve packs",
+                "parameters": [
+                    {
+                        "description": "Packs to reserve",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ReservationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ReservationResponse"
+                        }
                     },
-                    "example": [
-                        100,
-                        250,
-                        500,
-                        1000
-                    ]
+                    "400": {
+                        "description": "Bad Request - Invalid body, empty reservation or negative counts",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Not enough packs available",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
                 }
             }
         },
-        "handlers.PackSizesResponse": {
-            "type": "object",
-            "properties": {
-                "pack_sizes": {
-                    "type": "array",
-                    "items": {
-                        "type": "integer"
-                    },
-                    "example": [
-                        250,
+        "/api/inventory/reservations/{id}/confirm": {
+            "post": {
+                "description": "Settles a reservation as shipped, removing its packs from the stock on hand",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "inventory"
+                ],
+                "summary": "Confirm a reservation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Reservation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ReservationResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Reservation not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "405": {
+                        "description": "Method Not Allowed",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/inventory/reservations/{id}/release": {
+            "post": {
+                "description": "Cancels a reservation, making its packs available again",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "inventory"
+                ],
+                "summary": "Release a reservation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Reservation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ReservationResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Reservation not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "405": {
+                        "description": "Method Not Allowed",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/orders": {
+            "post": {
+                "description": "Packs every line of a customer order and returns the per-line results with order-level totals. Each line is packed with its own pack_sizes, with the pack sizes of its sku, or with the global pack sizes.\nThe order succeeds or fails as a whole: the first line that cannot be packed fails the request and the error names the line, numbered from 1.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Calculate optimal package combinations for a multi-line order",
+                "parameters": [
+                    {
+                        "description": "Order lines",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.OrderRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.OrderResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid body, empty or oversized order, invalid line",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Product not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "405": {
+                        "description": "Method Not Allowed",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity - A line cannot be packed",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable - Calculation cancelled or timed out",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/pack-sizes": {
+            "get": {
+                "description": "Returns the currently configured package sizes, their costs, weights, volumes and rules, and the parcel limits",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "pack-sizes"
+                ],
+                "summary": "Get current package sizes",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.PackSizesResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Updates the available package sizes used for calculations and records them as a new version.\nCosts and shipment_cost replace the current costs; sizes without a cost cost nothing.\nWeights, volumes, max_parcel_weight and max_parcel_volume replace the parcel limits; with a limit, calculations group the packs into parcels.\nRules replace the pack size rules: max_packs caps the packs of a size per order, and min_order and max_order bound the orders it may be used for.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "pack-sizes"
+                ],
+                "summary": "Update package sizes",
+                "parameters": [
+                    {
+                        "description": "New pack sizes",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.PackSizesRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.PackSizesUpdateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Empty array, non-positive values, invalid costs, invalid parcel limits or contradictory rules",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Pack sizes could not be saved",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/pack-sizes/diff": {
+            "get": {
+                "description": "Returns the pack sizes added, removed and kept when going from one version to another, and every cost, weight, volume, parcel limit and rule that changed",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "pack-sizes"
+                ],
+                "summary": "Compare two pack sizes versions",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Version to compare from",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Version to compare to",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.PackSizesDiffResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Missing or invalid version numbers",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Version not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "405": {
+                        "description": "Method Not Allowed",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/pack-sizes/rollback": {
+            "post": {
+                "description": "Restores the pack sizes, costs, parcel limits and rules of a previous version. The rollback is recorded as a new version, so the history is never rewritten.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "pack-sizes"
+                ],
+                "summary": "Roll back to a previous pack sizes version",
+                "parameters": [
+                    {
+                        "description": "Version to restore",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.PackSizesRollbackRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.PackSizesUpdateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid request body",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Version not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "405": {
+                        "description": "Method Not Allowed",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Pack sizes could not be saved",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/pack-sizes/versions": {
+            "get": {
+                "description": "Returns every recorded version of the pack sizes with its costs, parcel limits and rules, oldest first, and the current version number",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "pack-sizes"
+                ],
+                "summary": "List pack sizes versions",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.PackSizesVersionsResponse"
+                        }
+                    },
+                    "405": {
+                        "description": "Method Not Allowed",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Versions could not be loaded",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/products": {
+            "get": {
+                "description": "Returns every product in the catalog with its pack sizes, sorted by SKU",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "List products",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ProductsResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Adds a product to the catalog with its own pack sizes",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Create a product",
+                "parameters": [
+                    {
+                        "description": "New product",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ProductRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ProductResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid SKU or pack sizes",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Product already exists",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/products/{sku}": {
+            "get": {
+                "description": "Returns a product and its pack sizes",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Get a product",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Product SKU",
+                        "name": "sku",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ProductResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Product not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Removes a product from the catalog",
+                "tags": [
+                    "products"
+                ],
+                "summary": "Delete a product",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Product SKU",
+                        "name": "sku",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Product not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/products/{sku}/pack-sizes": {
+            "get": {
+                "description": "Returns the package sizes of a product",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Get product package sizes",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Product SKU",
+                        "name": "sku",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.PackSizesResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Product not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Replaces the package sizes of a product. Other products and the global pack sizes are not affected. Products have no costs, parcel limits or rules.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Update product package sizes",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Product SKU",
+                        "name": "sku",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New pack sizes",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ProductPackSizesRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.PackSizesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Empty array or non-positive values",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Product not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/health": {
+            "get": {
+                "description": "Returns the health status of the API",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Health check endpoint",
+                "responses": {
+                    "200": {
+                        "description": "status: healthy",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/metrics": {
+            "get": {
+                "description": "Returns the usage of the calculation limiter and the result cache in the Prometheus text format",
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Calculation metrics",
+                "responses": {
+                    "200": {
+                        "description": "Metrics",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "405": {
+                        "description": "Method Not Allowed",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "handlers.AlternativeResponse": {
+            "type": "object",
+            "properties": {
+                "packs": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    },
+                    "example": {
+                        "250": 2
+                    }
+                },
+                "surplus": {
+                    "type": "integer",
+                    "example": 249
+                },
+                "total_cost": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "total_items": {
+                    "type": "integer",
+                    "example": 500
+                },
+                "total_packs": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "handlers.BatchCalculateItem": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "Order must be positive"
+                },
+                "reference_id": {
+                    "type": "string",
+                    "example": "PO-1001"
+                },
+                "result": {
+                    "$ref": "#/definitions/handlers.CalculateResponse"
+                }
+            }
+        },
+        "handlers.BatchCalculateRequest": {
+            "type": "object",
+            "properties": {
+                "orders": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.BatchOrder"
+                    }
+                }
+            }
+        },
+        "handlers.BatchCalculateResponse": {
+            "type": "object",
+            "properties": {
+                "failed": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.BatchCalculateItem"
+                    }
+                },
+                "succeeded": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "handlers.BatchOrder": {
+            "type": "object",
+            "properties": {
+                "order": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 501
+                },
+                "reference_id": {
+                    "type": "string",
+                    "example": "PO-1001"
+                }
+            }
+        },
+        "handlers.CalculateRequest": {
+            "type": "object",
+            "properties": {
+                "alternatives": {
+                    "type": "integer",
+                    "maximum": 10,
+                    "minimum": 0,
+                    "example": 2
+                },
+                "exact": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "explain": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "fallback": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "max_surplus": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 250
+                },
+                "mode": {
+                    "type": "string",
+                    "enum": [
+                        "fulfil",
+                        "under_fulfil"
+                    ],
+                    "example": "fulfil"
+                },
+                "objective": {
+                    "type": "string",
+                    "enum": [
+                        "min_items_then_packs",
+                        "min_cost",
+                        "min_items_then_cost"
+                    ],
+                    "example": "min_items_then_packs"
+                },
+                "order": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 501
+                },
+                "reserve": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "sku": {
+                    "type": "string",
+                    "example": "WIDGET-01"
+                },
+                "stock": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    },
+                    "example": {
+                        "2000": 3,
+                        "5000": 1
+                    }
+                },
+                "strategy": {
+                    "type": "string",
+                    "example": "fewest_distinct_sizes"
+                },
+                "use_inventory": {
+                    "type": "boolean",
+                    "example": false
+                }
+            }
+        },
+        "handlers.CalculateResponse": {
+            "type": "object",
+            "properties": {
+                "alternatives": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.AlternativeResponse"
+                    }
+                },
+                "explanation": {
+                    "$ref": "#/definitions/handlers.ExplanationResponse"
+                },
+                "order": {
+                    "type": "integer",
+                    "example": 501
+                },
+                "pack_sizes": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        250,
+                        500,
+                        1000,
+                        2000,
+                        5000
+                    ]
+                },
+                "packs": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    },
+                    "example": {
+                        "250": 1,
+                        "500": 1
+                    }
+                },
+                "parcels": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.ParcelResponse"
+                    }
+                },
+                "reservation_id": {
+                    "type": "string",
+                    "example": "rsv-1"
+                },
+                "shortfall": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "surplus": {
+                    "type": "integer",
+                    "example": 249
+                },
+                "surplus_exceeded": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "total_cost": {
+                    "type": "integer",
+                    "example": 320
+                },
+                "total_items": {
+                    "type": "integer",
+                    "example": 750
+                },
+                "total_packs": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "total_parcels": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "handlers.ExplainedCandidateResponse": {
+            "type": "object",
+            "properties": {
+                "packs": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    },
+                    "example": {
+                        "2000": 1,
+                        "250": 1,
+                        "5000": 2
+                    }
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "chosen"
+                },
+                "total_items": {
+                    "type": "integer",
+                    "example": 12250
+                },
+                "total_packs": {
+                    "type": "integer",
+                    "example": 4
+                }
+            }
+        },
+        "handlers.ExplanationResponse": {
+            "type": "object",
+            "properties": {
+                "candidates": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.ExplainedCandidateResponse"
+                    }
+                },
+                "largest_packs_added": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "nearest_above": {
+                    "type": "integer",
+                    "example": 12500
+                },
+                "nearest_below": {
+                    "type": "integer",
+                    "example": 12000
+                },
+                "search_from": {
+                    "type": "integer",
+                    "example": 12001
+                },
+                "search_to": {
+                    "type": "integer",
+                    "example": 17001
+                },
+                "tie_rule": {
+                    "type": "string",
+                    "example": "fewest items, then fewest packs, then larger packs"
+                }
+            }
+        },
+        "handlers.InventoryResponse": {
+            "type": "object",
+            "properties": {
+                "levels": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.StockLevelResponse"
+                    }
+                }
+            }
+        },
+        "handlers.OrderLine": {
+            "type": "object",
+            "properties": {
+                "pack_sizes": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        250,
+                        500,
+                        1000
+                    ]
+                },
+                "quantity": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 501
+                },
+                "sku": {
+                    "type": "string",
+                    "example": "WIDGET-01"
+                }
+            }
+        },
+        "handlers.OrderLineResponse": {
+            "type": "object",
+            "properties": {
+                "alternatives": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.AlternativeResponse"
+                    }
+                },
+                "explanation": {
+                    "$ref": "#/definitions/handlers.ExplanationResponse"
+                },
+                "line": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "order": {
+                    "type": "integer",
+                    "example": 501
+                },
+                "pack_sizes": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        250,
+                        500,
+                        1000,
+                        2000,
+                        5000
+                    ]
+                },
+                "packs": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    },
+                    "example": {
+                        "250": 1,
+                        "500": 1
+                    }
+                },
+                "parcels": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.ParcelResponse"
+                    }
+                },
+                "reservation_id": {
+                    "type": "string",
+                    "example": "rsv-1"
+                },
+                "shortfall": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "sku": {
+                    "type": "string",
+                    "example": "WIDGET-01"
+                },
+                "surplus": {
+                    "type": "integer",
+                    "example": 249
+                },
+                "surplus_exceeded": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "total_cost": {
+                    "type": "integer",
+                    "example": 320
+                },
+                "total_items": {
+                    "type": "integer",
+                    "example": 750
+                },
+                "total_packs": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "total_parcels": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "handlers.OrderRequest": {
+            "type": "object",
+            "properties": {
+                "lines": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.OrderLine"
+                    }
+                }
+            }
+        },
+        "handlers.OrderResponse": {
+            "type": "object",
+            "properties": {
+                "lines": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.OrderLineResponse"
+                    }
+                },
+                "total_cost": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "total_items": {
+                    "type": "integer",
+                    "example": 1013
+                },
+                "total_packs": {
+                    "type": "integer",
+                    "example": 9
+                },
+                "total_quantity": {
+                    "type": "integer",
+                    "example": 764
+                },
+                "total_surplus": {
+                    "type": "integer",
+                    "example": 249
+                }
+            }
+        },
+        "handlers.PackSizeRule": {
+            "type": "object",
+            "properties": {
+                "max_order": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "max_packs": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "min_order": {
+                    "type": "integer",
+                    "example": 10001
+                }
+            }
+        },
+        "handlers.PackSizesChange": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string",
+                    "example": "costs"
+                },
+                "from": {},
+                "size": {
+                    "type": "integer",
+                    "example": 250
+                },
+                "to": {}
+            }
+        },
+        "handlers.PackSizesDiffResponse": {
+            "type": "object",
+            "properties": {
+                "added": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        100
+                    ]
+                },
+                "changes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.PackSizesChange"
+                    }
+                },
+                "from": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "removed": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        5000
+                    ]
+                },
+                "to": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "unchanged": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        250,
+                        500,
+                        1000,
+                        2000
+                    ]
+                }
+            }
+        },
+        "handlers.PackSizesRequest": {
+            "type": "object",
+            "properties": {
+                "author": {
+                    "type": "string",
+                    "example": "jane.doe"
+                },
+                "costs": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    },
+                    "example": {
+                        "100": 60,
+                        "250": 120
+                    }
+                },
+                "max_parcel_volume": {
+                    "type": "integer",
+                    "example": 60000
+                },
+                "max_parcel_weight": {
+                    "type": "integer",
+                    "example": 31500
+                },
+                "pack_sizes": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        100,
+                        250,
+                        500,
+                        1000
+                    ]
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "New 100 item box"
+                },
+                "rules": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/handlers.PackSizeRule"
+                    }
+                },
+                "shipment_cost": {
+                    "type": "integer",
+                    "example": 500
+                },
+                "volumes": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    },
+                    "example": {
+                        "100": 1500,
+                        "250": 4000
+                    }
+                },
+                "weights": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    },
+                    "example": {
+                        "100": 1200,
+                        "250": 3000
+                    }
+                }
+            }
+        },
+        "handlers.PackSizesResponse": {
+            "type": "object",
+            "properties": {
+                "costs": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    },
+                    "example": {
+                        "250": 120,
+                        "500": 200
+                    }
+                },
+                "max_parcel_volume": {
+                    "type": "integer",
+                    "example": 60000
+                },
+                "max_parcel_weight": {
+                    "type": "integer",
+                    "example": 31500
+                },
+                "pack_sizes": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        250,
+                        500,
+                        1000,
+                        2000,
+                        5000
+                    ]
+                },
+                "rules": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/handlers.PackSizeRule"
+                    }
+                },
+                "shipment_cost": {
+                    "type": "integer",
+                    "example": 500
+                },
+                "volumes": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    },
+                    "example": {
+                        "250": 4000,
+                        "500": 7500
+                    }
+                },
+                "weights": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    },
+                    "example": {
+                        "250": 3000,
+                        "500": 5500
+                    }
+                }
+            }
+        },
+        "handlers.PackSizesRollbackRequest": {
+            "type": "object",
+            "properties": {
+                "author": {
+                    "type": "string",
+                    "example": "jane.doe"
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "New sizes broke the warehouse labels"
+                },
+                "version": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "handlers.PackSizesUpdateResponse": {
+            "type": "object",
+            "properties": {
+                "costs": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    },
+                    "example": {
+                        "250": 120,
+                        "500": 200
+                    }
+                },
+                "max_parcel_volume": {
+                    "type": "integer",
+                    "example": 60000
+                },
+                "max_parcel_weight": {
+                    "type": "integer",
+                    "example": 31500
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Pack sizes updated successfully"
+                },
+                "pack_sizes": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        250,
                         500,
                         1000,
                         2000,
                         5000
                     ]
+                },
+                "rules": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/handlers.PackSizeRule"
+                    }
+                },
+                "shipment_cost": {
+                    "type": "integer",
+                    "example": 500
+                },
+                "version": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "volumes": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    },
+                    "example": {
+                        "250": 4000,
+                        "500": 7500
+                    }
+                },
+                "weights": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    },
+                    "example": {
+                        "250": 3000,
+                        "500": 5500
+                    }
                 }
             }
         },
-        "handlers.PackSizesUpdateResponse": {
+        "handlers.PackSizesVersion": {
             "type": "object",
             "properties": {
-                "message": {
+                "author": {
                     "type": "string",
-                    "example": "Pack sizes updated successfully"
+                    "example": "jane.doe"
+                },
+                "costs": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    },
+                    "example": {
+                        "250": 120,
+                        "500": 200
+                    }
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2025-01-31T12:00:00Z"
+                },
+                "max_parcel_volume": {
+                    "type": "integer",
+                    "example": 60000
+                },
+                "max_parcel_weight": {
+                    "type": "integer",
+                    "example": 31500
                 },
                 "pack_sizes": {
                     "type": "array",
@@ -269,6 +1885,244 @@ const docTemplate = `{
                         2000,
                         5000
                     ]
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "New 100 item box"
+                },
+                "rules": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/handlers.PackSizeRule"
+                    }
+                },
+                "shipment_cost": {
+                    "type": "integer",
+                    "example": 500
+                },
+                "version": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "volumes": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    },
+                    "example": {
+                        "250": 4000,
+                        "500": 7500
+                    }
+                },
+                "weights": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    },
+                    "example": {
+                        "250": 3000,
+                        "500": 5500
+                    }
+                }
+            }
+        },
+        "handlers.PackSizesVersionsResponse": {
+            "type": "object",
+            "properties": {
+                "current": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "versions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.PackSizesVersion"
+                    }
+                }
+            }
+        },
+        "handlers.ParcelResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "packs": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    },
+                    "example": {
+                        "250": 1,
+                        "500": 1
+                    }
+                },
+                "volume": {
+                    "type": "integer",
+                    "example": 11500
+                },
+                "weight": {
+                    "type": "integer",
+                    "example": 8500
+                }
+            }
+        },
+        "handlers.ProductPackSizesRequest": {
+            "type": "object",
+            "properties": {
+                "pack_sizes": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        23,
+                        31,
+                        53
+                    ]
+                }
+            }
+        },
+        "handlers.ProductRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "Widget"
+                },
+                "pack_sizes": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        23,
+                        31,
+                        53
+                    ]
+                },
+                "sku": {
+                    "type": "string",
+                    "example": "WIDGET-01"
+                }
+            }
+        },
+        "handlers.ProductResponse": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "Widget"
+                },
+                "pack_sizes": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        23,
+                        31,
+                        53
+                    ]
+                },
+                "sku": {
+                    "type": "string",
+                    "example": "WIDGET-01"
+                }
+            }
+        },
+        "handlers.ProductsResponse": {
+            "type": "object",
+            "properties": {
+                "products": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.ProductResponse"
+                    }
+                }
+            }
+        },
+        "handlers.ReceiveStockRequest": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "pack_size": {
+                    "type": "integer",
+                    "example": 500
+                }
+            }
+        },
+        "handlers.ReservationRequest": {
+            "type": "object",
+            "properties": {
+                "packs": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    },
+                    "example": {
+                        "250": 1,
+                        "500": 1
+                    }
+                }
+            }
+        },
+        "handlers.ReservationResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2025-01-31T12:00:00Z"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "rsv-1"
+                },
+                "packs": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    },
+                    "example": {
+                        "250": 1,
+                        "500": 1
+                    }
+                }
+            }
+        },
+        "handlers.ReservationsResponse": {
+            "type": "object",
+            "properties": {
+                "reservations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.ReservationResponse"
+                    }
+                }
+            }
+        },
+        "handlers.StockLevelResponse": {
+            "type": "object",
+            "properties": {
+                "available": {
+                    "type": "integer",
+                    "example": 8
+                },
+                "on_hand": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "pack_size": {
+                    "type": "integer",
+                    "example": 500
+                },
+                "reserved": {
+                    "type": "integer",
+                    "example": 2
                 }
             }
         }
@@ -9,6 +9,8 @@ import (
 	"time"
 
 	"github.com/joho/godotenv"
+
+	"github.com/luisfernandomoraes/order-packing-api/internal/domain"
 )
 
 // Config holds the application configuration
@@ -19,6 +21,17 @@ type Config struct {
 	WriteTimeout     time.Duration
 	IdleTimeout      time.Duration
 	LogLevel         string
+	PackSizesStore   string
+	PackSizesPath    string
+	DefaultStrategy  string
+	CacheSize        int
+	AnswerTablePath  string
+	MaxOrder         int
+	MaxIterations    int
+	MaxComputeTime   time.Duration
+
+	CalculationCapacity     int
+	CalculationQueueTimeout time.Duration
 }
 
 // Load configuration from environment variables
@@ -33,6 +46,17 @@ func Load() (Config, error) {
 		WriteTimeout:     parseDuration(getEnv("WRITE_TIMEOUT", "10s")),
 		IdleTimeout:      parseDuration(getEnv("IDLE_TIMEOUT", "60s")),
 		LogLevel:         getEnv("LOG_LEVEL", "info"),
+		PackSizesStore:   getEnv("PACK_SIZES_STORE", "file"),
+		PackSizesPath:    getEnv("PACK_SIZES_PATH", "data/pack-sizes.json"),
+		DefaultStrategy:  getEnv("DEFAULT_STRATEGY", "fewest_packs"),
+		CacheSize:        parseInt(getEnv("CACHE_SIZE", "10000"), 10000),
+		AnswerTablePath:  getEnv("ANSWER_TABLE_PATH", ""),
+		MaxOrder:         parseInt(getEnv("MAX_ORDER", "1000000000"), 1_000_000_000),
+		MaxIterations:    parseInt(getEnv("MAX_ITERATIONS", "100000000"), 100_000_000),
+		MaxComputeTime:   parseDuration(getEnv("MAX_COMPUTE_TIME", "5s")),
+
+		CalculationCapacity:     parseInt(getEnv("CALCULATION_CAPACITY", "67108864"), 1<<26),
+		CalculationQueueTimeout: parseDuration(getEnv("CALCULATION_QUEUE_TIMEOUT", "2s")),
 	}
 
 	if err := cfg.Validate(); err != nil {
@@ -58,6 +82,32 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	switch c.PackSizesStore {
+	case "none":
+	case "file", "bolt":
+		if c.PackSizesPath == "" {
+			return fmt.Errorf("PACK_SIZES_PATH cannot be empty")
+		}
+	default:
+		return fmt.Errorf("PACK_SIZES_STORE must be none, file or bolt, got: %s", c.PackSizesStore)
+	}
+
+	if _, err := domain.ParseStrategy(c.DefaultStrategy); err != nil {
+		return fmt.Errorf("DEFAULT_STRATEGY is not a valid strategy: %w", err)
+	}
+
+	if c.CacheSize < 0 {
+		return fmt.Errorf("CACHE_SIZE must not be negative, got: %d", c.CacheSize)
+	}
+
+	if c.MaxOrder < 0 || c.MaxIterations < 0 || c.MaxComputeTime < 0 {
+		return fmt.Errorf("MAX_ORDER, MAX_ITERATIONS and MAX_COMPUTE_TIME must not be negative")
+	}
+
+	if c.CalculationCapacity < 0 || c.CalculationQueueTimeout < 0 {
+		return fmt.Errorf("CALCULATION_CAPACITY and CALCULATION_QUEUE_TIMEOUT must not be negative")
+	}
+
 	return nil
 }
 
@@ -81,6 +131,14 @@ func parsePackSizes(value string) []int {
 	return sizes
 }
 
+func parseInt(value string, defaultValue int) int {
+	number, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return defaultValue
+	}
+	return number
+}
+
 func parseDuration(value string) time.Duration {
 	duration, err := time.ParseDuration(value)
 	if err != nil {
@@ -0,0 +1,438 @@
+package domain
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"math"
+	"slices"
+)
+
+// Alternatives returns up to k distinct pack combinations that fulfill the order,
+// best first, ranked by the objective and strategy of the options and drawing on
+// their stock within the pack size rules. Combinations above the maximum surplus
+// of the options are left out.
+//
+// Only combinations from which no pack can be removed are ranked: removing a pack
+// never ships more items, costs more or uses more packs, so the others never rank
+// first. With the default objective and a strategy without distinct sizes they
+// are found from tables, see tableAlternatives. Other rankings visit every such
+// combination, and orders with more than maxStrategyCombinations of them fail
+// with ErrOrderTooLarge.
+func (pc *PackCalculator) Alternatives(ctx context.Context, order int, opts CalculateOptions, k int) ([]PackResult, error) {
+	ctx, cancel, err := pc.bound(ctx, order)
+	defer cancel()
+	if err != nil {
+		return nil, err
+	}
+
+	if !opts.Objective.valid() {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidObjective, opts.Objective)
+	}
+
+	if opts.Strategy != nil && opts.Objective.costAware() {
+		return nil, fmt.Errorf("%w: strategies only apply to the %s objective", ErrInvalidStrategy, ObjectiveMinItemsThenPacks)
+	}
+
+	if opts.Mode == ModeUnderFulfil {
+		return nil, fmt.Errorf("%w: alternatives are only ranked for %s", ErrInvalidMode, ModeFulfil)
+	}
+
+	if order < 0 {
+		return nil, ErrInvalidOrder
+	}
+
+	for _, count := range opts.Stock {
+		if count < 0 {
+			return nil, ErrInvalidStock
+		}
+	}
+
+	packSizes, costs := pc.configuration()
+	opts.Stock = pc.GetRules().limit(order, opts.Stock)
+
+	if order == 0 || k <= 0 {
+		return []PackResult{}, nil
+	}
+
+	if len(packSizes) == 0 {
+		return nil, ErrNoPackSizes
+	}
+
+	if packsFirst, ok := opts.tableRanking(); ok {
+		return tableAlternatives(ctx, order, packSizes, costs, opts, k, packsFirst)
+	}
+
+	less := opts.less()
+	sizes := slices.Compact(slices.Clone(packSizes))
+	counts := make([]int, len(sizes))
+	best := make([]PackResult, 0, k)
+	visited := 0
+
+	consider := func(total int) {
+		if opts.MaxSurplus != nil && total-order > *opts.MaxSurplus {
+			return
+		}
+
+		candidate := PackResult{
+			Order:      order,
+			TotalItems: total,
+			Packs:      make(map[int]int),
+			PackSizes:  packSizes,
+		}
+		for i, count := range counts {
+			if count > 0 {
+				candidate.Packs[sizes[i]] = count
+			}
+		}
+		candidate.TotalCost = costs.Total(candidate.Packs)
+
+		position := len(best)
+		for position > 0 && less(candidate, best[position-1]) {
+			position--
+		}
+		if position == k {
+			return
+		}
+		if len(best) == k {
+			best = best[:k-1]
+		}
+		best = slices.Insert(best, position, candidate)
+	}
+
+	// walk chooses the count of each size from the largest down. Once the order
+	// is covered no further packs are added, and the combination is kept only if
+	// its smallest pack cannot be removed.
+	var walk func(index, total, smallest int) error
+	walk = func(index, total, smallest int) error {
+		visited++
+		if visited > maxStrategyCombinations {
+			return fmt.Errorf("%w: more than %d combinations to rank", ErrOrderTooLarge, maxStrategyCombinations)
+		}
+		if visited%cancellationCheckInterval == 0 {
+			if err := spend(ctx, cancellationCheckInterval); err != nil {
+				return err
+			}
+		}
+
+		if total >= order {
+			if total-smallest < order {
+				consider(total)
+			}
+			return nil
+		}
+
+		if index < 0 {
+			return nil
+		}
+
+		size := sizes[index]
+		most := (order - total + size - 1) / size
+		if limit, limited := opts.Stock[size]; limited {
+			most = min(most, limit)
+		}
+
+		for count := most; count >= 0; count-- {
+			counts[index] = count
+
+			next := smallest
+			if count > 0 {
+				next = size
+			}
+			if err := walk(index-1, total+count*size, next); err != nil {
+				return err
+			}
+		}
+		counts[index] = 0
+
+		return nil
+	}
+
+	if err := walk(len(sizes)-1, 0, 0); err != nil {
+		return nil, err
+	}
+
+	return best, nil
+}
+
+// less returns the ranking of the options: the strategy for
+// ObjectiveMinItemsThenPacks, or the cost and items in the order of a
+// cost-aware objective, then the fewest packs.
+func (opts CalculateOptions) less() func(a, b PackResult) bool {
+	switch opts.Objective {
+	case ObjectiveMinCost:
+		return func(a, b PackResult) bool {
+			if a.TotalCost != b.TotalCost {
+				return a.TotalCost < b.TotalCost
+			}
+			return FewestPacks.Less(a, b)
+		}
+	case ObjectiveMinItemsThenCost:
+		return func(a, b PackResult) bool {
+			if a.TotalItems != b.TotalItems {
+				return a.TotalItems < b.TotalItems
+			}
+			if a.TotalCost != b.TotalCost {
+				return a.TotalCost < b.TotalCost
+			}
+			return a.GetTotalPackCount() < b.GetTotalPackCount()
+		}
+	}
+
+	if opts.Strategy != nil {
+		return opts.Strategy.Less
+	}
+	return FewestPacks.Less
+}
+
+// tableRanking reports whether the options rank combinations by the fewest items,
+// then by keys that add up pack by pack, and whether the fewest packs come before
+// larger packs, as in rankLexicographic.
+func (opts CalculateOptions) tableRanking() (packsFirst, ok bool) {
+	if opts.Objective.costAware() {
+		return false, false
+	}
+
+	if opts.Strategy == nil {
+		return true, true
+	}
+
+	l, lexical := opts.Strategy.(lexicographic)
+	if !lexical || slices.Contains(l.keys, KeyDistinctSizes) {
+		return false, false
+	}
+
+	return !l.largerFirst(), true
+}
+
+// tableAlternatives returns the k best combinations from which no pack can be
+// removed for an order ranked by the fewest items, then by the fewest packs when
+// packsFirst is set, then by larger packs.
+//
+// Such combinations ship less than the order plus their smallest pack, so every
+// total from the order up is searched in turn using only the sizes above its
+// surplus, until k combinations are found. The combinations of a total are
+// listed best first by searchCombinations.
+//
+// When the largest size L is unlimited, the order is reduced like reduceOrder
+// with k times the margin: a combination holding k(L/g) smaller packs contains k
+// disjoint groups that can each be swapped for fewer packs of size L, giving k
+// better combinations of the same total. The k best therefore hold fewer smaller
+// packs, and at least as many packs of size L as the reduction removes.
+func tableAlternatives(ctx context.Context, order int, packSizes []int, costs Costs, opts CalculateOptions, k int, packsFirst bool) ([]PackResult, error) {
+	var sizes, bounds []int
+	for _, size := range slices.Compact(slices.Clone(packSizes)) {
+		bound := -1
+		if count, limited := opts.Stock[size]; limited {
+			bound = count
+		}
+		if bound != 0 {
+			sizes = append(sizes, size)
+			bounds = append(bounds, bound)
+		}
+	}
+
+	if len(sizes) == 0 {
+		return []PackResult{}, nil
+	}
+
+	largestPack := sizes[len(sizes)-1]
+	reduced, largestPacks := order, 0
+	if bounds[len(bounds)-1] < 0 {
+		if margin := alternativesMargin(sizes, k); order > margin {
+			largestPacks = (order - margin - 1) / largestPack
+			reduced = order - largestPacks*largestPack
+		}
+	}
+
+	limit := reduced + largestPack - 1
+	if opts.MaxSurplus != nil {
+		limit = min(limit, reduced+*opts.MaxSurplus)
+	}
+	if limit > maxTableSize {
+		return nil, fmt.Errorf("%w: search range exceeds %d quantities", ErrOrderTooLarge, maxTableSize)
+	}
+
+	best := make([]PackResult, 0, k)
+	first := -1
+	var tables [][]int32
+
+	for total := reduced; total <= limit && len(best) < k; total++ {
+		// Only sizes above the surplus leave no pack that can be removed.
+		smallest, _ := slices.BinarySearch(sizes, total-reduced+1)
+		if smallest == len(sizes) {
+			break
+		}
+
+		if smallest != first {
+			first = smallest
+			if err := spend(ctx, (len(sizes)-first)*(limit+1)); err != nil {
+				return nil, err
+			}
+			if err := admit(ctx, (len(sizes)-first)*(limit+1)); err != nil {
+				return nil, err
+			}
+
+			var err error
+			if tables, err = fewestPacksTables(ctx, sizes[first:], bounds[first:], limit); err != nil {
+				return nil, err
+			}
+		}
+
+		combinations, err := searchCombinations(ctx, sizes[first:], bounds[first:], tables, total, k-len(best), packsFirst)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, packs := range combinations {
+			if largestPacks > 0 {
+				packs[largestPack] += largestPacks
+			}
+			best = append(best, PackResult{
+				Order:      order,
+				TotalItems: total + largestPacks*largestPack,
+				Packs:      packs,
+				PackSizes:  packSizes,
+				TotalCost:  costs.Total(packs),
+			})
+		}
+	}
+
+	return best, nil
+}
+
+// alternativesMargin returns k times the most items that the smaller packs of one
+// of the best combinations can hold, or math.MaxInt when that does not fit an int.
+func alternativesMargin(sizes []int, k int) int {
+	threshold := periodThreshold(sizes)
+	secondLargestPack := 0
+	if len(sizes) > 1 {
+		secondLargestPack = sizes[len(sizes)-2]
+	}
+
+	if threshold > math.MaxInt/k-secondLargestPack {
+		return math.MaxInt
+	}
+	return k * (threshold + secondLargestPack)
+}
+
+// combinationState is a combination of a total whose counts are decided from the
+// largest size down to level+1; the smaller sizes must still make up rest.
+type combinationState struct {
+	counts []int
+	level  int
+	rest   int
+	packs  int
+}
+
+// searchCombinations returns up to k combinations summing exactly to total from the
+// sizes within the bounds, best first: the fewest packs when packsFirst is set,
+// then the most packs of the largest size, of the next largest and so on. tables
+// holds the fewest packs of each size and the smaller ones, see fewestPacksTables.
+//
+// It is a best-first search over partial combinations. A partial combination
+// ranks by the fewest packs it can end with and by its decided counts, with the
+// undecided ones ranking above any count, so it never ranks after the
+// combinations it leads to and complete combinations are found in order.
+func searchCombinations(ctx context.Context, sizes, bounds []int, tables [][]int32, total, k int, packsFirst bool) ([]map[int]int, error) {
+	top := len(sizes) - 1
+	if tables[top][total] == unreachable {
+		return nil, nil
+	}
+
+	fewest := func(state combinationState) int {
+		if state.level < 0 {
+			return state.packs
+		}
+		return state.packs + int(tables[state.level][state.rest])
+	}
+
+	before := func(a, b combinationState) bool {
+		if packsFirst {
+			if fa, fb := fewest(a), fewest(b); fa != fb {
+				return fa < fb
+			}
+		}
+		for i := top; i >= 0; i-- {
+			decidedA, decidedB := i > a.level, i > b.level
+			switch {
+			case !decidedA && !decidedB:
+				return false
+			case !decidedA || !decidedB:
+				return !decidedA
+			case a.counts[i] != b.counts[i]:
+				return a.counts[i] > b.counts[i]
+			}
+		}
+		return false
+	}
+
+	queue := &combinationQueue{before: before}
+	heap.Push(queue, combinationState{counts: make([]int, len(sizes)), level: top, rest: total})
+
+	var combinations []map[int]int
+	expanded := 0
+
+	for queue.Len() > 0 && len(combinations) < k {
+		expanded++
+		if expanded%cancellationCheckInterval == 0 {
+			if err := spend(ctx, cancellationCheckInterval); err != nil {
+				return nil, err
+			}
+		}
+
+		state := heap.Pop(queue).(combinationState)
+		if state.level < 0 {
+			packs := make(map[int]int)
+			for i, count := range state.counts {
+				if count > 0 {
+					packs[sizes[i]] = count
+				}
+			}
+			combinations = append(combinations, packs)
+			continue
+		}
+
+		size := sizes[state.level]
+		most := state.rest / size
+		if bounds[state.level] >= 0 {
+			most = min(most, bounds[state.level])
+		}
+
+		for count := most; count >= 0; count-- {
+			rest := state.rest - count*size
+			if (state.level == 0 && rest != 0) || (state.level > 0 && tables[state.level-1][rest] == unreachable) {
+				continue
+			}
+
+			child := combinationState{
+				counts: slices.Clone(state.counts),
+				level:  state.level - 1,
+				rest:   rest,
+				packs:  state.packs + count,
+			}
+			child.counts[state.level] = count
+			heap.Push(queue, child)
+		}
+	}
+
+	return combinations, nil
+}
+
+// combinationQueue is a priority queue of partial combinations, implementing
+// heap.Interface.
+type combinationQueue struct {
+	states []combinationState
+	before func(a, b combinationState) bool
+}
+
+func (q *combinationQueue) Len() int           { return len(q.states) }
+func (q *combinationQueue) Less(i, j int) bool { return q.before(q.states[i], q.states[j]) }
+func (q *combinationQueue) Swap(i, j int)      { q.states[i], q.states[j] = q.states[j], q.states[i] }
+func (q *combinationQueue) Push(x any)         { q.states = append(q.states, x.(combinationState)) }
+
+func (q *combinationQueue) Pop() any {
+	last := q.states[len(q.states)-1]
+	q.states = q.states[:len(q.states)-1]
+	return last
+}
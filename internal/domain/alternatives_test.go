@@ -0,0 +1,200 @@
+package domain
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPackCalculator_Alternatives(t *testing.T) {
+	defaultSizes := []int{250, 500, 1000, 2000, 5000}
+
+	tests := []struct {
+		name          string
+		order         int
+		opts          CalculateOptions
+		k             int
+		expectedPacks []map[int]int
+	}{
+		{
+			name:          "best combinations first",
+			order:         251,
+			k:             3,
+			expectedPacks: []map[int]int{{500: 1}, {250: 2}, {1000: 1}},
+		},
+		{
+			name:          "fewer combinations than requested",
+			order:         1,
+			k:             10,
+			expectedPacks: []map[int]int{{250: 1}, {500: 1}, {1000: 1}, {2000: 1}, {5000: 1}},
+		},
+		{
+			name:          "within stock",
+			order:         251,
+			opts:          CalculateOptions{Stock: map[int]int{500: 0}},
+			k:             2,
+			expectedPacks: []map[int]int{{250: 2}, {1000: 1}},
+		},
+		{
+			name:          "ranked by the strategy",
+			order:         501,
+			opts:          CalculateOptions{Strategy: FewestDistinctSizes},
+			k:             2,
+			expectedPacks: []map[int]int{{250: 3}, {500: 1, 250: 1}},
+		},
+		{
+			name:          "ranked by cost",
+			order:         251,
+			opts:          CalculateOptions{Objective: ObjectiveMinCost},
+			k:             3,
+			expectedPacks: []map[int]int{{1000: 1}, {250: 2}, {500: 1}},
+		},
+		{
+			name:          "no alternatives requested",
+			order:         251,
+			k:             0,
+			expectedPacks: []map[int]int{},
+		},
+		{
+			name:          "empty order",
+			order:         0,
+			k:             3,
+			expectedPacks: []map[int]int{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calculator := NewPackCalculator(defaultSizes)
+			calculator.UpdateCosts(Costs{PerPack: map[int]int{250: 100, 500: 300, 1000: 120, 2000: 400, 5000: 900}})
+
+			alternatives, err := calculator.Alternatives(context.Background(), tt.order, tt.opts, tt.k)
+
+			require.NoError(t, err)
+
+			packs := make([]map[int]int, len(alternatives))
+			for i, alternative := range alternatives {
+				packs[i] = alternative.Packs
+				assert.Equal(t, tt.order, alternative.Order)
+			}
+			assert.Equal(t, tt.expectedPacks, packs)
+		})
+	}
+
+	t.Run("should find alternatives for large orders from tables", func(t *testing.T) {
+		calculator := NewPackCalculator(defaultSizes)
+
+		alternatives, err := calculator.Alternatives(context.Background(), 1_000_000_001, CalculateOptions{}, 2)
+
+		require.NoError(t, err)
+		require.Len(t, alternatives, 2)
+		assert.Equal(t, map[int]int{5000: 200_000, 250: 1}, alternatives[0].Packs)
+		assert.Equal(t, 1_000_000_250, alternatives[0].TotalItems)
+		assert.Equal(t, 1_000_000_250, alternatives[1].TotalItems)
+	})
+
+	t.Run("should report orders with too many combinations to rank by cost", func(t *testing.T) {
+		calculator := NewPackCalculator(defaultSizes)
+
+		_, err := calculator.Alternatives(context.Background(), 1_000_000, CalculateOptions{Objective: ObjectiveMinCost}, 3)
+
+		assert.ErrorIs(t, err, ErrOrderTooLarge)
+	})
+
+	t.Run("should reject invalid options", func(t *testing.T) {
+		calculator := NewPackCalculator(defaultSizes)
+
+		_, err := calculator.Alternatives(context.Background(), -1, CalculateOptions{}, 3)
+		assert.ErrorIs(t, err, ErrInvalidOrder)
+
+		_, err = calculator.Alternatives(context.Background(), 1, CalculateOptions{Stock: map[int]int{250: -1}}, 3)
+		assert.ErrorIs(t, err, ErrInvalidStock)
+
+		_, err = calculator.Alternatives(context.Background(), 1, CalculateOptions{Objective: ObjectiveMinCost, Strategy: LargerPacks}, 3)
+		assert.ErrorIs(t, err, ErrInvalidStrategy)
+	})
+}
+
+func TestPackCalculator_Alternatives_MatchesCalculation(t *testing.T) {
+	packSets := [][]int{
+		{250, 500, 1000, 2000, 5000},
+		{3, 7, 11},
+		{23, 31, 53},
+	}
+
+	random := rand.New(rand.NewSource(1))
+
+	for _, packSizes := range packSets {
+		calculator := NewPackCalculator(packSizes)
+
+		for i := 0; i < 50; i++ {
+			order := 1 + random.Intn(4*packSizes[len(packSizes)-1])
+
+			for _, opts := range []CalculateOptions{{}, {Objective: ObjectiveMinCost}, {Strategy: FewestDistinctSizes}} {
+				result, err := calculator.CalculateWithOptions(context.Background(), order, opts)
+				require.NoError(t, err)
+
+				alternatives, err := calculator.Alternatives(context.Background(), order, opts, 5)
+				require.NoError(t, err)
+				require.NotEmpty(t, alternatives)
+
+				less := opts.less()
+				assert.False(t, less(result, alternatives[0]) || less(alternatives[0], result),
+					"sizes %v, order %d: best alternative %v does not tie with result %v", packSizes, order, alternatives[0].Packs, result.Packs)
+
+				for j := 1; j < len(alternatives); j++ {
+					assert.False(t, less(alternatives[j], alternatives[j-1]), "sizes %v, order %d: alternatives out of order", packSizes, order)
+				}
+			}
+		}
+	}
+}
+
+// walkedStrategy hides a lexicographic strategy so that alternatives are ranked
+// by visiting every combination.
+type walkedStrategy struct{ Strategy }
+
+func TestPackCalculator_Alternatives_TablesMatchWalk(t *testing.T) {
+	packSets := [][]int{
+		{250, 500, 1000, 2000, 5000},
+		{3, 7, 11},
+		{6, 9, 20},
+		{23, 31, 53},
+	}
+
+	random := rand.New(rand.NewSource(1))
+
+	for _, packSizes := range packSets {
+		calculator := NewPackCalculator(packSizes)
+		largestPack := packSizes[len(packSizes)-1]
+
+		for i := 0; i < 40; i++ {
+			order := 1 + random.Intn(6*largestPack)
+			stock := map[int]int{packSizes[random.Intn(len(packSizes))]: random.Intn(5)}
+
+			for _, opts := range []CalculateOptions{
+				{},
+				{Strategy: FewestPacks},
+				{Strategy: LargerPacks},
+				{Strategy: SurplusOnly},
+				{Strategy: LargerPacks, Stock: stock},
+			} {
+				fromTables, err := calculator.Alternatives(context.Background(), order, opts, 6)
+				require.NoError(t, err)
+
+				walked := opts
+				walked.Strategy = walkedStrategy{FewestPacks}
+				if opts.Strategy != nil {
+					walked.Strategy = walkedStrategy{opts.Strategy}
+				}
+				fromWalk, err := calculator.Alternatives(context.Background(), order, walked, 6)
+				require.NoError(t, err)
+
+				assert.Equal(t, fromWalk, fromTables, "sizes %v, order %d, options %+v", packSizes, order, opts)
+			}
+		}
+	}
+}
@@ -0,0 +1,290 @@
+package domain
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"slices"
+)
+
+// maxAnswerTableLimit caps the orders covered by an answer table, which holds one
+// int32 per pack size for every order.
+const maxAnswerTableLimit = 1 << 22
+
+// answerTableMagic starts every encoded answer table, followed by the format version.
+const (
+	answerTableMagic   = "OPAT"
+	answerTableVersion = 1
+)
+
+var (
+	// ErrInvalidAnswerTable is returned when an encoded answer table is corrupt or
+	// was not written by AnswerTable.WriteTo.
+	ErrInvalidAnswerTable = errors.New("invalid answer table")
+
+	// ErrAnswerTableMismatch is returned when an answer table is loaded into a
+	// calculator with other pack sizes.
+	ErrAnswerTableMismatch = errors.New("answer table was computed for other pack sizes")
+)
+
+// AnswerTable holds the optimal pack combination of every order from zero up to
+// its limit for one set of pack sizes, as computed by CalculateContext without
+// pack size rules. A calculator with the table loaded answers covered orders with
+// a lookup instead of solving them. Repeated pack sizes are held once.
+type AnswerTable struct {
+	packSizes []int
+	limit     int
+
+	// counts holds the packs of every size for each order: the count of
+	// packSizes[i] for an order is at counts[order*len(packSizes)+i].
+	counts []int32
+}
+
+// Precompute computes the answer table of every order up to limit for the current
+// pack sizes. Orders are solved from the shared table, so the work is that of the
+// largest search range plus a lookup per order.
+//
+// Failures are reported with ErrInvalidOrder for a negative limit, ErrOrderTooLarge
+// for limits above 4194304, ErrNoPackSizes, ErrInfeasible or ctx.Err().
+func (pc *PackCalculator) Precompute(ctx context.Context, limit int) (*AnswerTable, error) {
+	packSizes, _ := pc.configuration()
+	distinctSizes := slices.Compact(slices.Clone(packSizes))
+
+	if limit < 0 {
+		return nil, ErrInvalidOrder
+	}
+
+	if limit > maxAnswerTableLimit {
+		return nil, fmt.Errorf("%w: answer tables cover at most %d orders", ErrOrderTooLarge, maxAnswerTableLimit)
+	}
+
+	searchLimit := 0
+	for order := 0; order <= limit; order++ {
+		plan, err := planOrder(order, packSizes)
+		if err != nil {
+			return nil, err
+		}
+		searchLimit = max(searchLimit, plan.searchLimit(packSizes))
+	}
+
+	table, err := pc.tableFor(ctx, packSizes, searchLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	answers := &AnswerTable{
+		packSizes: distinctSizes,
+		limit:     limit,
+		counts:    make([]int32, (limit+1)*len(distinctSizes)),
+	}
+
+	for order := 0; order <= limit; order++ {
+		if order%cancellationCheckInterval == 0 {
+			if err := spend(ctx, 0); err != nil {
+				return nil, err
+			}
+		}
+
+		// The plans were validated above.
+		plan, _ := planOrder(order, packSizes)
+		result, err := pc.resolveOrderPlan(table, plan, Costs{})
+		if err != nil {
+			return nil, err
+		}
+
+		for i, size := range distinctSizes {
+			answers.counts[order*len(distinctSizes)+i] = int32(result.Packs[size])
+		}
+	}
+
+	return answers, nil
+}
+
+// PackSizes returns the distinct pack sizes the table was computed for.
+func (t *AnswerTable) PackSizes() []int {
+	return slices.Clone(t.packSizes)
+}
+
+// Limit returns the largest order covered by the table.
+func (t *AnswerTable) Limit() int {
+	return t.limit
+}
+
+// lookup returns the answer for the order with the calculator's pack sizes, or
+// false when the table does not cover it.
+func (t *AnswerTable) lookup(order int, packSizes []int) (PackResult, bool) {
+	if order < 0 || order > t.limit {
+		return PackResult{}, false
+	}
+
+	result := PackResult{
+		Order:     order,
+		Packs:     make(map[int]int),
+		PackSizes: slices.Clone(packSizes),
+	}
+
+	for i, size := range t.packSizes {
+		if count := int(t.counts[order*len(t.packSizes)+i]); count > 0 {
+			result.Packs[size] = count
+			result.TotalItems += count * size
+		}
+	}
+
+	return result, true
+}
+
+// WriteTo writes the table in its compact binary form: the magic "OPAT" and the
+// format version, then the pack sizes, the limit and the pack counts of every
+// order as unsigned varints, followed by the CRC-32 of everything before it.
+func (t *AnswerTable) WriteTo(w io.Writer) (int64, error) {
+	var encoded []byte
+	encoded = append(encoded, answerTableMagic...)
+	encoded = append(encoded, answerTableVersion)
+	encoded = binary.AppendUvarint(encoded, uint64(len(t.packSizes)))
+	for _, size := range t.packSizes {
+		encoded = binary.AppendUvarint(encoded, uint64(size))
+	}
+	encoded = binary.AppendUvarint(encoded, uint64(t.limit))
+	for _, count := range t.counts {
+		encoded = binary.AppendUvarint(encoded, uint64(count))
+	}
+	encoded = binary.BigEndian.AppendUint32(encoded, crc32.ChecksumIEEE(encoded))
+
+	n, err := w.Write(encoded)
+	return int64(n), err
+}
+
+// ReadAnswerTable reads a table written by AnswerTable.WriteTo. Tables that are
+// truncated, fail their checksum or hold combinations that do not fulfill their
+// order are rejected with ErrInvalidAnswerTable.
+func ReadAnswerTable(r io.Reader) (*AnswerTable, error) {
+	encoded, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	header := len(answerTableMagic) + 1
+	if len(encoded) < header+4 || !bytes.HasPrefix(encoded, []byte(answerTableMagic)) {
+		return nil, fmt.Errorf("%w: not an answer table", ErrInvalidAnswerTable)
+	}
+	if version := encoded[len(answerTableMagic)]; version != answerTableVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidAnswerTable, version)
+	}
+
+	body, checksum := encoded[:len(encoded)-4], binary.BigEndian.Uint32(encoded[len(encoded)-4:])
+	if crc32.ChecksumIEEE(body) != checksum {
+		return nil, fmt.Errorf("%w: checksum mismatch", ErrInvalidAnswerTable)
+	}
+
+	decoder := uvarintDecoder{data: body[header:]}
+
+	sizeCount := decoder.next(len(body))
+	packSizes := make([]int, 0, sizeCount)
+	for range sizeCount {
+		size := decoder.next(maxTableSize)
+		if size <= 0 || (len(packSizes) > 0 && size <= packSizes[len(packSizes)-1]) {
+			return nil, fmt.Errorf("%w: pack sizes must be positive and ascending", ErrInvalidAnswerTable)
+		}
+		packSizes = append(packSizes, size)
+	}
+
+	// Every pack count takes at least one byte, which bounds the allocation below.
+	limit := decoder.next(maxAnswerTableLimit)
+	if decoder.err != nil || (len(packSizes) == 0 && limit > 0) || (limit+1)*len(packSizes) > len(decoder.data) {
+		return nil, fmt.Errorf("%w: malformed header", ErrInvalidAnswerTable)
+	}
+
+	table := &AnswerTable{
+		packSizes: packSizes,
+		limit:     limit,
+		counts:    make([]int32, (limit+1)*len(packSizes)),
+	}
+
+	for i := range table.counts {
+		table.counts[i] = int32(decoder.next(maxTableSize))
+	}
+	if decoder.err != nil || len(decoder.data) > 0 {
+		return nil, fmt.Errorf("%w: malformed pack counts", ErrInvalidAnswerTable)
+	}
+
+	for order := 0; order <= limit && len(packSizes) > 0; order++ {
+		totalItems := 0
+		for i, size := range packSizes {
+			totalItems += int(table.counts[order*len(packSizes)+i]) * size
+		}
+		if totalItems < order {
+			return nil, fmt.Errorf("%w: order %d is not fulfilled", ErrInvalidAnswerTable, order)
+		}
+	}
+
+	return table, nil
+}
+
+// uvarintDecoder reads unsigned varints from data, remembering the first error.
+type uvarintDecoder struct {
+	data []byte
+	err  error
+}
+
+// next returns the next varint, which must not exceed limit.
+func (d *uvarintDecoder) next(limit int) int {
+	if d.err != nil {
+		return 0
+	}
+
+	value, n := binary.Uvarint(d.data)
+	if n <= 0 || value > uint64(limit) {
+		d.err = ErrInvalidAnswerTable
+		return 0
+	}
+
+	d.data = d.data[n:]
+	return int(value)
+}
+
+// LoadAnswerTable lets the calculator answer the orders covered by the table with
+// a lookup. The table must have been computed for the current pack sizes, repeated
+// ones aside, and is dropped when they change; a nil table unloads the current one.
+func (pc *PackCalculator) LoadAnswerTable(table *AnswerTable) error {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+
+	if table != nil && !slices.Equal(table.packSizes, slices.Compact(slices.Clone(pc.packSizes))) {
+		return fmt.Errorf("%w: table has %v, calculator has %v", ErrAnswerTableMismatch, table.packSizes, pc.packSizes)
+	}
+
+	pc.answers = table
+	return nil
+}
+
+// answerTable returns the loaded answer table when it was computed for the pack sizes.
+func (pc *PackCalculator) answerTable(packSizes []int) *AnswerTable {
+	pc.mu.RLock()
+	defer pc.mu.RUnlock()
+
+	if pc.answers == nil || !slices.Equal(pc.answers.packSizes, slices.Compact(slices.Clone(packSizes))) {
+		return nil
+	}
+	return pc.answers
+}
+
+// lookupAnswer returns the answer for the order from the loaded answer table,
+// priced with the costs, or false when no loaded table covers it.
+func (pc *PackCalculator) lookupAnswer(packSizes []int, costs Costs, order int) (PackResult, bool) {
+	answers := pc.answerTable(packSizes)
+	if answers == nil {
+		return PackResult{}, false
+	}
+
+	result, ok := answers.lookup(order, packSizes)
+	if !ok {
+		return PackResult{}, false
+	}
+
+	result.TotalCost = costs.Total(result.Packs)
+	return result, true
+}
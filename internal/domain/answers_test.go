@@ -0,0 +1,157 @@
+package domain
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPackCalculator_Precompute(t *testing.T) {
+	packSizes := []int{23, 31, 53}
+	calculator := NewPackCalculator(packSizes)
+
+	answers, err := calculator.Precompute(context.Background(), 5000)
+	require.NoError(t, err)
+	assert.Equal(t, packSizes, answers.PackSizes())
+	assert.Equal(t, 5000, answers.Limit())
+
+	t.Run("should hold the solver's answer for every order", func(t *testing.T) {
+		solver := NewPackCalculator(packSizes)
+
+		for order := 0; order <= answers.Limit(); order++ {
+			expected, err := solver.CalculateContext(context.Background(), order)
+			require.NoError(t, err)
+
+			actual, ok := answers.lookup(order, packSizes)
+			require.True(t, ok)
+			assert.Equal(t, expected, actual, "order %d", order)
+		}
+
+		_, ok := answers.lookup(5001, packSizes)
+		assert.False(t, ok)
+	})
+
+	t.Run("should round-trip through the binary form", func(t *testing.T) {
+		var buf bytes.Buffer
+		n, err := answers.WriteTo(&buf)
+		require.NoError(t, err)
+		assert.Equal(t, int64(buf.Len()), n)
+
+		read, err := ReadAnswerTable(&buf)
+		require.NoError(t, err)
+		assert.Equal(t, answers, read)
+	})
+
+	t.Run("should reject invalid limits", func(t *testing.T) {
+		_, err := calculator.Precompute(context.Background(), -1)
+		assert.ErrorIs(t, err, ErrInvalidOrder)
+
+		_, err = calculator.Precompute(context.Background(), maxAnswerTableLimit+1)
+		assert.ErrorIs(t, err, ErrOrderTooLarge)
+
+		_, err = NewPackCalculator(nil).Precompute(context.Background(), 1)
+		assert.ErrorIs(t, err, ErrNoPackSizes)
+	})
+}
+
+func TestReadAnswerTable_Invalid(t *testing.T) {
+	answers, err := NewPackCalculator([]int{250, 500}).Precompute(context.Background(), 1000)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	_, err = answers.WriteTo(&buf)
+	require.NoError(t, err)
+	encoded := buf.Bytes()
+
+	corrupt := bytes.Clone(encoded)
+	corrupt[len(corrupt)/2] ^= 0xff
+
+	tests := []struct {
+		name    string
+		encoded []byte
+	}{
+		{name: "empty", encoded: nil},
+		{name: "wrong magic", encoded: append([]byte("JSON"), encoded[4:]...)},
+		{name: "unsupported version", encoded: append(append([]byte("OPAT"), 2), encoded[5:]...)},
+		{name: "truncated", encoded: encoded[:len(encoded)-10]},
+		{name: "corrupt", encoded: corrupt},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadAnswerTable(bytes.NewReader(tt.encoded))
+			assert.ErrorIs(t, err, ErrInvalidAnswerTable)
+		})
+	}
+}
+
+func TestPackCalculator_LoadAnswerTable(t *testing.T) {
+	precomputed, err := NewPackCalculator([]int{250, 500, 1000}).Precompute(context.Background(), 1000)
+	require.NoError(t, err)
+
+	t.Run("should answer covered orders from the table", func(t *testing.T) {
+		calculator := NewPackCalculator([]int{250, 500, 1000}, WithResultCache(10))
+		require.NoError(t, calculator.LoadAnswerTable(precomputed))
+		calculator.UpdateCosts(Costs{PerPack: map[int]int{500: 200}})
+
+		result, err := calculator.CalculateContext(context.Background(), 251)
+		require.NoError(t, err)
+		assert.Equal(t, map[int]int{500: 1}, result.Packs)
+		assert.Equal(t, 200, result.TotalCost)
+		assert.Nil(t, calculator.sharedTable(calculator.GetPackSizes()))
+		assert.Equal(t, CacheStats{Capacity: 10}, calculator.CacheStats())
+	})
+
+	t.Run("should fall back to the solver beyond the limit", func(t *testing.T) {
+		calculator := NewPackCalculator([]int{250, 500, 1000})
+		require.NoError(t, calculator.LoadAnswerTable(precomputed))
+
+		result, err := calculator.CalculateContext(context.Background(), 1001)
+		require.NoError(t, err)
+		assert.Equal(t, map[int]int{1000: 1, 250: 1}, result.Packs)
+	})
+
+	t.Run("should hold repeated pack sizes once", func(t *testing.T) {
+		calculator := NewPackCalculator([]int{250, 250, 500})
+		answers, err := calculator.Precompute(context.Background(), 1000)
+		require.NoError(t, err)
+		assert.Equal(t, []int{250, 500}, answers.PackSizes())
+
+		var buf bytes.Buffer
+		_, err = answers.WriteTo(&buf)
+		require.NoError(t, err)
+		read, err := ReadAnswerTable(&buf)
+		require.NoError(t, err)
+		require.NoError(t, calculator.LoadAnswerTable(read))
+
+		for order := 0; order <= 1000; order++ {
+			expected, err := NewPackCalculator([]int{250, 250, 500}).CalculateContext(context.Background(), order)
+			require.NoError(t, err)
+
+			result, err := calculator.CalculateContext(context.Background(), order)
+			require.NoError(t, err)
+			assert.Equal(t, expected, result, "order %d", order)
+		}
+	})
+
+	t.Run("should reject tables of other pack sizes", func(t *testing.T) {
+		calculator := NewPackCalculator([]int{250, 500})
+		assert.ErrorIs(t, calculator.LoadAnswerTable(precomputed), ErrAnswerTableMismatch)
+	})
+
+	t.Run("should drop the table when the pack sizes change", func(t *testing.T) {
+		calculator := NewPackCalculator([]int{250, 500, 1000})
+		require.NoError(t, calculator.LoadAnswerTable(precomputed))
+
+		calculator.UpdatePackSizes([]int{300})
+		result, err := calculator.CalculateContext(context.Background(), 251)
+		require.NoError(t, err)
+		assert.Equal(t, map[int]int{300: 1}, result.Packs)
+
+		calculator.UpdatePackSizes([]int{250, 500, 1000})
+		assert.Nil(t, calculator.answerTable(calculator.GetPackSizes()))
+	})
+}
@@ -0,0 +1,154 @@
+package domain
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+)
+
+// BatchResult holds the outcome of a single order within a batch calculation.
+// Err is set when the order could not be calculated; Result is zero in that case.
+type BatchResult struct {
+	Result PackResult
+	Err    error
+}
+
+// CalculateBatch computes the optimal pack combination for every order, sharing
+// the dynamic programming table between them.
+//
+// Orders are resolved by at most workers goroutines. Results are returned in the
+// same order as the input, and an order that fails does not affect the others.
+// Orders restricted by the pack size rules are solved first, on their own like
+// CalculateContext; the other orders are then solved from the smallest table up,
+// so that a table exceeding the compute budget, or cancelled by ctx, only fails
+// the orders that needed at least as large a table. The batch is admitted to the
+// limiter once, with every table it needs, and the packs are grouped into parcels
+// like CalculateContext.
+func (pc *PackCalculator) CalculateBatch(ctx context.Context, orders []int, workers int) []BatchResult {
+	packSizes, costs := pc.configuration()
+	rules := pc.GetRules()
+	results := make([]BatchResult, len(orders))
+	plans := make([]orderPlan, len(orders))
+	searchLimits := make([]int, len(orders))
+
+	limits := pc.GetLimits()
+	ctx, cancel := limits.withBudget(ctx)
+	defer cancel()
+
+	var restricted, planned []int
+	for i, order := range orders {
+		if err := limits.checkOrder(order); err != nil {
+			results[i].Err = err
+			continue
+		}
+
+		if rules.Restricts(order) {
+			restricted = append(restricted, i)
+			continue
+		}
+
+		plan, err := planOrder(order, packSizes)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+
+		plans[i] = plan
+		searchLimits[i] = plan.searchLimit(packSizes)
+		planned = append(planned, i)
+	}
+
+	slices.SortStableFunc(planned, func(a, b int) int {
+		return cmp.Compare(searchLimits[a], searchLimits[b])
+	})
+
+	if err := pc.admitBatch(ctx, packSizes, planned, searchLimits); err != nil {
+		for _, i := range planned {
+			results[i].Err = err
+		}
+		planned = nil
+	}
+
+	pc.solveBatch(restricted, workers, results, func(i int) (PackResult, error) {
+		return pc.calculateWithOptions(ctx, orders[i], CalculateOptions{})
+	})
+
+	for len(planned) > 0 {
+		// Tables above maxSharedTableSize are built on their own, so one is built
+		// for all the orders that need one instead of one per order.
+		limit := searchLimits[planned[0]]
+		if limit > maxSharedTableSize {
+			limit = searchLimits[planned[len(planned)-1]]
+		}
+		solved, _ := slices.BinarySearchFunc(planned, limit+1, func(i, limit int) int {
+			return cmp.Compare(searchLimits[i], limit)
+		})
+
+		table, err := pc.tableFor(ctx, packSizes, limit)
+		if err != nil {
+			for _, i := range planned {
+				results[i].Err = err
+			}
+			break
+		}
+
+		pc.solveBatch(planned[:solved], workers, results, func(i int) (PackResult, error) {
+			return pc.resolveOrderPlan(table, plans[i], costs)
+		})
+		planned = planned[solved:]
+	}
+
+	return results
+}
+
+// admitBatch admits a batch to the limiter of the budget of ctx with the
+// quantities of every table its orders need, sorted by search limit, so that it
+// is held back as a whole rather than by its smallest table. Nothing is admitted
+// when the shared table already covers every order.
+func (pc *PackCalculator) admitBatch(ctx context.Context, packSizes []int, planned, searchLimits []int) error {
+	quantities, shared := 0, 0
+	for _, i := range planned {
+		if searchLimits[i] > maxSharedTableSize {
+			quantities = searchLimits[i]
+		} else {
+			shared = searchLimits[i]
+		}
+	}
+
+	if table := pc.sharedTable(packSizes); table != nil {
+		shared -= min(shared, table.limit())
+	}
+
+	if quantities+shared == 0 {
+		return nil
+	}
+	return admit(ctx, quantities+shared)
+}
+
+// solveBatch solves the orders at the given indices of a batch with at most
+// workers goroutines, grouping the packs of every result into parcels.
+func (pc *PackCalculator) solveBatch(indices []int, workers int, results []BatchResult, solve func(i int) (PackResult, error)) {
+	pending := make(chan int)
+	var wg sync.WaitGroup
+
+	for range min(max(workers, 1), len(indices)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range pending {
+				result, err := solve(i)
+				if err == nil {
+					result, err = pc.groupParcels(result)
+				}
+				results[i].Result, results[i].Err = result, err
+			}
+		}()
+	}
+
+	for _, i := range indices {
+		pending <- i
+	}
+	close(pending)
+	wg.Wait()
+}
@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPackCalculator_CalculateBatch(t *testing.T) {
+	t.Run("should match individual calculations", func(t *testing.T) {
+		calculator := NewPackCalculator([]int{250, 500, 1000, 2000, 5000})
+		orders := []int{1, 251, 501, 12001, 0, 100_000, 1_000_000_001, 7501}
+
+		results := calculator.CalculateBatch(context.Background(), orders, 3)
+
+		require.Len(t, results, len(orders))
+		for i, order := range orders {
+			expected, err := calculator.CalculateContext(context.Background(), order)
+			require.NoError(t, err)
+
+			assert.NoError(t, results[i].Err, "order %d", order)
+			assert.Equal(t, expected, results[i].Result, "order %d", order)
+		}
+	})
+
+	t.Run("should report per-order errors without failing the batch", func(t *testing.T) {
+		calculator := NewPackCalculator([]int{999_983, 1_000_003})
+		orders := []int{1, -5, 100_000_000, 2_000_000}
+
+		results := calculator.CalculateBatch(context.Background(), orders, 2)
+
+		require.Len(t, results, len(orders))
+		assert.NoError(t, results[0].Err)
+		assert.Equal(t, 999_983, results[0].Result.TotalItems)
+		assert.ErrorIs(t, results[1].Err, ErrInvalidOrder)
+		assert.ErrorIs(t, results[2].Err, ErrOrderTooLarge)
+		assert.NoError(t, results[3].Err)
+		assert.Equal(t, 2_000_000, results[3].Result.Order)
+		assert.GreaterOrEqual(t, results[3].Result.TotalItems, 2_000_000)
+	})
+
+	t.Run("should report missing pack sizes for every positive order", func(t *testing.T) {
+		calculator := NewPackCalculator([]int{})
+
+		results := calculator.CalculateBatch(context.Background(), []int{0, 10}, 1)
+
+		assert.NoError(t, results[0].Err)
+		assert.ErrorIs(t, results[1].Err, ErrNoPackSizes)
+	})
+
+	t.Run("should report cancellation for orders that needed the table", func(t *testing.T) {
+		calculator := NewPackCalculator([]int{997, 1009, 4999})
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		results := calculator.CalculateBatch(ctx, []int{-1, 1_000_001}, 1)
+
+		assert.ErrorIs(t, results[0].Err, ErrInvalidOrder)
+		assert.ErrorIs(t, results[1].Err, context.Canceled)
+	})
+
+	t.Run("should handle an empty batch", func(t *testing.T) {
+		calculator := NewPackCalculator([]int{250, 500})
+
+		results := calculator.CalculateBatch(context.Background(), nil, 4)
+
+		assert.Empty(t, results)
+	})
+}
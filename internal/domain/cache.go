@@ -0,0 +1,163 @@
+package domain
+
+import (
+	"container/list"
+	"hash/fnv"
+	"maps"
+	"slices"
+	"strconv"
+	"sync"
+)
+
+// CalculatorOption configures a PackCalculator
+type CalculatorOption func(*PackCalculator)
+
+// WithResultCache keeps the results of up to capacity orders solved from the
+// dynamic programming table, evicting the least recently used one when full.
+// A capacity of zero or less disables the cache.
+func WithResultCache(capacity int) CalculatorOption {
+	return func(pc *PackCalculator) {
+		if capacity > 0 {
+			pc.cache = newResultCache(capacity)
+		}
+	}
+}
+
+// CacheStats reports the usage of the result cache since it was created.
+type CacheStats struct {
+	Hits     int64
+	Misses   int64
+	Size     int
+	Capacity int
+}
+
+// cacheKey identifies a cached result: the canonical hash of the pack size set
+// and the order.
+type cacheKey struct {
+	packSizes uint64
+	order     int
+}
+
+// cacheEntry is a cached result. The pack sizes are kept to tell hash collisions
+// apart from hits.
+type cacheEntry struct {
+	key        cacheKey
+	packSizes  []int
+	totalItems int
+	packs      map[int]int
+}
+
+// resultCache is a size-bounded LRU cache of table results. Costs are not cached,
+// so results stay valid when only the costs change.
+type resultCache struct {
+	mu       sync.Mutex
+	capacity int
+	entries  map[cacheKey]*list.Element
+	recency  *list.List
+	hits     int64
+	misses   int64
+}
+
+// newResultCache creates an empty cache holding at most capacity results.
+func newResultCache(capacity int) *resultCache {
+	return &resultCache{
+		capacity: capacity,
+		entries:  make(map[cacheKey]*list.Element),
+		recency:  list.New(),
+	}
+}
+
+// hashPackSizes returns the canonical hash of a pack size set, which does not
+// depend on the order of the sizes or on duplicates.
+func hashPackSizes(packSizes []int) uint64 {
+	canonical := slices.Compact(slices.Sorted(slices.Values(packSizes)))
+
+	hash := fnv.New64a()
+	for _, size := range canonical {
+		hash.Write(strconv.AppendInt(nil, int64(size), 10))
+		hash.Write([]byte{','})
+	}
+	return hash.Sum64()
+}
+
+// get returns a copy of the cached result for the order with the pack sizes.
+func (c *resultCache) get(packSizes []int, order int) (PackResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[cacheKey{packSizes: hashPackSizes(packSizes), order: order}]
+	if !ok || !slices.Equal(element.Value.(*cacheEntry).packSizes, packSizes) {
+		c.misses++
+		return PackResult{}, false
+	}
+
+	c.hits++
+	c.recency.MoveToFront(element)
+
+	entry := element.Value.(*cacheEntry)
+	return PackResult{
+		Order:      order,
+		TotalItems: entry.totalItems,
+		Packs:      maps.Clone(entry.packs),
+		PackSizes:  slices.Clone(entry.packSizes),
+	}, true
+}
+
+// put caches the result for the order with the pack sizes.
+func (c *resultCache) put(packSizes []int, result PackResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := cacheKey{packSizes: hashPackSizes(packSizes), order: result.Order}
+	entry := &cacheEntry{
+		key:        key,
+		packSizes:  slices.Clone(packSizes),
+		totalItems: result.TotalItems,
+		packs:      maps.Clone(result.Packs),
+	}
+
+	if element, ok := c.entries[key]; ok {
+		element.Value = entry
+		c.recency.MoveToFront(element)
+		return
+	}
+
+	c.entries[key] = c.recency.PushFront(entry)
+
+	if c.recency.Len() > c.capacity {
+		oldest := c.recency.Back()
+		c.recency.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// clear removes every cached result, keeping the counters.
+func (c *resultCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	clear(c.entries)
+	c.recency.Init()
+}
+
+// stats returns the counters and the current size of the cache.
+func (c *resultCache) stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return CacheStats{
+		Hits:     c.hits,
+		Misses:   c.misses,
+		Size:     c.recency.Len(),
+		Capacity: c.capacity,
+	}
+}
+
+// CacheStats returns the hit and miss counters and the size of the result cache,
+// or zero stats when the calculator has no cache.
+func (pc *PackCalculator) CacheStats() CacheStats {
+	if pc.cache == nil {
+		return CacheStats{}
+	}
+	return pc.cache.stats()
+}
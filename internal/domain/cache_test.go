@@ -0,0 +1,97 @@
+package domain
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPackCalculator_ResultCache(t *testing.T) {
+	t.Run("should answer repeated orders from the cache", func(t *testing.T) {
+		calculator := NewPackCalculator([]int{250, 500, 1000, 2000, 5000}, WithResultCache(10))
+
+		first, err := calculator.CalculateContext(context.Background(), 12001)
+		require.NoError(t, err)
+		second, err := calculator.CalculateContext(context.Background(), 12001)
+		require.NoError(t, err)
+
+		assert.Equal(t, first, second)
+		assert.Equal(t, CacheStats{Hits: 1, Misses: 1, Size: 1, Capacity: 10}, calculator.CacheStats())
+	})
+
+	t.Run("should not share packs with cached results", func(t *testing.T) {
+		calculator := NewPackCalculator([]int{250, 500}, WithResultCache(10))
+
+		first, err := calculator.CalculateContext(context.Background(), 251)
+		require.NoError(t, err)
+		first.Packs[250] = 99
+
+		second, err := calculator.CalculateContext(context.Background(), 251)
+		require.NoError(t, err)
+		assert.Equal(t, map[int]int{500: 1}, second.Packs)
+	})
+
+	t.Run("should evict the least recently used result", func(t *testing.T) {
+		calculator := NewPackCalculator([]int{250, 500}, WithResultCache(2))
+
+		for _, order := range []int{1, 251, 1, 501, 1, 251} {
+			_, err := calculator.CalculateContext(context.Background(), order)
+			require.NoError(t, err)
+		}
+
+		// 251 is evicted by 501, as 1 was used more recently.
+		assert.Equal(t, CacheStats{Hits: 2, Misses: 4, Size: 2, Capacity: 2}, calculator.CacheStats())
+	})
+
+	t.Run("should drop cached results when the pack sizes change", func(t *testing.T) {
+		calculator := NewPackCalculator([]int{250, 500}, WithResultCache(10))
+
+		_, err := calculator.CalculateContext(context.Background(), 251)
+		require.NoError(t, err)
+
+		calculator.UpdatePackSizes([]int{500, 250})
+		assert.Equal(t, 1, calculator.CacheStats().Size)
+
+		calculator.UpdatePackSizes([]int{300})
+		assert.Zero(t, calculator.CacheStats().Size)
+
+		result, err := calculator.CalculateContext(context.Background(), 251)
+		require.NoError(t, err)
+		assert.Equal(t, map[int]int{300: 1}, result.Packs)
+	})
+
+	t.Run("should price cached results with the current costs", func(t *testing.T) {
+		calculator := NewPackCalculator([]int{250, 500}, WithResultCache(10))
+
+		_, err := calculator.CalculateContext(context.Background(), 251)
+		require.NoError(t, err)
+
+		calculator.UpdateCosts(Costs{PerPack: map[int]int{500: 200}})
+		result, err := calculator.CalculateContext(context.Background(), 251)
+		require.NoError(t, err)
+		assert.Equal(t, 200, result.TotalCost)
+	})
+
+	t.Run("should not cache failed calculations", func(t *testing.T) {
+		calculator := NewPackCalculator([]int{250}, WithResultCache(10))
+
+		_, err := calculator.CalculateContext(context.Background(), -1)
+		assert.ErrorIs(t, err, ErrInvalidOrder)
+		assert.Zero(t, calculator.CacheStats().Size)
+	})
+
+	t.Run("should have no cache by default", func(t *testing.T) {
+		calculator := NewPackCalculator([]int{250})
+
+		_, err := calculator.CalculateContext(context.Background(), 1)
+		require.NoError(t, err)
+		assert.Equal(t, CacheStats{}, calculator.CacheStats())
+	})
+}
+
+func TestHashPackSizes(t *testing.T) {
+	assert.Equal(t, hashPackSizes([]int{250, 500}), hashPackSizes([]int{500, 250, 500}))
+	assert.NotEqual(t, hashPackSizes([]int{250, 500}), hashPackSizes([]int{2, 50500}))
+}
@@ -0,0 +1,128 @@
+package domain
+
+import (
+	"errors"
+	"sort"
+	"sync"
+)
+
+var (
+	// ErrProductNotFound is returned when no product with the given SKU exists in the catalog.
+	ErrProductNotFound = errors.New("product not found")
+
+	// ErrProductExists is returned when adding a SKU that is already in the catalog.
+	ErrProductExists = errors.New("product already exists")
+)
+
+// Product is a catalog entry together with its current pack sizes.
+type Product struct {
+	SKU       string
+	Name      string
+	PackSizes []int
+}
+
+// catalogEntry is a product and the calculator that owns its pack sizes.
+type catalogEntry struct {
+	name       string
+	calculator *PackCalculator
+}
+
+// Catalog holds the products the business ships. Each product owns its own
+// PackCalculator, so products can be packed with different pack sizes and
+// updating one product never affects another.
+type Catalog struct {
+	mu       sync.RWMutex
+	products map[string]catalogEntry
+	opts     []CalculatorOption
+}
+
+// NewCatalog creates an empty catalog. The calculators of its products are
+// created with the given options.
+func NewCatalog(opts ...CalculatorOption) *Catalog {
+	return &Catalog{
+		products: make(map[string]catalogEntry),
+		opts:     opts,
+	}
+}
+
+// Add creates a product with the given pack sizes.
+// It returns ErrProductExists if the SKU is already in the catalog.
+func (c *Catalog) Add(sku, name string, packSizes []int) (Product, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, exists := c.products[sku]; exists {
+		return Product{}, ErrProductExists
+	}
+
+	entry := catalogEntry{name: name, calculator: NewPackCalculator(packSizes, c.opts...)}
+	c.products[sku] = entry
+
+	return entry.product(sku), nil
+}
+
+// Remove deletes a product from the catalog.
+// It returns ErrProductNotFound if the SKU is not in the catalog.
+func (c *Catalog) Remove(sku string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, exists := c.products[sku]; !exists {
+		return ErrProductNotFound
+	}
+
+	delete(c.products, sku)
+	return nil
+}
+
+// Product returns the product with the given SKU.
+func (c *Catalog) Product(sku string) (Product, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, exists := c.products[sku]
+	if !exists {
+		return Product{}, ErrProductNotFound
+	}
+
+	return entry.product(sku), nil
+}
+
+// Products returns every product in the catalog, sorted by SKU.
+func (c *Catalog) Products() []Product {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	products := make([]Product, 0, len(c.products))
+	for sku, entry := range c.products {
+		products = append(products, entry.product(sku))
+	}
+
+	sort.Slice(products, func(i, j int) bool {
+		return products[i].SKU < products[j].SKU
+	})
+
+	return products
+}
+
+// Calculator returns the calculator that owns the pack sizes of the product.
+// Updating its pack sizes updates the product.
+func (c *Catalog) Calculator(sku string) (*PackCalculator, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, exists := c.products[sku]
+	if !exists {
+		return nil, ErrProductNotFound
+	}
+
+	return entry.calculator, nil
+}
+
+func (e catalogEntry) product(sku string) Product {
+	return Product{
+		SKU:       sku,
+		Name:      e.name,
+		PackSizes: e.calculator.GetPackSizes(),
+	}
+}
@@ -0,0 +1,116 @@
+package domain
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCatalog(t *testing.T) {
+	t.Run("should add and look up products", func(t *testing.T) {
+		catalog := NewCatalog()
+
+		product, err := catalog.Add("WIDGET", "Widget", []int{500, 250})
+		require.NoError(t, err)
+		assert.Equal(t, Product{SKU: "WIDGET", Name: "Widget", PackSizes: []int{250, 500}}, product)
+
+		found, err := catalog.Product("WIDGET")
+		require.NoError(t, err)
+		assert.Equal(t, product, found)
+	})
+
+	t.Run("should reject duplicate SKUs", func(t *testing.T) {
+		catalog := NewCatalog()
+		_, err := catalog.Add("WIDGET", "Widget", []int{250})
+		require.NoError(t, err)
+
+		_, err = catalog.Add("WIDGET", "Other widget", []int{100})
+		assert.ErrorIs(t, err, ErrProductExists)
+
+		product, err := catalog.Product("WIDGET")
+		require.NoError(t, err)
+		assert.Equal(t, []int{250}, product.PackSizes)
+	})
+
+	t.Run("should report unknown SKUs", func(t *testing.T) {
+		catalog := NewCatalog()
+
+		_, err := catalog.Product("MISSING")
+		assert.ErrorIs(t, err, ErrProductNotFound)
+
+		_, err = catalog.Calculator("MISSING")
+		assert.ErrorIs(t, err, ErrProductNotFound)
+
+		assert.ErrorIs(t, catalog.Remove("MISSING"), ErrProductNotFound)
+	})
+
+	t.Run("should list products sorted by SKU", func(t *testing.T) {
+		catalog := NewCatalog()
+		for _, sku := range []string{"C", "A", "B"} {
+			_, err := catalog.Add(sku, "", []int{1})
+			require.NoError(t, err)
+		}
+
+		var skus []string
+		for _, product := range catalog.Products() {
+			skus = append(skus, product.SKU)
+		}
+		assert.Equal(t, []string{"A", "B", "C"}, skus)
+	})
+
+	t.Run("should remove products", func(t *testing.T) {
+		catalog := NewCatalog()
+		_, err := catalog.Add("WIDGET", "Widget", []int{250})
+		require.NoError(t, err)
+
+		require.NoError(t, catalog.Remove("WIDGET"))
+
+		_, err = catalog.Product("WIDGET")
+		assert.ErrorIs(t, err, ErrProductNotFound)
+		assert.Empty(t, catalog.Products())
+	})
+
+	t.Run("should keep each product's pack sizes independent", func(t *testing.T) {
+		catalog := NewCatalog()
+		_, err := catalog.Add("SMALL", "", []int{23, 31, 53})
+		require.NoError(t, err)
+		_, err = catalog.Add("LARGE", "", []int{250, 500, 1000})
+		require.NoError(t, err)
+
+		small, err := catalog.Calculator("SMALL")
+		require.NoError(t, err)
+		large, err := catalog.Calculator("LARGE")
+		require.NoError(t, err)
+
+		assert.Equal(t, 263, small.Calculate(263).TotalItems)
+		assert.Equal(t, 500, large.Calculate(263).TotalItems)
+
+		large.UpdatePackSizes([]int{100})
+
+		product, err := catalog.Product("LARGE")
+		require.NoError(t, err)
+		assert.Equal(t, []int{100}, product.PackSizes)
+		assert.Equal(t, 263, small.Calculate(263).TotalItems)
+	})
+
+	t.Run("should handle concurrent access safely", func(t *testing.T) {
+		catalog := NewCatalog()
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				sku := string(rune('A' + i))
+				_, err := catalog.Add(sku, "", []int{i + 1})
+				assert.NoError(t, err)
+				_ = catalog.Products()
+			}()
+		}
+		wg.Wait()
+
+		assert.Len(t, catalog.Products(), 10)
+	})
+}
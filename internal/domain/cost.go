@@ -0,0 +1,95 @@
+package domain
+
+import "maps"
+
+// Objective selects what a calculation optimizes.
+type Objective string
+
+// Supported objectives. The zero value is ObjectiveMinItemsThenPacks.
+const (
+	// ObjectiveMinItemsThenPacks ships the fewest items, then the fewest packs.
+	ObjectiveMinItemsThenPacks Objective = "min_items_then_packs"
+
+	// ObjectiveMinCost ships the cheapest packs, then the fewest items, then the
+	// fewest packs.
+	ObjectiveMinCost Objective = "min_cost"
+
+	// ObjectiveMinItemsThenCost ships the fewest items, then the cheapest packs,
+	// then the fewest packs.
+	ObjectiveMinItemsThenCost Objective = "min_items_then_cost"
+)
+
+// valid reports whether the objective is one of the supported objectives.
+func (o Objective) valid() bool {
+	switch o {
+	case "", ObjectiveMinItemsThenPacks, ObjectiveMinCost, ObjectiveMinItemsThenCost:
+		return true
+	default:
+		return false
+	}
+}
+
+// costAware reports whether the objective compares the cost of the packs.
+func (o Objective) costAware() bool {
+	return o == ObjectiveMinCost || o == ObjectiveMinItemsThenCost
+}
+
+// Costs holds the shipping cost of a calculation in the smallest currency unit
+// (e.g. cents). Pack sizes missing from PerPack cost nothing.
+type Costs struct {
+	// PerPack maps a pack size to the cost of shipping one pack of that size.
+	PerPack map[int]int
+
+	// PerShipment is a fixed cost added once to every non-empty shipment.
+	PerShipment int
+}
+
+// Total returns the cost of shipping the packs, including the shipment cost.
+func (c Costs) Total(packs map[int]int) int {
+	total := 0
+	for packSize, count := range packs {
+		total += c.PerPack[packSize] * count
+	}
+
+	if len(packs) > 0 {
+		total += c.PerShipment
+	}
+
+	return total
+}
+
+// clone returns a copy of the costs that shares no memory with them.
+func (c Costs) clone() Costs {
+	return Costs{
+		PerPack:     maps.Clone(c.PerPack),
+		PerShipment: c.PerShipment,
+	}
+}
+
+// UpdateCosts replaces the costs used by cost-aware objectives and reported
+// in every result. Costs must not be negative.
+func (pc *PackCalculator) UpdateCosts(costs Costs) {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+
+	pc.costs = costs.clone()
+}
+
+// GetCosts returns the currently configured costs.
+func (pc *PackCalculator) GetCosts() Costs {
+	pc.mu.RLock()
+	defer pc.mu.RUnlock()
+
+	return pc.costs.clone()
+}
+
+// configuration returns the pack sizes and costs as one consistent snapshot.
+func (pc *PackCalculator) configuration() ([]int, Costs) {
+	pc.mu.RLock()
+	defer pc.mu.RUnlock()
+
+	packSizes := make([]int, len(pc.packSizes))
+	copy(packSizes, pc.packSizes)
+
+	return packSizes, pc.costs.clone()
+}
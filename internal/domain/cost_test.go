@@ -0,0 +1,228 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCosts_Total(t *testing.T) {
+	costs := Costs{PerPack: map[int]int{250: 100, 500: 150}, PerShipment: 50}
+
+	tests := []struct {
+		name     string
+		packs    map[int]int
+		expected int
+	}{
+		{name: "no packs", packs: map[int]int{}, expected: 0},
+		{name: "priced packs", packs: map[int]int{250: 1, 500: 2}, expected: 100 + 2*150 + 50},
+		{name: "unpriced pack", packs: map[int]int{1000: 1}, expected: 50},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, costs.Total(tt.packs))
+		})
+	}
+}
+
+func TestPackCalculator_CalculateWithOptions_Objectives(t *testing.T) {
+	// The 1000 pack is cheaper than two 250 packs, so shipping more items can
+	// cost less.
+	costs := Costs{PerPack: map[int]int{250: 100, 500: 300, 1000: 120}}
+
+	tests := []struct {
+		name          string
+		objective     Objective
+		order         int
+		stock         map[int]int
+		expectedItems int
+		expectedPacks map[int]int
+		expectedCost  int
+	}{
+		{
+			name:          "default objective",
+			order:         300,
+			expectedItems: 500,
+			expectedPacks: map[int]int{500: 1},
+			expectedCost:  300,
+		},
+		{
+			name:          "min items then packs",
+			objective:     ObjectiveMinItemsThenPacks,
+			order:         300,
+			expectedItems: 500,
+			expectedPacks: map[int]int{500: 1},
+			expectedCost:  300,
+		},
+		{
+			name:          "min items then cost",
+			objective:     ObjectiveMinItemsThenCost,
+			order:         300,
+			expectedItems: 500,
+			expectedPacks: map[int]int{250: 2},
+			expectedCost:  200,
+		},
+		{
+			name:          "min cost",
+			objective:     ObjectiveMinCost,
+			order:         300,
+			expectedItems: 1000,
+			expectedPacks: map[int]int{1000: 1},
+			expectedCost:  120,
+		},
+		{
+			name:          "min cost within stock",
+			objective:     ObjectiveMinCost,
+			order:         300,
+			stock:         map[int]int{1000: 0},
+			expectedItems: 500,
+			expectedPacks: map[int]int{250: 2},
+			expectedCost:  200,
+		},
+		{
+			name:          "min cost of an empty order",
+			objective:     ObjectiveMinCost,
+			order:         0,
+			expectedItems: 0,
+			expectedPacks: map[int]int{},
+			expectedCost:  0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calculator := NewPackCalculator([]int{250, 500, 1000})
+			calculator.UpdateCosts(costs)
+
+			result, err := calculator.CalculateWithOptions(context.Background(), tt.order, CalculateOptions{
+				Stock:     tt.stock,
+				Objective: tt.objective,
+			})
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedItems, result.TotalItems)
+			assert.Equal(t, tt.expectedPacks, result.Packs)
+			assert.Equal(t, tt.expectedCost, result.TotalCost)
+		})
+	}
+
+	t.Run("should add the shipment cost", func(t *testing.T) {
+		calculator := NewPackCalculator([]int{250, 500, 1000})
+		calculator.UpdateCosts(Costs{PerPack: costs.PerPack, PerShipment: 500})
+
+		result, err := calculator.CalculateWithOptions(context.Background(), 300, CalculateOptions{Objective: ObjectiveMinCost})
+
+		require.NoError(t, err)
+		assert.Equal(t, map[int]int{1000: 1}, result.Packs)
+		assert.Equal(t, 620, result.TotalCost)
+	})
+
+	t.Run("should reject unknown objectives", func(t *testing.T) {
+		calculator := NewPackCalculator([]int{250})
+
+		_, err := calculator.CalculateWithOptions(context.Background(), 1, CalculateOptions{Objective: "fastest"})
+
+		assert.ErrorIs(t, err, ErrInvalidObjective)
+	})
+}
+
+func TestPackCalculator_UpdateCosts(t *testing.T) {
+	calculator := NewPackCalculator([]int{250, 500})
+	perPack := map[int]int{250: 100}
+
+	calculator.UpdateCosts(Costs{PerPack: perPack, PerShipment: 10})
+	perPack[250] = 1
+
+	assert.Equal(t, Costs{PerPack: map[int]int{250: 100}, PerShipment: 10}, calculator.GetCosts())
+
+	result := calculator.Calculate(251)
+	assert.Equal(t, 10, result.TotalCost, "the unpriced 500 pack only costs the shipment")
+}
+
+func TestPackCalculator_CalculateWithOptions_CostMatchesBruteForce(t *testing.T) {
+	packSets := [][]int{
+		{250, 500, 1000, 2000, 5000},
+		{3, 7, 11},
+		{6, 9, 20},
+	}
+
+	random := rand.New(rand.NewSource(1))
+
+	for _, packSizes := range packSets {
+		for i := 0; i < 100; i++ {
+			costs := Costs{PerPack: make(map[int]int)}
+			for _, size := range packSizes {
+				costs.PerPack[size] = random.Intn(20)
+			}
+
+			calculator := NewPackCalculator(packSizes)
+			calculator.UpdateCosts(costs)
+
+			order := 1 + random.Intn(4*packSizes[len(packSizes)-1])
+			message := fmt.Sprintf("sizes %v, costs %v, order %d", packSizes, costs.PerPack, order)
+
+			for _, objective := range []Objective{ObjectiveMinCost, ObjectiveMinItemsThenCost} {
+				expected := bruteForceCostSolution(packSizes, costs, order, objective)
+
+				result, err := calculator.CalculateWithOptions(context.Background(), order, CalculateOptions{Objective: objective})
+
+				require.NoError(t, err, message)
+				assert.Equal(t, expected, costSolution{
+					cost:  result.TotalCost,
+					items: result.TotalItems,
+					packs: result.GetTotalPackCount(),
+				}, "%s, objective %s", message, objective)
+			}
+		}
+	}
+}
+
+// costSolution is the comparison key of a cost-aware solution.
+type costSolution struct {
+	cost, items, packs int
+}
+
+// bruteForceCostSolution enumerates every combination up to the search limit and
+// returns the best one for the objective.
+func bruteForceCostSolution(packSizes []int, costs Costs, order int, objective Objective) costSolution {
+	searchLimit := order + packSizes[len(packSizes)-1]
+	best := costSolution{items: -1}
+
+	key := func(s costSolution) [3]int {
+		if objective == ObjectiveMinCost {
+			return [3]int{s.cost, s.items, s.packs}
+		}
+		return [3]int{s.items, s.cost, s.packs}
+	}
+
+	var enumerate func(index int, current costSolution)
+	enumerate = func(index int, current costSolution) {
+		if index == len(packSizes) {
+			if current.items < order {
+				return
+			}
+			a, b := key(current), key(best)
+			if best.items == -1 || a[0] < b[0] || (a[0] == b[0] && (a[1] < b[1] || (a[1] == b[1] && a[2] < b[2]))) {
+				best = current
+			}
+			return
+		}
+
+		size := packSizes[index]
+		for count := 0; current.items+count*size <= searchLimit; count++ {
+			enumerate(index+1, costSolution{
+				cost:  current.cost + count*costs.PerPack[size],
+				items: current.items + count*size,
+				packs: current.packs + count,
+			})
+		}
+	}
+	enumerate(0, costSolution{})
+
+	return best
+}
@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Errors returned by PackCalculator.CalculateContext and CalculateWithOptions. Cancellation is reported
+// with the context's own error (context.Canceled or context.DeadlineExceeded).
+var (
+	// ErrInvalidOrder is returned for negative order quantities.
+	ErrInvalidOrder = errors.New("order must not be negative")
+
+	// ErrNoPackSizes is returned when there are no pack sizes to fulfill the order with.
+	ErrNoPackSizes = errors.New("no pack sizes configured")
+
+	// ErrOrderTooLarge is returned when solving the order would need a table
+	// larger than the calculator is willing to allocate.
+	ErrOrderTooLarge = errors.New("order is too large to calculate")
+
+	// ErrInfeasible is returned when no combination of packs fulfills the order.
+	ErrInfeasible = errors.New("order cannot be fulfilled with the available pack sizes")
+
+	// ErrInsufficientStock is returned when the packs in stock cannot fulfill the
+	// order. It wraps ErrInfeasible.
+	ErrInsufficientStock = fmt.Errorf("%w: not enough packs in stock", ErrInfeasible)
+
+	// ErrRulesInfeasible is returned when the pack size rules leave no combination
+	// that fulfills the order. It wraps ErrInfeasible.
+	ErrRulesInfeasible = fmt.Errorf("%w: pack size rules exclude every combination", ErrInfeasible)
+
+	// ErrNoExactFit is returned when an exact fit is required and no combination
+	// ships exactly the order. It wraps ErrInfeasible.
+	ErrNoExactFit = fmt.Errorf("%w: no combination matches the order exactly", ErrInfeasible)
+
+	// ErrSurplusExceeded is returned when every combination ships more items above
+	// the order than the maximum surplus allows. It wraps ErrInfeasible.
+	ErrSurplusExceeded = fmt.Errorf("%w: every combination exceeds the maximum surplus", ErrInfeasible)
+
+	// ErrInvalidMaxSurplus is returned for negative maximum surpluses.
+	ErrInvalidMaxSurplus = errors.New("maximum surplus must not be negative")
+
+	// ErrInvalidMode is returned for unknown modes and for ModeUnderFulfil
+	// combined with a cost-aware objective.
+	ErrInvalidMode = errors.New("invalid calculation mode")
+
+	// ErrInvalidStock is returned for negative stock levels.
+	ErrInvalidStock = errors.New("stock must not be negative")
+
+	// ErrInvalidObjective is returned for objectives that are not supported.
+	ErrInvalidObjective = errors.New("unknown objective")
+
+	// ErrInvalidStrategy is returned for unknown strategies and for strategies
+	// combined with a cost-aware objective.
+	ErrInvalidStrategy = errors.New("invalid strategy")
+
+	// ErrParcelLimit is returned when a single pack is heavier or larger than
+	// a parcel may be.
+	ErrParcelLimit = errors.New("pack exceeds the parcel limits")
+
+	// ErrNotExplainable is returned by Explain for orders restricted by the pack
+	// size rules, which are not solved with the dynamic programming table alone.
+	ErrNotExplainable = errors.New("order is restricted by pack size rules and cannot be explained")
+)
@@ -0,0 +1,183 @@
+package domain
+
+import (
+	"context"
+	"maps"
+)
+
+// Reasons given for the candidates of an explanation.
+const (
+	ReasonChosen      = "chosen"
+	ReasonTooFewItems = "ships fewer items than ordered"
+	ReasonMoreItems   = "ships more items"
+	ReasonMorePacks   = "uses more packs"
+	ReasonLargerPacks = "tied on packs; the larger last pack was preferred"
+)
+
+// tieRuleFewestPacks describes how the table decides between combinations.
+const tieRuleFewestPacks = "fewest items, then fewest packs, then larger packs"
+
+// Explanation describes how the result of a calculation was chosen.
+type Explanation struct {
+	// SearchFrom and SearchTo bound the totals searched: the order up to the order
+	// plus the largest pack. Larger totals always ship a removable pack.
+	SearchFrom int
+	SearchTo   int
+
+	// LargestPacksAdded counts the packs of the largest size added before the
+	// search, when the order is above the period threshold.
+	LargestPacksAdded int
+
+	// NearestBelow is the largest reachable total below the order, and
+	// NearestAbove the first reachable total above the chosen one within the
+	// searched range. Either is 0 when there is none.
+	NearestBelow int
+	NearestAbove int
+
+	// TieRule names the rule that decides between combinations of the same total.
+	TieRule string
+
+	// Candidates lists the chosen combination, the other combinations the table
+	// compared for its total, and the combinations of the nearest totals.
+	Candidates []ExplainedCandidate
+}
+
+// ExplainedCandidate is a combination considered for an explanation.
+type ExplainedCandidate struct {
+	TotalItems int
+	Packs      map[int]int
+	TotalPacks int
+	Reason     string
+}
+
+// Explain computes the optimal pack combination like CalculateContext, grouped
+// into parcels like CalculateWithOptions, and explains the choice from the
+// state of the dynamic programming table. Orders restricted by the pack size
+// rules fail with ErrNotExplainable.
+func (pc *PackCalculator) Explain(ctx context.Context, order int) (PackResult, Explanation, error) {
+	ctx, cancel, err := pc.bound(ctx, order)
+	defer cancel()
+	if err != nil {
+		return PackResult{}, Explanation{}, err
+	}
+
+	if pc.GetRules().Restricts(order) {
+		return PackResult{}, Explanation{}, ErrNotExplainable
+	}
+
+	packSizes, costs := pc.configuration()
+
+	plan, err := planOrder(order, packSizes)
+	if err != nil {
+		return PackResult{}, Explanation{}, err
+	}
+
+	table, err := pc.tableFor(ctx, packSizes, plan.searchLimit(packSizes))
+	if err != nil {
+		return PackResult{}, Explanation{}, err
+	}
+
+	result, err := pc.resolveOrderPlan(table, plan, costs)
+	if err != nil {
+		return PackResult{}, Explanation{}, err
+	}
+
+	explanation := explainOrderPlan(table, plan, result)
+
+	result, err = pc.groupParcels(result)
+	if err != nil {
+		return PackResult{}, Explanation{}, err
+	}
+
+	return result, explanation, nil
+}
+
+// explainOrderPlan explains a result read from the table. Totals in the table are
+// relative to the residual order; the largest packs of the plan are added back.
+func explainOrderPlan(table *packTable, plan orderPlan, result PackResult) Explanation {
+	explanation := Explanation{
+		SearchFrom: plan.order,
+		SearchTo:   plan.order,
+		TieRule:    tieRuleFewestPacks,
+	}
+
+	if plan.order == 0 {
+		return explanation
+	}
+
+	largestPack := table.packSizes[len(table.packSizes)-1]
+	offset := plan.largestPacks * largestPack
+
+	explanation.SearchTo = plan.order + largestPack
+	explanation.LargestPacksAdded = plan.largestPacks
+
+	candidate := func(quantity int, packs map[int]int, reason string) ExplainedCandidate {
+		if plan.largestPacks > 0 {
+			packs[largestPack] += plan.largestPacks
+		}
+
+		explained := ExplainedCandidate{
+			TotalItems: quantity + offset,
+			Packs:      packs,
+			Reason:     reason,
+		}
+		for _, count := range packs {
+			explained.TotalPacks += count
+		}
+		return explained
+	}
+
+	chosen := result.TotalItems - offset
+	explanation.Candidates = append(explanation.Candidates, candidate(chosen, table.packs(chosen), ReasonChosen))
+
+	// Every pack size that completes the chosen total from a reachable smaller
+	// total was compared when the table was built, largest first.
+	chosenPacks := table.packCounts[chosen]
+	for packIndex := len(table.packSizes) - 1; packIndex >= 0; packIndex-- {
+		previous := chosen - table.packSizes[packIndex]
+		if packIndex == int(table.lastPacks[chosen]) || !table.reachable(previous) {
+			continue
+		}
+
+		packs := table.packs(previous)
+		packs[table.packSizes[packIndex]]++
+
+		reason := ReasonMorePacks
+		if table.packCounts[previous]+1 == chosenPacks {
+			reason = ReasonLargerPacks
+		}
+
+		compared := candidate(chosen, packs, reason)
+		if !containsPacks(explanation.Candidates, compared.Packs) {
+			explanation.Candidates = append(explanation.Candidates, compared)
+		}
+	}
+
+	for quantity := plan.residualOrder - 1; quantity+offset > 0; quantity-- {
+		if table.reachable(quantity) {
+			explanation.NearestBelow = quantity + offset
+			explanation.Candidates = append(explanation.Candidates, candidate(quantity, table.packs(quantity), ReasonTooFewItems))
+			break
+		}
+	}
+
+	for quantity := chosen + 1; quantity <= plan.searchLimit(table.packSizes); quantity++ {
+		if table.reachable(quantity) {
+			explanation.NearestAbove = quantity + offset
+			explanation.Candidates = append(explanation.Candidates, candidate(quantity, table.packs(quantity), ReasonMoreItems))
+			break
+		}
+	}
+
+	return explanation
+}
+
+// containsPacks reports whether one of the candidates ships exactly the packs.
+func containsPacks(candidates []ExplainedCandidate, packs map[int]int) bool {
+	for _, candidate := range candidates {
+		if maps.Equal(candidate.Packs, packs) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPackCalculator_Explain(t *testing.T) {
+	calculator := NewPackCalculator([]int{250, 500, 1000, 2000, 5000})
+
+	t.Run("should explain order 12001", func(t *testing.T) {
+		result, explanation, err := calculator.Explain(context.Background(), 12001)
+
+		require.NoError(t, err)
+		assert.Equal(t, calculator.Calculate(12001), result)
+		assert.Equal(t, Explanation{
+			SearchFrom:   12001,
+			SearchTo:     17001,
+			NearestBelow: 12000,
+			NearestAbove: 12500,
+			TieRule:      tieRuleFewestPacks,
+			Candidates: []ExplainedCandidate{
+				{TotalItems: 12250, Packs: map[int]int{5000: 2, 2000: 1, 250: 1}, TotalPacks: 4, Reason: ReasonChosen},
+				{TotalItems: 12250, Packs: map[int]int{5000: 2, 1000: 2, 250: 1}, TotalPacks: 5, Reason: ReasonMorePacks},
+				{TotalItems: 12250, Packs: map[int]int{5000: 2, 1000: 1, 500: 2, 250: 1}, TotalPacks: 6, Reason: ReasonMorePacks},
+				{TotalItems: 12000, Packs: map[int]int{5000: 2, 2000: 1}, TotalPacks: 3, Reason: ReasonTooFewItems},
+				{TotalItems: 12500, Packs: map[int]int{5000: 2, 2000: 1, 500: 1}, TotalPacks: 4, Reason: ReasonMoreItems},
+			},
+		}, explanation)
+	})
+
+	t.Run("should report ties lost to larger packs", func(t *testing.T) {
+		tied := NewPackCalculator([]int{250, 500, 750})
+
+		_, explanation, err := tied.Explain(context.Background(), 1000)
+
+		require.NoError(t, err)
+		require.Len(t, explanation.Candidates, 4)
+		assert.Equal(t, ExplainedCandidate{TotalItems: 1000, Packs: map[int]int{750: 1, 250: 1}, TotalPacks: 2, Reason: ReasonChosen}, explanation.Candidates[0])
+		assert.Equal(t, ExplainedCandidate{TotalItems: 1000, Packs: map[int]int{500: 2}, TotalPacks: 2, Reason: ReasonLargerPacks}, explanation.Candidates[1])
+	})
+
+	t.Run("should add back the largest packs of large orders", func(t *testing.T) {
+		result, explanation, err := calculator.Explain(context.Background(), 1_000_000_001)
+
+		require.NoError(t, err)
+		assert.Equal(t, 1_000_000_250, result.TotalItems)
+		assert.Equal(t, 1_000_005_001, explanation.SearchTo)
+		assert.Positive(t, explanation.LargestPacksAdded)
+		assert.Equal(t, 1_000_000_000, explanation.NearestBelow)
+		assert.Equal(t, 1_000_000_500, explanation.NearestAbove)
+		assert.Equal(t, result.Packs, explanation.Candidates[0].Packs)
+	})
+
+	t.Run("should not list an empty shipment below the order", func(t *testing.T) {
+		_, explanation, err := calculator.Explain(context.Background(), 1)
+
+		require.NoError(t, err)
+		assert.Zero(t, explanation.NearestBelow)
+		for _, candidate := range explanation.Candidates {
+			assert.NotEqual(t, ReasonTooFewItems, candidate.Reason)
+		}
+	})
+
+	t.Run("should explain an empty order", func(t *testing.T) {
+		_, explanation, err := calculator.Explain(context.Background(), 0)
+
+		require.NoError(t, err)
+		assert.Equal(t, Explanation{TieRule: tieRuleFewestPacks}, explanation)
+	})
+
+	t.Run("should report invalid orders", func(t *testing.T) {
+		_, _, err := calculator.Explain(context.Background(), -1)
+
+		assert.ErrorIs(t, err, ErrInvalidOrder)
+	})
+}
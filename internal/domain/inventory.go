@@ -0,0 +1,245 @@
+package domain
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxReserveAttempts bounds how often ReserveOrder re-plans an order whose packs
+// were claimed by a concurrent reservation.
+const maxReserveAttempts = 3
+
+var (
+	// ErrInvalidPackCount is returned when receiving or reserving a non-positive number of packs.
+	ErrInvalidPackCount = errors.New("pack count must be positive")
+
+	// ErrEmptyReservation is returned when a reservation holds no packs.
+	ErrEmptyReservation = errors.New("reservation must contain packs")
+
+	// ErrReservationNotFound is returned for unknown or already settled reservations.
+	ErrReservationNotFound = errors.New("reservation not found")
+)
+
+// StockLevel is the stock of a single pack size. Available packs are the packs
+// on hand that are not reserved.
+type StockLevel struct {
+	PackSize  int
+	OnHand    int
+	Reserved  int
+	Available int
+}
+
+// Reservation holds packs for an order until it is confirmed or released.
+type Reservation struct {
+	ID        string
+	Packs     map[int]int
+	CreatedAt time.Time
+}
+
+// Inventory tracks the packs on hand per pack size and the packs reserved for
+// orders. Reserving checks and claims the packs under one lock, so concurrent
+// reservations can never claim the same packs. The inventory is held in memory
+// only and is not persisted.
+type Inventory struct {
+	// planMu serializes ReserveOrder, so concurrent orders plan one after the
+	// other and never from the same snapshot of the available stock
+	planMu sync.Mutex
+
+	mu           sync.Mutex
+	onHand       map[int]int
+	reserved     map[int]int
+	reservations map[string]Reservation
+	lastID       int
+}
+
+// NewInventory creates an empty inventory.
+func NewInventory() *Inventory {
+	return &Inventory{
+		onHand:       make(map[int]int),
+		reserved:     make(map[int]int),
+		reservations: make(map[string]Reservation),
+	}
+}
+
+// Receive adds count packs of the given size to the stock on hand.
+func (inv *Inventory) Receive(packSize, count int) error {
+	if packSize <= 0 || count <= 0 {
+		return ErrInvalidPackCount
+	}
+
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+
+	inv.onHand[packSize] += count
+	return nil
+}
+
+// Levels returns the stock level of every pack size ever received, sorted by pack size.
+func (inv *Inventory) Levels() []StockLevel {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+
+	levels := make([]StockLevel, 0, len(inv.onHand))
+	for packSize, onHand := range inv.onHand {
+		levels = append(levels, StockLevel{
+			PackSize:  packSize,
+			OnHand:    onHand,
+			Reserved:  inv.reserved[packSize],
+			Available: onHand - inv.reserved[packSize],
+		})
+	}
+
+	sort.Slice(levels, func(i, j int) bool {
+		return levels[i].PackSize < levels[j].PackSize
+	})
+
+	return levels
+}
+
+// Available returns the unreserved packs of each of the given pack sizes, with
+// zero for sizes that are not in stock. The result can be used as the Stock of
+// CalculateOptions.
+func (inv *Inventory) Available(packSizes []int) map[int]int {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+
+	available := make(map[int]int, len(packSizes))
+	for _, packSize := range packSizes {
+		available[packSize] = inv.onHand[packSize] - inv.reserved[packSize]
+	}
+
+	return available
+}
+
+// Reserve claims the packs if every one of them is available, or claims nothing
+// and returns ErrInsufficientStock.
+func (inv *Inventory) Reserve(packs map[int]int) (Reservation, error) {
+	total := 0
+	for _, count := range packs {
+		if count < 0 {
+			return Reservation{}, ErrInvalidPackCount
+		}
+		total += count
+	}
+
+	if total == 0 {
+		return Reservation{}, ErrEmptyReservation
+	}
+
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+
+	for packSize, count := range packs {
+		if count > inv.onHand[packSize]-inv.reserved[packSize] {
+			return Reservation{}, fmt.Errorf("%w: %d packs of %d requested", ErrInsufficientStock, count, packSize)
+		}
+	}
+
+	reservation := Reservation{
+		Packs:     make(map[int]int, len(packs)),
+		CreatedAt: time.Now().UTC(),
+	}
+	for packSize, count := range packs {
+		if count > 0 {
+			reservation.Packs[packSize] = count
+			inv.reserved[packSize] += count
+		}
+	}
+
+	inv.lastID++
+	reservation.ID = fmt.Sprintf("rsv-%d", inv.lastID)
+	inv.reservations[reservation.ID] = reservation
+
+	return cloneReservation(reservation), nil
+}
+
+// ReserveOrder calculates the order from the available stock only and reserves
+// the resulting packs. The stock of opts is replaced by the available stock. Orders reserved this way are planned one at a time; if a
+// direct Reserve call claims some of the planned packs first, the order is
+// planned again from the remaining stock.
+func (inv *Inventory) ReserveOrder(ctx context.Context, calculator *PackCalculator, order int, opts CalculateOptions) (PackResult, Reservation, error) {
+	inv.planMu.Lock()
+	defer inv.planMu.Unlock()
+
+	for attempt := 0; attempt < maxReserveAttempts; attempt++ {
+		opts.Stock = inv.Available(calculator.GetPackSizes())
+
+		result, err := calculator.CalculateWithOptions(ctx, order, opts)
+		if err != nil {
+			return PackResult{}, Reservation{}, err
+		}
+
+		reservation, err := inv.Reserve(result.Packs)
+		if errors.Is(err, ErrInsufficientStock) {
+			continue
+		}
+		if err != nil {
+			return PackResult{}, Reservation{}, err
+		}
+
+		return result, reservation, nil
+	}
+
+	return PackResult{}, Reservation{}, ErrInsufficientStock
+}
+
+// Reservations returns the open reservations, oldest first.
+func (inv *Inventory) Reservations() []Reservation {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+
+	reservations := make([]Reservation, 0, len(inv.reservations))
+	for _, reservation := range inv.reservations {
+		reservations = append(reservations, cloneReservation(reservation))
+	}
+
+	// IDs are numbered in creation order, so shorter IDs are older
+	slices.SortFunc(reservations, func(a, b Reservation) int {
+		return cmp.Or(cmp.Compare(len(a.ID), len(b.ID)), strings.Compare(a.ID, b.ID))
+	})
+
+	return reservations
+}
+
+// Confirm settles a reservation as shipped, removing its packs from the stock on hand.
+func (inv *Inventory) Confirm(id string) (Reservation, error) {
+	return inv.settle(id, true)
+}
+
+// Release cancels a reservation, making its packs available again.
+func (inv *Inventory) Release(id string) (Reservation, error) {
+	return inv.settle(id, false)
+}
+
+func (inv *Inventory) settle(id string, shipped bool) (Reservation, error) {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+
+	reservation, exists := inv.reservations[id]
+	if !exists {
+		return Reservation{}, ErrReservationNotFound
+	}
+
+	for packSize, count := range reservation.Packs {
+		inv.reserved[packSize] -= count
+		if shipped {
+			inv.onHand[packSize] -= count
+		}
+	}
+	delete(inv.reservations, id)
+
+	return reservation, nil
+}
+
+func cloneReservation(reservation Reservation) Reservation {
+	reservation.Packs = maps.Clone(reservation.Packs)
+	return reservation
+}
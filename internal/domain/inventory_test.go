@@ -0,0 +1,205 @@
+package domain
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInventory_Receive(t *testing.T) {
+	inventory := NewInventory()
+
+	require.NoError(t, inventory.Receive(500, 3))
+	require.NoError(t, inventory.Receive(250, 2))
+	require.NoError(t, inventory.Receive(500, 1))
+
+	assert.Equal(t, []StockLevel{
+		{PackSize: 250, OnHand: 2, Available: 2},
+		{PackSize: 500, OnHand: 4, Available: 4},
+	}, inventory.Levels())
+
+	assert.ErrorIs(t, inventory.Receive(500, 0), ErrInvalidPackCount)
+	assert.ErrorIs(t, inventory.Receive(500, -1), ErrInvalidPackCount)
+	assert.ErrorIs(t, inventory.Receive(0, 1), ErrInvalidPackCount)
+}
+
+func TestInventory_Reserve(t *testing.T) {
+	t.Run("should hold reserved packs until they are confirmed", func(t *testing.T) {
+		inventory := NewInventory()
+		require.NoError(t, inventory.Receive(500, 3))
+
+		reservation, err := inventory.Reserve(map[int]int{500: 2})
+		require.NoError(t, err)
+		assert.Equal(t, "rsv-1", reservation.ID)
+		assert.Equal(t, map[int]int{500: 2}, reservation.Packs)
+
+		assert.Equal(t, []StockLevel{{PackSize: 500, OnHand: 3, Reserved: 2, Available: 1}}, inventory.Levels())
+		assert.Equal(t, map[int]int{250: 0, 500: 1}, inventory.Available([]int{250, 500}))
+
+		confirmed, err := inventory.Confirm(reservation.ID)
+		require.NoError(t, err)
+		assert.Equal(t, reservation.Packs, confirmed.Packs)
+
+		assert.Equal(t, []StockLevel{{PackSize: 500, OnHand: 1, Available: 1}}, inventory.Levels())
+		assert.Empty(t, inventory.Reservations())
+	})
+
+	t.Run("should return released packs to the available stock", func(t *testing.T) {
+		inventory := NewInventory()
+		require.NoError(t, inventory.Receive(500, 3))
+
+		reservation, err := inventory.Reserve(map[int]int{500: 3})
+		require.NoError(t, err)
+
+		_, err = inventory.Release(reservation.ID)
+		require.NoError(t, err)
+
+		assert.Equal(t, []StockLevel{{PackSize: 500, OnHand: 3, Available: 3}}, inventory.Levels())
+	})
+
+	t.Run("should claim nothing when any pack is missing", func(t *testing.T) {
+		inventory := NewInventory()
+		require.NoError(t, inventory.Receive(500, 3))
+		require.NoError(t, inventory.Receive(250, 1))
+
+		_, err := inventory.Reserve(map[int]int{500: 1, 250: 2})
+		assert.ErrorIs(t, err, ErrInsufficientStock)
+
+		assert.Equal(t, map[int]int{250: 1, 500: 3}, inventory.Available([]int{250, 500}))
+	})
+
+	t.Run("should reject invalid reservations", func(t *testing.T) {
+		inventory := NewInventory()
+		require.NoError(t, inventory.Receive(500, 3))
+
+		_, err := inventory.Reserve(map[int]int{})
+		assert.ErrorIs(t, err, ErrEmptyReservation)
+
+		_, err = inventory.Reserve(map[int]int{500: 0})
+		assert.ErrorIs(t, err, ErrEmptyReservation)
+
+		_, err = inventory.Reserve(map[int]int{500: -1})
+		assert.ErrorIs(t, err, ErrInvalidPackCount)
+	})
+
+	t.Run("should settle a reservation only once", func(t *testing.T) {
+		inventory := NewInventory()
+		require.NoError(t, inventory.Receive(500, 3))
+
+		reservation, err := inventory.Reserve(map[int]int{500: 1})
+		require.NoError(t, err)
+
+		_, err = inventory.Confirm(reservation.ID)
+		require.NoError(t, err)
+
+		_, err = inventory.Confirm(reservation.ID)
+		assert.ErrorIs(t, err, ErrReservationNotFound)
+		_, err = inventory.Release(reservation.ID)
+		assert.ErrorIs(t, err, ErrReservationNotFound)
+
+		assert.Equal(t, []StockLevel{{PackSize: 500, OnHand: 2, Available: 2}}, inventory.Levels())
+	})
+
+	t.Run("should list open reservations oldest first", func(t *testing.T) {
+		inventory := NewInventory()
+		require.NoError(t, inventory.Receive(1, 20))
+
+		for i := 0; i < 11; i++ {
+			_, err := inventory.Reserve(map[int]int{1: 1})
+			require.NoError(t, err)
+		}
+
+		reservations := inventory.Reservations()
+		require.Len(t, reservations, 11)
+		assert.Equal(t, "rsv-1", reservations[0].ID)
+		assert.Equal(t, "rsv-2", reservations[1].ID)
+		assert.Equal(t, "rsv-11", reservations[10].ID)
+	})
+
+	t.Run("should never let concurrent reservations claim the same packs", func(t *testing.T) {
+		inventory := NewInventory()
+		require.NoError(t, inventory.Receive(500, 10))
+
+		var (
+			wg       sync.WaitGroup
+			mu       sync.Mutex
+			reserved int
+		)
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := inventory.Reserve(map[int]int{500: 1}); err == nil {
+					mu.Lock()
+					reserved++
+					mu.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, 10, reserved)
+		assert.Equal(t, []StockLevel{{PackSize: 500, OnHand: 10, Reserved: 10}}, inventory.Levels())
+	})
+}
+
+func TestInventory_ReserveOrder(t *testing.T) {
+	t.Run("should plan from the available stock and reserve the packs", func(t *testing.T) {
+		calculator := NewPackCalculator([]int{250, 500, 1000, 2000, 5000})
+		inventory := NewInventory()
+		require.NoError(t, inventory.Receive(5000, 1))
+		require.NoError(t, inventory.Receive(2000, 5))
+		require.NoError(t, inventory.Receive(1000, 5))
+		require.NoError(t, inventory.Receive(250, 5))
+
+		result, reservation, err := inventory.ReserveOrder(context.Background(), calculator, 12001, CalculateOptions{})
+		require.NoError(t, err)
+
+		assert.Equal(t, 12250, result.TotalItems)
+		assert.Equal(t, map[int]int{5000: 1, 2000: 3, 1000: 1, 250: 1}, result.Packs)
+		assert.Equal(t, result.Packs, reservation.Packs)
+		assert.Equal(t, map[int]int{250: 4, 500: 0, 1000: 4, 2000: 2, 5000: 0}, inventory.Available(calculator.GetPackSizes()))
+	})
+
+	t.Run("should fail when the available stock cannot cover the order", func(t *testing.T) {
+		calculator := NewPackCalculator([]int{250, 500})
+		inventory := NewInventory()
+		require.NoError(t, inventory.Receive(500, 1))
+
+		_, err := inventory.Reserve(map[int]int{500: 1})
+		require.NoError(t, err)
+
+		_, _, err = inventory.ReserveOrder(context.Background(), calculator, 1, CalculateOptions{})
+		assert.ErrorIs(t, err, ErrInsufficientStock)
+	})
+
+	t.Run("should split the stock between concurrent orders", func(t *testing.T) {
+		calculator := NewPackCalculator([]int{250, 500})
+		inventory := NewInventory()
+		require.NoError(t, inventory.Receive(500, 5))
+
+		var (
+			wg        sync.WaitGroup
+			mu        sync.Mutex
+			fulfilled int
+		)
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, _, err := inventory.ReserveOrder(context.Background(), calculator, 500, CalculateOptions{}); err == nil {
+					mu.Lock()
+					fulfilled++
+					mu.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, 5, fulfilled)
+		assert.Equal(t, []StockLevel{{PackSize: 500, OnHand: 5, Reserved: 5}}, inventory.Levels())
+	})
+}
@@ -0,0 +1,146 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/semaphore"
+)
+
+// ErrSaturated is returned when a calculation cannot be admitted by the Limiter
+// within its queue timeout.
+var ErrSaturated = errors.New("calculation capacity is saturated")
+
+// SaturatedError is returned when the Limiter rejects a calculation. It wraps
+// ErrSaturated.
+type SaturatedError struct {
+	// RetryAfter is how long the caller should wait before trying again.
+	RetryAfter time.Duration
+}
+
+// Error implements error.
+func (e *SaturatedError) Error() string {
+	return fmt.Sprintf("%v: retry after %s", ErrSaturated, e.RetryAfter)
+}
+
+// Unwrap returns ErrSaturated.
+func (e *SaturatedError) Unwrap() error {
+	return ErrSaturated
+}
+
+// Limiter bounds the table quantities allocated by concurrent calculations, so
+// that a few large orders cannot take every CPU and most of the memory. It is a
+// weighted semaphore: a calculation that allocates a table is admitted with a
+// weight equal to the table's quantities (per stock layer, and at most the
+// capacity), waiting up to the queue timeout for others to finish. Calculations
+// answered without a new table, from the cache, the answer table or the shared
+// table, are never held back.
+type Limiter struct {
+	semaphore    *semaphore.Weighted
+	capacity     int64
+	queueTimeout time.Duration
+
+	inUse    atomic.Int64
+	waiting  atomic.Int64
+	admitted atomic.Int64
+	rejected atomic.Int64
+}
+
+// LimiterStats reports the usage of a Limiter since it was created.
+type LimiterStats struct {
+	Capacity int64
+	InUse    int64
+	Waiting  int64
+	Admitted int64
+	Rejected int64
+}
+
+// NewLimiter creates a limiter admitting calculations up to capacity table
+// quantities at once. Calculations wait up to queueTimeout to be admitted; with
+// a zero timeout they are rejected at once when the capacity is used up.
+func NewLimiter(capacity int64, queueTimeout time.Duration) *Limiter {
+	return &Limiter{
+		semaphore:    semaphore.NewWeighted(capacity),
+		capacity:     capacity,
+		queueTimeout: queueTimeout,
+	}
+}
+
+// acquire admits a calculation with the given weight and returns the weight it
+// holds, to be released when it ends. Calculations that are not admitted in time
+// fail with a SaturatedError, or with the cause of ctx when it is done first.
+func (l *Limiter) acquire(ctx context.Context, weight int64) (int64, error) {
+	weight = min(max(weight, 1), l.capacity)
+
+	if !l.semaphore.TryAcquire(weight) {
+		if l.queueTimeout <= 0 {
+			l.rejected.Add(1)
+			return 0, l.saturated()
+		}
+
+		l.waiting.Add(1)
+		queued, cancel := context.WithTimeout(ctx, l.queueTimeout)
+		err := l.semaphore.Acquire(queued, weight)
+		cancel()
+		l.waiting.Add(-1)
+
+		if err != nil {
+			if ctx.Err() != nil {
+				return 0, context.Cause(ctx)
+			}
+			l.rejected.Add(1)
+			return 0, l.saturated()
+		}
+	}
+
+	l.inUse.Add(weight)
+	l.admitted.Add(1)
+	return weight, nil
+}
+
+// release returns the weight held by a calculation.
+func (l *Limiter) release(weight int64) {
+	l.inUse.Add(-weight)
+	l.semaphore.Release(weight)
+}
+
+// saturated returns the error of a rejected calculation. Callers are asked to
+// retry after the queue timeout, and after a second at least.
+func (l *Limiter) saturated() error {
+	return &SaturatedError{RetryAfter: max(l.queueTimeout, time.Second)}
+}
+
+// Stats returns the capacity, the weight in use, the calculations waiting and
+// the counts of admitted and rejected calculations.
+func (l *Limiter) Stats() LimiterStats {
+	return LimiterStats{
+		Capacity: l.capacity,
+		InUse:    l.inUse.Load(),
+		Waiting:  l.waiting.Load(),
+		Admitted: l.admitted.Load(),
+		Rejected: l.rejected.Load(),
+	}
+}
+
+// admit admits the calculation of ctx to the limiter of its budget before it
+// allocates a table of the given quantities. A calculation is admitted once,
+// with the weight of its first table; the weight is released when the context
+// returned by Limits.withBudget is cancelled.
+func admit(ctx context.Context, quantities int) error {
+	b, ok := ctx.Value(budgetKey{}).(*budget)
+	if !ok || b.limiter == nil || !b.admitted.CompareAndSwap(false, true) {
+		return nil
+	}
+
+	held, err := b.limiter.acquire(ctx, int64(quantities))
+	if err != nil {
+		b.admitted.Store(false)
+		return err
+	}
+
+	b.held.Store(held)
+	return nil
+}
@@ -0,0 +1,159 @@
+package domain
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimiter(t *testing.T) {
+	t.Run("should reject calculations when saturated", func(t *testing.T) {
+		limiter := NewLimiter(100, 0)
+
+		held, err := limiter.acquire(context.Background(), 80)
+		require.NoError(t, err)
+		assert.Equal(t, int64(80), held)
+
+		_, err = limiter.acquire(context.Background(), 30)
+		assert.ErrorIs(t, err, ErrSaturated)
+
+		var saturatedErr *SaturatedError
+		require.ErrorAs(t, err, &saturatedErr)
+		assert.Equal(t, time.Second, saturatedErr.RetryAfter)
+
+		limiter.release(held)
+		_, err = limiter.acquire(context.Background(), 30)
+		assert.NoError(t, err)
+
+		assert.Equal(t, LimiterStats{Capacity: 100, InUse: 30, Admitted: 2, Rejected: 1}, limiter.Stats())
+	})
+
+	t.Run("should clamp weights to the capacity", func(t *testing.T) {
+		limiter := NewLimiter(100, 0)
+
+		held, err := limiter.acquire(context.Background(), 1_000_000)
+		require.NoError(t, err)
+		assert.Equal(t, int64(100), held)
+
+		held, err = NewLimiter(100, 0).acquire(context.Background(), 0)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), held)
+	})
+
+	t.Run("should queue calculations until capacity is released", func(t *testing.T) {
+		limiter := NewLimiter(100, time.Minute)
+
+		held, err := limiter.acquire(context.Background(), 100)
+		require.NoError(t, err)
+
+		done := make(chan error)
+		go func() {
+			_, err := limiter.acquire(context.Background(), 50)
+			done <- err
+		}()
+
+		require.Eventually(t, func() bool { return limiter.Stats().Waiting == 1 }, time.Second, time.Millisecond)
+		limiter.release(held)
+
+		require.NoError(t, <-done)
+		assert.Equal(t, LimiterStats{Capacity: 100, InUse: 50, Admitted: 2}, limiter.Stats())
+	})
+
+	t.Run("should reject queued calculations after the queue timeout", func(t *testing.T) {
+		limiter := NewLimiter(100, 10*time.Millisecond)
+
+		_, err := limiter.acquire(context.Background(), 100)
+		require.NoError(t, err)
+
+		_, err = limiter.acquire(context.Background(), 1)
+		assert.ErrorIs(t, err, ErrSaturated)
+		assert.Equal(t, int64(1), limiter.Stats().Rejected)
+	})
+
+	t.Run("should return the cause of a cancelled context while queued", func(t *testing.T) {
+		limiter := NewLimiter(100, time.Minute)
+
+		_, err := limiter.acquire(context.Background(), 100)
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err = limiter.acquire(ctx, 1)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Zero(t, limiter.Stats().Rejected)
+	})
+}
+
+func TestPackCalculator_Limiter(t *testing.T) {
+	packSizes := []int{250, 500, 1000, 2000, 5000}
+
+	t.Run("should release the capacity when the calculation ends", func(t *testing.T) {
+		limiter := NewLimiter(1_000_000, 0)
+		calculator := NewPackCalculator(packSizes, WithLimits(Limits{Limiter: limiter}))
+
+		_, err := calculator.CalculateContext(context.Background(), 12001)
+		require.NoError(t, err)
+
+		_, err = calculator.CalculateWithOptions(context.Background(), 12001, CalculateOptions{Stock: map[int]int{5000: 1}})
+		require.NoError(t, err)
+
+		assert.Equal(t, LimiterStats{Capacity: 1_000_000, Admitted: 2}, limiter.Stats())
+	})
+
+	t.Run("should not hold back calculations answered from the shared table", func(t *testing.T) {
+		limiter := NewLimiter(1_000_000, 0)
+		calculator := NewPackCalculator(packSizes, WithLimits(Limits{Limiter: limiter}))
+
+		_, err := calculator.CalculateContext(context.Background(), 12001)
+		require.NoError(t, err)
+
+		held, err := limiter.acquire(context.Background(), 1_000_000)
+		require.NoError(t, err)
+		defer limiter.release(held)
+
+		_, err = calculator.CalculateContext(context.Background(), 501)
+		assert.NoError(t, err)
+	})
+
+	t.Run("should not extend the shared table while waiting to be admitted", func(t *testing.T) {
+		limiter := NewLimiter(1_000_000, time.Minute)
+		calculator := NewPackCalculator(packSizes, WithLimits(Limits{Limiter: limiter}))
+
+		held, err := limiter.acquire(context.Background(), 1_000_000)
+		require.NoError(t, err)
+
+		done := make(chan error)
+		go func() {
+			_, err := calculator.CalculateContext(context.Background(), 12001)
+			done <- err
+		}()
+
+		require.Eventually(t, func() bool { return limiter.Stats().Waiting == 1 }, time.Second, time.Millisecond)
+		require.True(t, calculator.growMu.TryLock())
+		calculator.growMu.Unlock()
+
+		limiter.release(held)
+		assert.NoError(t, <-done)
+	})
+
+	t.Run("should reject calculations allocating a table when saturated", func(t *testing.T) {
+		limiter := NewLimiter(1_000_000, 0)
+		calculator := NewPackCalculator(packSizes, WithLimits(Limits{Limiter: limiter}))
+
+		held, err := limiter.acquire(context.Background(), 1_000_000)
+		require.NoError(t, err)
+		defer limiter.release(held)
+
+		_, err = calculator.CalculateContext(context.Background(), 12001)
+		assert.ErrorIs(t, err, ErrSaturated)
+
+		results := calculator.CalculateBatch(context.Background(), []int{251, 12001}, 2)
+		for _, result := range results {
+			assert.ErrorIs(t, result.Err, ErrSaturated)
+		}
+	})
+}
@@ -0,0 +1,164 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// ErrOrderLimit is returned for orders above the calculator's maximum order.
+	ErrOrderLimit = errors.New("order exceeds the maximum order")
+
+	// ErrComputeBudget is returned when a calculation needs more iterations or
+	// time than the calculator's compute budget allows.
+	ErrComputeBudget = errors.New("calculation exceeds the compute budget")
+)
+
+// LimitError is returned when a calculation exceeds one of the calculator's
+// Limits. It wraps ErrOrderLimit or ErrComputeBudget.
+type LimitError struct {
+	// Err is ErrOrderLimit or ErrComputeBudget.
+	Err error
+
+	// Limit describes the limit that was exceeded, e.g. "1000000 items",
+	// "50000000 iterations" or "2s".
+	Limit string
+}
+
+// Error implements error.
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%v: limit is %s", e.Err, e.Limit)
+}
+
+// Unwrap returns ErrOrderLimit or ErrComputeBudget.
+func (e *LimitError) Unwrap() error {
+	return e.Err
+}
+
+// Limits bounds the work of a single calculation, so that one request cannot
+// exhaust the process. Zero fields are unlimited.
+type Limits struct {
+	// MaxOrder is the largest order accepted.
+	MaxOrder int
+
+	// MaxIterations caps the table quantities filled (per stock layer) and the
+	// combinations visited by a calculation. Tables that would exceed it are not
+	// allocated.
+	MaxIterations int
+
+	// MaxDuration caps the time a calculation may take.
+	MaxDuration time.Duration
+
+	// Limiter, when set, admits the calculations that allocate a table. It is
+	// shared by every calculator given the same limits.
+	Limiter *Limiter
+}
+
+// WithLimits bounds every calculation of the calculator by the limits.
+func WithLimits(limits Limits) CalculatorOption {
+	return func(pc *PackCalculator) {
+		pc.limits = limits
+	}
+}
+
+// GetLimits returns the limits of the calculator.
+func (pc *PackCalculator) GetLimits() Limits {
+	pc.mu.RLock()
+	defer pc.mu.RUnlock()
+
+	return pc.limits
+}
+
+// Budget returns a context carrying one compute budget of the calculator's
+// limits, and a cancel function that ends it and releases its weight in the
+// limiter. Every calculation run with the context, by any calculator, spends
+// from that budget, so the calculations made for one request are bounded
+// together instead of each on its own.
+func (pc *PackCalculator) Budget(ctx context.Context) (context.Context, context.CancelFunc) {
+	return pc.GetLimits().withBudget(ctx)
+}
+
+// bound checks the order against the limits of the calculator and returns a
+// context carrying their compute budget, to be cancelled when the calculation ends.
+func (pc *PackCalculator) bound(ctx context.Context, order int) (context.Context, context.CancelFunc, error) {
+	limits := pc.GetLimits()
+	if err := limits.checkOrder(order); err != nil {
+		return ctx, func() {}, err
+	}
+
+	ctx, cancel := limits.withBudget(ctx)
+	return ctx, cancel, nil
+}
+
+// checkOrder rejects orders above the maximum order.
+func (l Limits) checkOrder(order int) error {
+	if l.MaxOrder > 0 && order > l.MaxOrder {
+		return &LimitError{Err: ErrOrderLimit, Limit: strconv.Itoa(l.MaxOrder) + " items"}
+	}
+	return nil
+}
+
+// budgetKey is the context key of the compute budget of a calculation.
+type budgetKey struct{}
+
+// budget counts the iterations spent by a calculation, which may be spread over
+// several goroutines, and the weight it holds in the limiter.
+type budget struct {
+	maxIterations int64
+	spent         atomic.Int64
+
+	limiter  *Limiter
+	admitted atomic.Bool
+	held     atomic.Int64
+}
+
+// withBudget returns a context carrying the compute budget of the limits, and a
+// cancel function that also releases the weight held in the limiter. A context
+// that already carries a budget is returned as is, so calculations that call
+// each other share the budget of the outermost one.
+func (l Limits) withBudget(ctx context.Context) (context.Context, context.CancelFunc) {
+	if ctx.Value(budgetKey{}) != nil {
+		return ctx, func() {}
+	}
+
+	cancel := context.CancelFunc(func() {})
+	if l.MaxDuration > 0 {
+		cause := &LimitError{Err: ErrComputeBudget, Limit: l.MaxDuration.String()}
+		ctx, cancel = context.WithTimeoutCause(ctx, l.MaxDuration, cause)
+	}
+
+	b := &budget{maxIterations: int64(l.MaxIterations), limiter: l.Limiter}
+	return context.WithValue(ctx, budgetKey{}, b), func() {
+		cancel()
+		if held := b.held.Swap(0); held > 0 {
+			b.limiter.release(held)
+		}
+	}
+}
+
+// spend charges iterations to the budget of ctx and reports whether the
+// calculation may go on: a LimitError once the budget is exhausted or its time
+// is up, ctx.Err() when ctx is otherwise done, and nil otherwise. Tables are
+// charged in full before they are allocated, so a table is never allocated when
+// filling it would exceed the budget; filling it then spends no iterations.
+func spend(ctx context.Context, iterations int) error {
+	if b, ok := ctx.Value(budgetKey{}).(*budget); ok && b.maxIterations > 0 {
+		if b.spent.Add(int64(iterations)) > b.maxIterations {
+			return b.exceeded()
+		}
+	}
+
+	if ctx.Err() != nil {
+		return context.Cause(ctx)
+	}
+	return nil
+}
+
+// exceeded returns the error of an exhausted budget.
+func (b *budget) exceeded() error {
+	return &LimitError{Err: ErrComputeBudget, Limit: strconv.FormatInt(b.maxIterations, 10) + " iterations"}
+}
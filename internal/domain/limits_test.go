@@ -0,0 +1,130 @@
+package domain
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPackCalculator_Limits(t *testing.T) {
+	packSizes := []int{250, 500, 1000, 2000, 5000}
+
+	t.Run("should reject orders above the maximum order", func(t *testing.T) {
+		calculator := NewPackCalculator(packSizes, WithLimits(Limits{MaxOrder: 1_000_000}))
+
+		_, err := calculator.CalculateContext(context.Background(), 1_000_000)
+		require.NoError(t, err)
+
+		_, err = calculator.CalculateContext(context.Background(), 2_000_000_000)
+		assert.ErrorIs(t, err, ErrOrderLimit)
+
+		var limitErr *LimitError
+		require.ErrorAs(t, err, &limitErr)
+		assert.Equal(t, "1000000 items", limitErr.Limit)
+
+		_, err = calculator.CalculateWithOptions(context.Background(), 1_000_001, CalculateOptions{Stock: map[int]int{250: 1}})
+		assert.ErrorIs(t, err, ErrOrderLimit)
+
+		_, _, err = calculator.Explain(context.Background(), 1_000_001)
+		assert.ErrorIs(t, err, ErrOrderLimit)
+
+		_, err = calculator.Alternatives(context.Background(), 1_000_001, CalculateOptions{}, 1)
+		assert.ErrorIs(t, err, ErrOrderLimit)
+
+		results := calculator.CalculateBatch(context.Background(), []int{251, 1_000_001}, 1)
+		assert.NoError(t, results[0].Err)
+		assert.ErrorIs(t, results[1].Err, ErrOrderLimit)
+	})
+
+	t.Run("should not allocate tables beyond the iteration budget", func(t *testing.T) {
+		calculator := NewPackCalculator(packSizes, WithLimits(Limits{MaxIterations: 10_000}))
+
+		_, err := calculator.CalculateContext(context.Background(), 4000)
+		require.NoError(t, err)
+
+		_, err = calculator.CalculateContext(context.Background(), 40_000)
+		assert.ErrorIs(t, err, ErrComputeBudget)
+		assert.Equal(t, 9000, calculator.sharedTable(packSizes).limit())
+
+		_, err = calculator.CalculateWithOptions(context.Background(), 40_000, CalculateOptions{Stock: map[int]int{5000: 1}})
+		assert.ErrorIs(t, err, ErrComputeBudget)
+
+		var limitErr *LimitError
+		require.ErrorAs(t, err, &limitErr)
+		assert.Equal(t, "10000 iterations", limitErr.Limit)
+	})
+
+	t.Run("should only fail the batch orders whose table exceeds the iteration budget", func(t *testing.T) {
+		calculator := NewPackCalculator([]int{999_983, 1_000_003}, WithLimits(Limits{MaxIterations: 10_000_000}))
+		calculator.UpdateRules(PackRules{1_000_003: {MinOrder: 10}})
+
+		results := calculator.CalculateBatch(context.Background(), []int{30_000_000, 1_000_000, 5}, 2)
+
+		assert.ErrorIs(t, results[0].Err, ErrComputeBudget)
+		require.NoError(t, results[1].Err)
+		assert.Equal(t, map[int]int{1_000_003: 1}, results[1].Result.Packs)
+		require.NoError(t, results[2].Err)
+		assert.Equal(t, map[int]int{999_983: 1}, results[2].Result.Packs)
+	})
+
+	t.Run("should share a budget between the calculations run with it", func(t *testing.T) {
+		calculator := NewPackCalculator(packSizes, WithLimits(Limits{MaxIterations: 10_000}))
+		other := NewPackCalculator([]int{250, 500, 1000}, WithLimits(calculator.GetLimits()))
+
+		ctx, cancel := calculator.Budget(context.Background())
+		defer cancel()
+
+		_, err := calculator.CalculateContext(ctx, 4000)
+		require.NoError(t, err)
+
+		_, err = other.CalculateContext(ctx, 4000)
+		assert.ErrorIs(t, err, ErrComputeBudget)
+
+		_, err = other.CalculateContext(context.Background(), 4000)
+		assert.NoError(t, err)
+	})
+
+	t.Run("should stop calculations that exhaust the iteration budget", func(t *testing.T) {
+		calculator := NewPackCalculator([]int{23, 31, 53}, WithLimits(Limits{MaxIterations: 5000}))
+
+		_, err := calculator.Alternatives(context.Background(), 5000, CalculateOptions{}, 10)
+		assert.ErrorIs(t, err, ErrComputeBudget)
+	})
+
+	t.Run("should stop calculations that run out of time", func(t *testing.T) {
+		calculator := NewPackCalculator(packSizes, WithLimits(Limits{MaxDuration: time.Nanosecond}))
+
+		_, err := calculator.CalculateWithOptions(context.Background(), 1_000_000, CalculateOptions{Stock: map[int]int{250: 4000}})
+		assert.ErrorIs(t, err, ErrComputeBudget)
+		assert.NotErrorIs(t, err, context.DeadlineExceeded)
+
+		var limitErr *LimitError
+		require.ErrorAs(t, err, &limitErr)
+		assert.Equal(t, "1ns", limitErr.Limit)
+	})
+
+	t.Run("should keep reporting cancellation by the caller", func(t *testing.T) {
+		calculator := NewPackCalculator(packSizes, WithLimits(Limits{MaxDuration: time.Hour}))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := calculator.CalculateContext(ctx, 12001)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("should apply the limits to product calculators", func(t *testing.T) {
+		catalog := NewCatalog(WithLimits(Limits{MaxOrder: 100}))
+		_, err := catalog.Add("WIDGET", "Widget", []int{23, 31, 53})
+		require.NoError(t, err)
+
+		calculator, err := catalog.Calculator("WIDGET")
+		require.NoError(t, err)
+
+		_, err = calculator.CalculateContext(context.Background(), 101)
+		assert.ErrorIs(t, err, ErrOrderLimit)
+	})
+}
@@ -3,10 +3,23 @@
 package domain
 
 import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
 	"sort"
 	"sync"
 )
 
+const (
+	// maxTableSize caps the number of quantities held by a dynamic programming table
+	// (two int32 each), which bounds the memory used by a single calculation.
+	maxTableSize = 1 << 25
+
+	// cancellationCheckInterval is the number of quantities filled between context checks.
+	cancellationCheckInterval = 4096
+)
+
 // PackResult represents the calculation result containing the order details,
 // total items to be shipped, and the distribution of packs.
 type PackResult struct {
@@ -14,31 +27,49 @@ type PackResult struct {
 	TotalItems int         `json:"total_items"`
 	Packs      map[int]int `json:"packs"`
 	PackSizes  []int       `json:"pack_sizes_used"`
+	TotalCost  int         `json:"total_cost"`
+	Parcels    []Parcel    `json:"parcels,omitempty"`
 }
 
 // PackCalculator is responsible for calculating the optimal pack combination
 // to fulfill customer orders while minimizing items and packs sent.
 type PackCalculator struct {
-	mu        sync.RWMutex
-	packSizes []int
+	mu         sync.RWMutex
+	packSizes  []int
+	costs      Costs
+	parcelSpec ParcelSpec
+	rules      PackRules
+	cache      *resultCache
+	answers    *AnswerTable
+	limits     Limits
+
+	// table is the shared table of the current pack sizes, replaced by a larger
+	// one as orders need it; growMu lets a single calculation grow it at a time.
+	table  *packTable
+	growMu sync.Mutex
 }
 
 // NewPackCalculator creates a new calculator instance with the given pack sizes.
 // The pack sizes are automatically sorted in ascending order for optimization.
-func NewPackCalculator(sizes []int) *PackCalculator {
+func NewPackCalculator(sizes []int, opts ...CalculatorOption) *PackCalculator {
 	sortedSizes := make([]int, len(sizes))
 	copy(sortedSizes, sizes)
 	sort.Ints(sortedSizes)
 
-	return &PackCalculator{
+	pc := &PackCalculator{
 		packSizes: sortedSizes,
 	}
+
+	for _, opt := range opts {
+		opt(pc)
+	}
+
+	return pc
 }
 
 // solution represents a possible pack combination during the calculation process.
 type solution struct {
 	totalItems     int
-	packsBySize    map[int]int
 	totalPackCount int
 }
 
@@ -56,85 +87,216 @@ type solution struct {
 //	order = 251  -> TotalItems: 500,   Packs: {500: 1}
 //	order = 501  -> TotalItems: 750,   Packs: {500: 1, 250: 1}
 //	order = 12001-> TotalItems: 12250, Packs: {5000: 2, 2000: 1, 250: 1}
+//
+// Calculate cannot be cancelled and reports failures as an empty result;
+// use CalculateContext to get the reason.
 func (pc *PackCalculator) Calculate(order int) PackResult {
-	packSizes := pc.GetPackSizes()
-
-	if order <= 0 {
+	result, err := pc.CalculateContext(context.Background(), order)
+	if err != nil {
 		return PackResult{
 			Order:      order,
 			TotalItems: 0,
 			Packs:      make(map[int]int),
-			PackSizes:  packSizes,
+			PackSizes:  pc.GetPackSizes(),
 		}
 	}
+	return result
+}
+
+// CalculateContext computes the optimal pack combination like Calculate, but stops
+// as soon as ctx is done and reports why no combination could be returned.
+//
+// An order of zero yields an empty result without error. Failures are reported with
+// ErrInvalidOrder, ErrNoPackSizes, ErrOrderTooLarge, ErrInfeasible or ctx.Err(),
+// and calculations beyond the calculator's Limits with a LimitError. Orders
+// restricted by the pack size rules are solved like limited stock; see
+// CalculateWithOptions. When the parcels are limited, the packs are grouped into
+// parcels and packs that fit none fail with ErrParcelLimit; see ParcelSpec.Group.
+func (pc *PackCalculator) CalculateContext(ctx context.Context, order int) (PackResult, error) {
+	ctx, cancel, err := pc.bound(ctx, order)
+	defer cancel()
+	if err != nil {
+		return PackResult{}, err
+	}
+
+	var result PackResult
+	if pc.GetRules().Restricts(order) {
+		result, err = pc.calculateWithOptions(ctx, order, CalculateOptions{})
+	} else {
+		result, err = pc.calculateTable(ctx, order)
+	}
+	if err != nil {
+		return PackResult{}, err
+	}
+
+	return pc.groupParcels(result)
+}
+
+// calculateTable computes the optimal pack combination from the dynamic
+// programming table, ignoring the pack size rules. Orders covered by the loaded
+// answer table are looked up in it; others are looked up in and added to the
+// result cache, when the calculator has one.
+func (pc *PackCalculator) calculateTable(ctx context.Context, order int) (PackResult, error) {
+	packSizes, costs := pc.configuration()
+
+	if result, ok := pc.lookupAnswer(packSizes, costs, order); ok {
+		return result, nil
+	}
+
+	if pc.cache != nil {
+		if result, ok := pc.cache.get(packSizes, order); ok {
+			result.TotalCost = costs.Total(result.Packs)
+			return result, nil
+		}
+	}
+
+	plan, err := planOrder(order, packSizes)
+	if err != nil {
+		return PackResult{}, err
+	}
+
+	table, err := pc.tableFor(ctx, packSizes, plan.searchLimit(packSizes))
+	if err != nil {
+		return PackResult{}, err
+	}
+
+	result, err := pc.resolveOrderPlan(table, plan, costs)
+	if err != nil {
+		return PackResult{}, err
+	}
+
+	if pc.cache != nil {
+		pc.cache.put(packSizes, result)
+	}
+
+	return result, nil
+}
+
+// orderPlan describes how an order is solved: the residual order searched in the
+// dynamic programming table and the number of largest packs added on top of it.
+type orderPlan struct {
+	order         int
+	residualOrder int
+	largestPacks  int
+}
+
+// planOrder validates the order against the pack sizes and reduces it by the period
+// threshold, so large orders only need a table covering the residual order.
+func planOrder(order int, packSizes []int) (orderPlan, error) {
+	if order < 0 {
+		return orderPlan{}, ErrInvalidOrder
+	}
+
+	if order == 0 {
+		return orderPlan{}, nil
+	}
 
 	if len(packSizes) == 0 {
+		return orderPlan{}, ErrNoPackSizes
+	}
+
+	residualOrder, largestPacks := reduceOrder(order, packSizes)
+
+	largestPack := packSizes[len(packSizes)-1]
+	if residualOrder > maxTableSize-largestPack {
+		return orderPlan{}, fmt.Errorf("%w: search range exceeds %d quantities", ErrOrderTooLarge, maxTableSize)
+	}
+
+	return orderPlan{
+		order:         order,
+		residualOrder: residualOrder,
+		largestPacks:  largestPacks,
+	}, nil
+}
+
+// searchLimit returns the largest quantity the table must cover to solve the plan.
+func (p orderPlan) searchLimit(packSizes []int) int {
+	if p.order == 0 {
+		return 0
+	}
+	return p.residualOrder + packSizes[len(packSizes)-1]
+}
+
+// resolveOrderPlan reads the solution of a plan from a table that covers at least
+// the plan's search limit and prices it with the costs.
+func (pc *PackCalculator) resolveOrderPlan(table *packTable, plan orderPlan, costs Costs) (PackResult, error) {
+	packSizes := table.packSizes
+
+	if plan.order == 0 {
 		return PackResult{
-			Order:      order,
+			Order:      plan.order,
 			TotalItems: 0,
 			Packs:      make(map[int]int),
 			PackSizes:  packSizes,
-		}
+		}, nil
 	}
 
-	largestPack := packSizes[len(packSizes)-1]
-	searchLimit := order + largestPack
+	result, err := pc.findBestSolutionForOrder(table, plan.residualOrder, plan.searchLimit(packSizes), packSizes)
+	if err != nil {
+		return PackResult{}, err
+	}
 
-	optimalSolutions := make(map[int]*solution)
-	optimalSolutions[0] = &solution{
-		totalItems:     0,
-		packsBySize:    make(map[int]int),
-		totalPackCount: 0,
+	if plan.largestPacks > 0 {
+		largestPack := packSizes[len(packSizes)-1]
+		result.Order = plan.order
+		result.TotalItems += plan.largestPacks * largestPack
+		result.Packs[largestPack] += plan.largestPacks
 	}
 
-	pc.buildOptimalSolutions(optimalSolutions, searchLimit, packSizes)
+	result.TotalCost = costs.Total(result.Packs)
 
-	return pc.findBestSolutionForOrder(optimalSolutions, order, searchLimit, packSizes)
+	return result, nil
 }
 
-// buildOptimalSolutions fills the dynamic programming table with optimal solutions.
-func (pc *PackCalculator) buildOptimalSolutions(optimalSolutions map[int]*solution, limit int, packSizes []int) {
-	for currentQuantity := 1; currentQuantity <= limit; currentQuantity++ {
-		for _, packSize := range packSizes {
-			if currentQuantity >= packSize {
-				previousSolution := optimalSolutions[currentQuantity-packSize]
-				if previousSolution == nil {
-					continue
-				}
+// buildOptimalSolutions fills the dynamic programming table with optimal solutions
+// for the quantities from the given one up to its limit; smaller quantities must
+// already be solved.
+//
+// Pack sizes are tried from largest to smallest and only strictly better solutions
+// replace the current one, so ties are resolved in favor of larger packs. This
+// keeps the table consistent with the largest-pack fill used for large orders.
+//
+// The context is checked every cancellationCheckInterval quantities, so an abandoned
+// calculation stops consuming CPU shortly after ctx is done. The quantities are
+// charged to the compute budget before the table is allocated; see spend.
+func (pc *PackCalculator) buildOptimalSolutions(ctx context.Context, table *packTable, from int) error {
+	for currentQuantity := max(from, 1); currentQuantity <= table.limit(); currentQuantity++ {
+		if currentQuantity%cancellationCheckInterval == 1 {
+			if err := spend(ctx, 0); err != nil {
+				return err
+			}
+		}
 
-				newSolution := pc.createSolutionWithPack(previousSolution, packSize)
-				currentBestSolution := optimalSolutions[currentQuantity]
+		for packIndex := len(table.packSizes) - 1; packIndex >= 0; packIndex-- {
+			packSize := table.packSizes[packIndex]
+			if currentQuantity < packSize {
+				continue
+			}
 
-				if pc.isBetterSolution(newSolution, currentBestSolution) {
-					optimalSolutions[currentQuantity] = newSolution
-				}
+			previousQuantity := currentQuantity - packSize
+			if !table.reachable(previousQuantity) {
+				continue
 			}
-		}
-	}
-}
 
-// createSolutionWithPack creates a new solution by adding one pack to an existing solution.
-func (pc *PackCalculator) createSolutionWithPack(baseSolution *solution, packSize int) *solution {
-	newPacks := make(map[int]int)
-	for size, quantity := range baseSolution.packsBySize {
-		newPacks[size] = quantity
-	}
-	newPacks[packSize]++
+			newSolution := solution{
+				totalItems:     currentQuantity,
+				totalPackCount: int(table.packCounts[previousQuantity]) + 1,
+			}
 
-	return &solution{
-		totalItems:     baseSolution.totalItems + packSize,
-		packsBySize:    newPacks,
-		totalPackCount: baseSolution.totalPackCount + 1,
+			if !table.reachable(currentQuantity) ||
+				pc.isBetterSolution(newSolution, table.solutionAt(currentQuantity)) {
+				table.packCounts[currentQuantity] = int32(newSolution.totalPackCount)
+				table.lastPacks[currentQuantity] = int32(packIndex)
+			}
+		}
 	}
+
+	return nil
 }
 
 // isBetterSolution determines if the new solution is better than the current one.
 // Priority: fewer items first, then fewer packs.
-func (pc *PackCalculator) isBetterSolution(newSolution, currentSolution *solution) bool {
-	if currentSolution == nil {
-		return true
-	}
-
+func (pc *PackCalculator) isBetterSolution(newSolution, currentSolution solution) bool {
 	if newSolution.totalItems < currentSolution.totalItems {
 		return true
 	}
@@ -149,38 +311,69 @@ func (pc *PackCalculator) isBetterSolution(newSolution, currentSolution *solutio
 
 // findBestSolutionForOrder searches for the first valid solution that meets or exceeds the order.
 func (pc *PackCalculator) findBestSolutionForOrder(
-	optimalSolutions map[int]*solution,
+	table *packTable,
 	order int,
 	searchLimit int,
 	packSizes []int,
-) PackResult {
+) (PackResult, error) {
 	for quantity := order; quantity <= searchLimit; quantity++ {
-		if solution := optimalSolutions[quantity]; solution != nil {
+		if table.reachable(quantity) {
 			return PackResult{
 				Order:      order,
-				TotalItems: solution.totalItems,
-				Packs:      solution.packsBySize,
+				TotalItems: quantity,
+				Packs:      table.packs(quantity),
 				PackSizes:  packSizes,
-			}
+			}, nil
 		}
 	}
 
-	return PackResult{
-		Order:      order,
-		TotalItems: 0,
-		Packs:      make(map[int]int),
-		PackSizes:  packSizes,
-	}
+	return PackResult{}, ErrInfeasible
+}
+
+// Configuration holds everything a calculation is configured with: the pack
+// sizes, their costs, the parcel limits and the pack size rules.
+type Configuration struct {
+	PackSizes  []int
+	Costs      Costs
+	ParcelSpec ParcelSpec
+	Rules      PackRules
+}
+
+// UpdateConfiguration replaces the pack sizes, costs, parcel limits and rules at
+// once, so that no calculation sees part of a configuration. Each part is updated
+// like its own Update method.
+func (pc *PackCalculator) UpdateConfiguration(config Configuration) {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+
+	pc.setPackSizes(config.PackSizes)
+	pc.costs = config.Costs.clone()
+	pc.parcelSpec = config.ParcelSpec.clone()
+	pc.rules = maps.Clone(config.Rules)
 }
 
-// UpdatePackSizes updates the available pack sizes and re-sorts them.
+// UpdatePackSizes updates the available pack sizes and re-sorts them. Cached
+// results, the shared table and the answer table are dropped when the sizes change.
 func (pc *PackCalculator) UpdatePackSizes(sizes []int) {
 	pc.mu.Lock()
 	defer pc.mu.Unlock()
 
+	pc.setPackSizes(sizes)
+}
+
+// setPackSizes updates the pack sizes like UpdatePackSizes; pc.mu must be held.
+func (pc *PackCalculator) setPackSizes(sizes []int) {
 	sortedSizes := make([]int, len(sizes))
 	copy(sortedSizes, sizes)
 	sort.Ints(sortedSizes)
+
+	if !slices.Equal(sortedSizes, pc.packSizes) {
+		if pc.cache != nil {
+			pc.cache.clear()
+		}
+		pc.table = nil
+		pc.answers = nil
+	}
 	pc.packSizes = sortedSizes
 }
 
@@ -205,5 +398,11 @@ func (pr *PackResult) GetTotalPackCount() int {
 
 // GetSurplus returns the number of extra items being sent beyond the order.
 func (pr *PackResult) GetSurplus() int {
-	return pr.TotalItems - pr.Order
+	return max(pr.TotalItems-pr.Order, 0)
+}
+
+// GetShortfall returns the number of ordered items that are not being sent,
+// which is only non-zero for results of ModeUnderFulfil.
+func (pr *PackResult) GetShortfall() int {
+	return max(pr.Order-pr.TotalItems, 0)
 }
@@ -1,7 +1,11 @@
 package domain
 
 import (
+	"context"
+	"math"
+	"runtime"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -204,6 +208,70 @@ func TestPackCalculator_UpdatePackSizes(t *testing.T) {
 	}
 }
 
+func TestPackCalculator_UpdateConfiguration(t *testing.T) {
+	first := Configuration{
+		PackSizes:  []int{500, 250},
+		Costs:      Costs{PerPack: map[int]int{250: 1}, PerShipment: 10},
+		ParcelSpec: ParcelSpec{Weights: map[int]int{250: 3000}, MaxWeight: 31500},
+		Rules:      PackRules{250: {MaxPacks: 2}},
+	}
+	second := Configuration{
+		PackSizes:  []int{300},
+		Costs:      Costs{PerPack: map[int]int{300: 2}},
+		ParcelSpec: ParcelSpec{Volumes: map[int]int{300: 6000}, MaxVolume: 60000},
+		Rules:      PackRules{300: {MinOrder: 1}},
+	}
+
+	t.Run("should replace every part of the configuration", func(t *testing.T) {
+		calculator := NewPackCalculator([]int{1000}, WithResultCache(10))
+		_, err := calculator.CalculateContext(context.Background(), 1)
+		require.NoError(t, err)
+
+		calculator.UpdateConfiguration(first)
+
+		assert.Equal(t, []int{250, 500}, calculator.GetPackSizes())
+		assert.Equal(t, first.Costs, calculator.GetCosts())
+		assert.Equal(t, first.ParcelSpec, calculator.GetParcelSpec())
+		assert.Equal(t, first.Rules, calculator.GetRules())
+		assert.Nil(t, calculator.sharedTable(calculator.GetPackSizes()))
+		assert.Zero(t, calculator.CacheStats().Size)
+	})
+
+	t.Run("should never expose part of a configuration", func(t *testing.T) {
+		calculator := NewPackCalculator(nil)
+		calculator.UpdateConfiguration(first)
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for i := 0; i < 1000; i++ {
+				calculator.UpdateConfiguration(second)
+				calculator.UpdateConfiguration(first)
+			}
+		}()
+
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+
+			calculator.mu.RLock()
+			packSizes, costs, rules := calculator.packSizes, calculator.costs, calculator.rules
+			calculator.mu.RUnlock()
+
+			if len(packSizes) == 1 {
+				assert.Equal(t, second.Costs, costs)
+				assert.Equal(t, second.Rules, rules)
+			} else {
+				assert.Equal(t, first.Costs, costs)
+				assert.Equal(t, first.Rules, rules)
+			}
+		}
+	})
+}
+
 func TestPackCalculator_GetPackSizes(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -449,6 +517,60 @@ func BenchmarkCalculate_LargeOrder(b *testing.B) {
 	}
 }
 
+func BenchmarkCalculate_HugeOrder(b *testing.B) {
+	calculator := NewPackCalculator([]int{250, 500, 1000, 2000, 5000})
+	order := 2_000_001
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	bytes := allocatedBytes(func() {
+		for i := 0; i < b.N; i++ {
+			calculator.Calculate(order)
+		}
+	})
+
+	b.ReportMetric(float64(bytes)/float64(b.N)/float64(order), "B/item")
+}
+
+func TestPackCalculator_Calculate_MemoryIsLinearInOrder(t *testing.T) {
+	// Coprime sizes push the period threshold above the orders used here,
+	// so the whole order is solved by the table.
+	packSizes := []int{997, 1009, 4999}
+	require.Greater(t, periodThreshold(packSizes), 1_000_001)
+
+	// Every measurement gets a new calculator, so that the table is built for
+	// the order instead of being reused from the shared table.
+	calculate := func(order int) func() {
+		return func() { NewPackCalculator(packSizes).Calculate(order) }
+	}
+
+	t.Run("allocation count does not grow with the order", func(t *testing.T) {
+		smallOrderAllocs := testing.AllocsPerRun(5, calculate(10_001))
+		largeOrderAllocs := testing.AllocsPerRun(5, calculate(1_000_001))
+
+		assert.Equal(t, smallOrderAllocs, largeOrderAllocs)
+	})
+
+	t.Run("allocated bytes stay within two int32 per searched quantity", func(t *testing.T) {
+		order := 1_000_001
+		searchedQuantities := order + packSizes[len(packSizes)-1] + 1
+
+		bytes := allocatedBytes(calculate(order))
+
+		assert.GreaterOrEqual(t, float64(bytes)/float64(searchedQuantities), 8.0)
+		assert.Less(t, float64(bytes)/float64(searchedQuantities), 9.0)
+	})
+}
+
+// allocatedBytes returns the number of heap bytes allocated while running fn.
+func allocatedBytes(fn func()) uint64 {
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	fn()
+	runtime.ReadMemStats(&after)
+	return after.TotalAlloc - before.TotalAlloc
+}
+
 func TestPackCalculator_Calculate_WithEmptyPackSizes(t *testing.T) {
 	tests := []struct {
 		name               string
@@ -627,3 +749,88 @@ func TestPackCalculator_Calculate_EdgeCasesWithLargePacks(t *testing.T) {
 		})
 	}
 }
+
+func TestPackCalculator_CalculateContext(t *testing.T) {
+	t.Run("should return the optimal result", func(t *testing.T) {
+		calculator := NewPackCalculator([]int{250, 500, 1000, 2000, 5000})
+
+		result, err := calculator.CalculateContext(context.Background(), 12001)
+
+		require.NoError(t, err)
+		assert.Equal(t, 12250, result.TotalItems)
+		assert.Equal(t, map[int]int{5000: 2, 2000: 1, 250: 1}, result.Packs)
+	})
+
+	t.Run("should return an empty result for order zero", func(t *testing.T) {
+		calculator := NewPackCalculator([]int{})
+
+		result, err := calculator.CalculateContext(context.Background(), 0)
+
+		require.NoError(t, err)
+		assert.Equal(t, 0, result.TotalItems)
+		assert.Equal(t, map[int]int{}, result.Packs)
+	})
+
+	tests := []struct {
+		name          string
+		packSizes     []int
+		order         int
+		expectedError error
+	}{
+		{
+			name:          "should reject negative order",
+			packSizes:     []int{250, 500},
+			order:         -1,
+			expectedError: ErrInvalidOrder,
+		},
+		{
+			name:          "should report missing pack sizes",
+			packSizes:     []int{},
+			order:         100,
+			expectedError: ErrNoPackSizes,
+		},
+		{
+			name:          "should reject order whose table exceeds the size limit",
+			packSizes:     []int{999_983, 1_000_003},
+			order:         100_000_000,
+			expectedError: ErrOrderTooLarge,
+		},
+		{
+			name:          "should reject order that would overflow the search range",
+			packSizes:     []int{999_983, 1_000_003},
+			order:         math.MaxInt,
+			expectedError: ErrOrderTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calculator := NewPackCalculator(tt.packSizes)
+
+			result, err := calculator.CalculateContext(context.Background(), tt.order)
+
+			require.ErrorIs(t, err, tt.expectedError)
+			assert.Equal(t, PackResult{}, result)
+		})
+	}
+
+	t.Run("should stop when the context is cancelled", func(t *testing.T) {
+		calculator := NewPackCalculator([]int{997, 1009, 4999})
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := calculator.CalculateContext(ctx, 1_000_001)
+
+		require.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("should stop when the deadline is exceeded", func(t *testing.T) {
+		calculator := NewPackCalculator([]int{997, 1009, 4999})
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		defer cancel()
+
+		_, err := calculator.CalculateContext(ctx, 5_000_001)
+
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
@@ -0,0 +1,182 @@
+package domain
+
+import (
+	"context"
+	"slices"
+)
+
+// unreachable marks quantities that cannot be composed from the available pack sizes.
+const unreachable int32 = -1
+
+// maxSharedTableSize caps the quantities of the table kept between calculations
+// (two int32 each). Calculations that need a larger table build their own.
+const maxSharedTableSize = 1 << 22
+
+// packTable is the dynamic programming table used to find optimal pack combinations.
+//
+// Every quantity from 0 to limit is a slot in flat slices: the quantity itself is
+// the number of items shipped, packCounts holds the minimum number of packs that sum
+// exactly to it and lastPacks is a back-pointer to the pack size added last. This
+// keeps memory at two integers per quantity; the pack distribution is only rebuilt
+// for the quantity that is finally chosen.
+type packTable struct {
+	packSizes  []int
+	packCounts []int32
+	lastPacks  []int32
+}
+
+// newPackTable allocates a table covering quantities 0..limit for the given sorted pack sizes.
+func newPackTable(packSizes []int, limit int) *packTable {
+	table := &packTable{
+		packSizes:  packSizes,
+		packCounts: make([]int32, limit+1),
+		lastPacks:  make([]int32, limit+1),
+	}
+
+	for quantity := 1; quantity <= limit; quantity++ {
+		table.packCounts[quantity] = unreachable
+		table.lastPacks[quantity] = unreachable
+	}
+
+	return table
+}
+
+// extend returns a table covering quantities 0..limit that starts with the solved
+// quantities of t, leaving the new ones unreachable until they are solved. The new
+// quantities are stored past the end of t, so t itself is never modified and can
+// keep being read while the extension is solved.
+func (t *packTable) extend(limit int) *packTable {
+	from := len(t.packCounts)
+	extended := &packTable{
+		packSizes:  t.packSizes,
+		packCounts: slices.Grow(t.packCounts, limit+1-from)[:limit+1],
+		lastPacks:  slices.Grow(t.lastPacks, limit+1-from)[:limit+1],
+	}
+
+	for quantity := from; quantity <= limit; quantity++ {
+		extended.packCounts[quantity] = unreachable
+		extended.lastPacks[quantity] = unreachable
+	}
+
+	return extended
+}
+
+// limit returns the largest quantity covered by the table.
+func (t *packTable) limit() int {
+	return len(t.packCounts) - 1
+}
+
+// reachable reports whether the quantity can be composed exactly from whole packs.
+func (t *packTable) reachable(quantity int) bool {
+	return quantity >= 0 && quantity <= t.limit() && t.packCounts[quantity] != unreachable
+}
+
+// solutionAt returns the optimal solution stored for the quantity.
+func (t *packTable) solutionAt(quantity int) solution {
+	return solution{
+		totalItems:     quantity,
+		totalPackCount: int(t.packCounts[quantity]),
+	}
+}
+
+// packs reconstructs the pack distribution for a reachable quantity by following
+// the back-pointers down to zero.
+func (t *packTable) packs(quantity int) map[int]int {
+	packsBySize := make(map[int]int)
+	for quantity > 0 {
+		packSize := t.packSizes[t.lastPacks[quantity]]
+		packsBySize[packSize]++
+		quantity -= packSize
+	}
+	return packsBySize
+}
+
+// tableFor returns a solved table covering at least quantities 0..limit for the
+// pack sizes.
+//
+// The calculator keeps one shared table for its current pack sizes. Tables are
+// never modified once solved, so a table covering the limit is returned to any
+// number of concurrent readers under the read lock. A larger limit extends the
+// shared table, solving only the new quantities, and replaces it under the write
+// lock; extensions are serialized by growMu so that only one is written past the
+// end of the shared slices at a time, and are admitted by the limiter before
+// taking it. Limits above maxSharedTableSize, and pack
+// sizes that were replaced meanwhile, get a table of their own.
+func (pc *PackCalculator) tableFor(ctx context.Context, packSizes []int, limit int) (*packTable, error) {
+	if limit > maxSharedTableSize {
+		if err := spend(ctx, limit); err != nil {
+			return nil, err
+		}
+		if err := admit(ctx, limit); err != nil {
+			return nil, err
+		}
+
+		table := newPackTable(packSizes, limit)
+		if err := pc.buildOptimalSolutions(ctx, table, 1); err != nil {
+			return nil, err
+		}
+		return table, nil
+	}
+
+	covered := 0
+	if table := pc.sharedTable(packSizes); table != nil {
+		if table.limit() >= limit {
+			return table, nil
+		}
+		covered = table.limit()
+	}
+
+	// Admission may wait for other calculations, which must not wait for growMu
+	// meanwhile, so it is taken first with the extension needed so far.
+	if err := admit(ctx, limit-covered); err != nil {
+		return nil, err
+	}
+
+	pc.growMu.Lock()
+	defer pc.growMu.Unlock()
+
+	// Another calculation may have extended the table while this one waited.
+	current := pc.sharedTable(packSizes)
+	if current != nil && current.limit() >= limit {
+		return current, nil
+	}
+
+	from := 1
+	if current != nil {
+		from = current.limit() + 1
+	}
+
+	if err := spend(ctx, limit-from+1); err != nil {
+		return nil, err
+	}
+
+	// The first table is allocated at its size rather than extended from nothing.
+	var table *packTable
+	if current == nil {
+		table = newPackTable(packSizes, limit)
+	} else {
+		table = current.extend(limit)
+	}
+	if err := pc.buildOptimalSolutions(ctx, table, from); err != nil {
+		return nil, err
+	}
+
+	pc.mu.Lock()
+	if slices.Equal(pc.packSizes, packSizes) {
+		pc.table = table
+	}
+	pc.mu.Unlock()
+
+	return table, nil
+}
+
+// sharedTable returns the shared table when it was built for the pack sizes.
+func (pc *PackCalculator) sharedTable(packSizes []int) *packTable {
+	pc.mu.RLock()
+	defer pc.mu.RUnlock()
+
+	if pc.table == nil || !slices.Equal(pc.table.packSizes, packSizes) {
+		return nil
+	}
+	return pc.table
+}
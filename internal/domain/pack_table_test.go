@@ -0,0 +1,98 @@
+package domain
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPackCalculator_SharedTable(t *testing.T) {
+	t.Run("should extend the shared table for larger orders only", func(t *testing.T) {
+		calculator := NewPackCalculator([]int{250, 500, 1000, 2000, 5000})
+
+		_, err := calculator.CalculateContext(context.Background(), 12001)
+		require.NoError(t, err)
+		table := calculator.sharedTable(calculator.GetPackSizes())
+		require.NotNil(t, table)
+		assert.Equal(t, 17001, table.limit())
+
+		_, err = calculator.CalculateContext(context.Background(), 251)
+		require.NoError(t, err)
+		assert.Same(t, table, calculator.sharedTable(calculator.GetPackSizes()))
+
+		_, err = calculator.CalculateContext(context.Background(), 20001)
+		require.NoError(t, err)
+		assert.Equal(t, 25001, calculator.sharedTable(calculator.GetPackSizes()).limit())
+	})
+
+	t.Run("should leave the previous table unchanged when extending it", func(t *testing.T) {
+		calculator := NewPackCalculator([]int{23, 31, 53})
+
+		small, err := calculator.tableFor(context.Background(), calculator.GetPackSizes(), 100)
+		require.NoError(t, err)
+		counts := append([]int32(nil), small.packCounts...)
+
+		large, err := calculator.tableFor(context.Background(), calculator.GetPackSizes(), 1000)
+		require.NoError(t, err)
+
+		assert.Equal(t, counts, small.packCounts)
+		assert.Equal(t, counts, large.packCounts[:101])
+	})
+
+	t.Run("should rebuild the table when the pack sizes change", func(t *testing.T) {
+		calculator := NewPackCalculator([]int{250, 500})
+
+		_, err := calculator.CalculateContext(context.Background(), 251)
+		require.NoError(t, err)
+
+		calculator.UpdatePackSizes([]int{300})
+		assert.Nil(t, calculator.sharedTable([]int{250, 500}))
+
+		result, err := calculator.CalculateContext(context.Background(), 251)
+		require.NoError(t, err)
+		assert.Equal(t, map[int]int{300: 1}, result.Packs)
+	})
+
+	t.Run("should not share tables cancelled while extending", func(t *testing.T) {
+		calculator := NewPackCalculator([]int{250, 500})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := calculator.CalculateContext(ctx, 100_000)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Nil(t, calculator.sharedTable(calculator.GetPackSizes()))
+	})
+
+	t.Run("should match fresh calculators under concurrent requests", func(t *testing.T) {
+		packSizes := []int{23, 31, 53}
+		shared := NewPackCalculator(packSizes)
+		random := rand.New(rand.NewSource(1))
+
+		orders := make([]int, 200)
+		for i := range orders {
+			orders[i] = random.Intn(5000)
+		}
+
+		results := make([]PackResult, len(orders))
+		var wg sync.WaitGroup
+		for i, order := range orders {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				results[i], _ = shared.CalculateContext(context.Background(), order)
+			}()
+		}
+		wg.Wait()
+
+		for i, order := range orders {
+			expected, err := NewPackCalculator(packSizes).CalculateContext(context.Background(), order)
+			require.NoError(t, err)
+			assert.Equal(t, expected, results[i], "order %d", order)
+		}
+	})
+}
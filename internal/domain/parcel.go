@@ -0,0 +1,201 @@
+package domain
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// ParcelSpec holds the physical metadata of the pack sizes and the limits of a
+// shipping parcel. Weights are in grams and volumes in cubic centimetres; pack
+// sizes missing from Weights or Volumes weigh or take up nothing, and a zero
+// limit is unlimited.
+type ParcelSpec struct {
+	// Weights maps a pack size to the weight of one pack of that size.
+	Weights map[int]int
+
+	// Volumes maps a pack size to the volume of one pack of that size.
+	Volumes map[int]int
+
+	// MaxWeight is the heaviest parcel the carrier accepts.
+	MaxWeight int
+
+	// MaxVolume is the largest parcel volume the carrier accepts.
+	MaxVolume int
+}
+
+// Parcel is a group of identical shipping parcels holding the same packs.
+type Parcel struct {
+	Count  int         `json:"count"`
+	Packs  map[int]int `json:"packs"`
+	Weight int         `json:"weight"`
+	Volume int         `json:"volume"`
+}
+
+// limited reports whether parcels have a weight or volume limit, which is when
+// results are grouped into parcels.
+func (s ParcelSpec) limited() bool {
+	return s.MaxWeight > 0 || s.MaxVolume > 0
+}
+
+// clone returns a copy of the spec that shares no memory with it.
+func (s ParcelSpec) clone() ParcelSpec {
+	return ParcelSpec{
+		Weights:   maps.Clone(s.Weights),
+		Volumes:   maps.Clone(s.Volumes),
+		MaxWeight: s.MaxWeight,
+		MaxVolume: s.MaxVolume,
+	}
+}
+
+// Group packs the packs into parcels within the limits using first-fit
+// decreasing: packs are placed heaviest first, then largest volume first, into
+// the first parcel with room for them, and a new parcel is opened when none
+// has. Identical parcels opened one after the other are reported once with
+// their count. A pack that does not fit in an empty parcel fails with
+// ErrParcelLimit.
+func (s ParcelSpec) Group(packs map[int]int) ([]Parcel, error) {
+	sizes := slices.Collect(maps.Keys(packs))
+	slices.SortFunc(sizes, func(a, b int) int {
+		if s.Weights[a] != s.Weights[b] {
+			return s.Weights[b] - s.Weights[a]
+		}
+		if s.Volumes[a] != s.Volumes[b] {
+			return s.Volumes[b] - s.Volumes[a]
+		}
+		return b - a
+	})
+
+	var parcels []Parcel
+
+	for _, size := range sizes {
+		remaining := packs[size]
+		if remaining <= 0 {
+			continue
+		}
+
+		// Fill the open parcels first, splitting a group when only some of its
+		// parcels receive packs.
+		for i := 0; i < len(parcels) && remaining > 0; i++ {
+			fit := s.fit(parcels[i], size, remaining)
+			if fit == 0 {
+				continue
+			}
+
+			group := parcels[i]
+			if remaining >= fit*group.Count {
+				parcels[i] = s.add(group, size, fit)
+				remaining -= fit * group.Count
+				continue
+			}
+
+			full, rest := remaining/fit, remaining%fit
+			split := make([]Parcel, 0, 3)
+			if full > 0 {
+				split = append(split, s.add(withCount(group, full), size, fit))
+			}
+			if rest > 0 {
+				split = append(split, s.add(withCount(group, 1), size, rest))
+			}
+			if untouched := group.Count - full - min(rest, 1); untouched > 0 {
+				split = append(split, withCount(group, untouched))
+			}
+
+			parcels = slices.Replace(parcels, i, i+1, split...)
+			remaining = 0
+		}
+
+		if remaining == 0 {
+			continue
+		}
+
+		fit := s.fit(Parcel{}, size, remaining)
+		if fit == 0 {
+			return nil, fmt.Errorf("%w: pack size %d", ErrParcelLimit, size)
+		}
+
+		if full := remaining / fit; full > 0 {
+			parcels = append(parcels, s.add(Parcel{Count: full}, size, fit))
+		}
+		if rest := remaining % fit; rest > 0 {
+			parcels = append(parcels, s.add(Parcel{Count: 1}, size, rest))
+		}
+	}
+
+	return parcels, nil
+}
+
+// fit returns how many packs of the size, up to want, fit in one parcel of the group.
+func (s ParcelSpec) fit(parcel Parcel, size, want int) int {
+	fit := want
+	if weight := s.Weights[size]; s.MaxWeight > 0 && weight > 0 {
+		fit = min(fit, max(s.MaxWeight-parcel.Weight, 0)/weight)
+	}
+	if volume := s.Volumes[size]; s.MaxVolume > 0 && volume > 0 {
+		fit = min(fit, max(s.MaxVolume-parcel.Volume, 0)/volume)
+	}
+	return fit
+}
+
+// add returns the group with count more packs of the size in each parcel.
+func (s ParcelSpec) add(parcel Parcel, size, count int) Parcel {
+	parcel.Packs = maps.Clone(parcel.Packs)
+	if parcel.Packs == nil {
+		parcel.Packs = make(map[int]int)
+	}
+
+	parcel.Packs[size] += count
+	parcel.Weight += s.Weights[size] * count
+	parcel.Volume += s.Volumes[size] * count
+	return parcel
+}
+
+// withCount returns a copy of the group with another number of parcels.
+func withCount(parcel Parcel, count int) Parcel {
+	parcel.Packs = maps.Clone(parcel.Packs)
+	parcel.Count = count
+	return parcel
+}
+
+// groupParcels groups the packs of the result into parcels when the parcels are
+// limited, leaving the result untouched otherwise.
+func (pc *PackCalculator) groupParcels(result PackResult) (PackResult, error) {
+	spec := pc.GetParcelSpec()
+	if !spec.limited() {
+		return result, nil
+	}
+
+	parcels, err := spec.Group(result.Packs)
+	if err != nil {
+		return PackResult{}, err
+	}
+
+	result.Parcels = parcels
+	return result, nil
+}
+
+// UpdateParcelSpec replaces the pack weights and volumes and the parcel limits.
+// Weights, volumes and limits must not be negative.
+func (pc *PackCalculator) UpdateParcelSpec(spec ParcelSpec) {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+
+	pc.parcelSpec = spec.clone()
+}
+
+// GetParcelSpec returns the currently configured pack weights, volumes and parcel limits.
+func (pc *PackCalculator) GetParcelSpec() ParcelSpec {
+	pc.mu.RLock()
+	defer pc.mu.RUnlock()
+
+	return pc.parcelSpec.clone()
+}
+
+// GetParcelCount returns the number of parcels the result is shipped in.
+func (pr *PackResult) GetParcelCount() int {
+	total := 0
+	for _, parcel := range pr.Parcels {
+		total += parcel.Count
+	}
+	return total
+}
@@ -0,0 +1,238 @@
+package domain
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParcelSpec_Group(t *testing.T) {
+	spec := ParcelSpec{
+		Weights:   map[int]int{250: 3000, 500: 5500, 1000: 10500, 2000: 21000},
+		Volumes:   map[int]int{250: 4000, 500: 7500, 1000: 15000, 2000: 30000},
+		MaxWeight: 31500,
+		MaxVolume: 60000,
+	}
+
+	tests := []struct {
+		name     string
+		spec     ParcelSpec
+		packs    map[int]int
+		expected []Parcel
+	}{
+		{
+			name:  "should ship packs that fit together in one parcel",
+			spec:  spec,
+			packs: map[int]int{1000: 1, 500: 1, 250: 1},
+			expected: []Parcel{
+				{Count: 1, Packs: map[int]int{1000: 1, 500: 1, 250: 1}, Weight: 19000, Volume: 26500},
+			},
+		},
+		{
+			name:  "should fill the heaviest packs first and add smaller packs to them",
+			spec:  spec,
+			packs: map[int]int{2000: 3, 1000: 1, 250: 4},
+			expected: []Parcel{
+				{Count: 1, Packs: map[int]int{2000: 1, 1000: 1}, Weight: 31500, Volume: 45000},
+				{Count: 1, Packs: map[int]int{2000: 1, 250: 3}, Weight: 30000, Volume: 42000},
+				{Count: 1, Packs: map[int]int{2000: 1, 250: 1}, Weight: 24000, Volume: 34000},
+			},
+		},
+		{
+			name:  "should split a group when only some of its parcels have room",
+			spec:  spec,
+			packs: map[int]int{2000: 3, 250: 3},
+			expected: []Parcel{
+				{Count: 1, Packs: map[int]int{2000: 1, 250: 3}, Weight: 30000, Volume: 42000},
+				{Count: 2, Packs: map[int]int{2000: 1}, Weight: 21000, Volume: 30000},
+			},
+		},
+		{
+			name:  "should report identical full parcels once",
+			spec:  spec,
+			packs: map[int]int{250: 25},
+			expected: []Parcel{
+				{Count: 2, Packs: map[int]int{250: 10}, Weight: 30000, Volume: 40000},
+				{Count: 1, Packs: map[int]int{250: 5}, Weight: 15000, Volume: 20000},
+			},
+		},
+		{
+			name:  "should limit by volume when weight is unlimited",
+			spec:  ParcelSpec{Volumes: map[int]int{250: 4000}, MaxVolume: 10000},
+			packs: map[int]int{250: 4},
+			expected: []Parcel{
+				{Count: 2, Packs: map[int]int{250: 2}, Weight: 0, Volume: 8000},
+			},
+		},
+		{
+			name:  "should put packs without metadata in the first parcel",
+			spec:  ParcelSpec{Weights: map[int]int{500: 20000}, MaxWeight: 30000},
+			packs: map[int]int{500: 2, 250: 7},
+			expected: []Parcel{
+				{Count: 1, Packs: map[int]int{500: 1, 250: 7}, Weight: 20000, Volume: 0},
+				{Count: 1, Packs: map[int]int{500: 1}, Weight: 20000, Volume: 0},
+			},
+		},
+		{
+			name:     "should ship no parcels for no packs",
+			spec:     spec,
+			packs:    map[int]int{},
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parcels, err := tt.spec.Group(tt.packs)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, parcels)
+		})
+	}
+
+	t.Run("should reject packs heavier than a parcel", func(t *testing.T) {
+		_, err := ParcelSpec{Weights: map[int]int{5000: 52000}, MaxWeight: 31500}.Group(map[int]int{5000: 1})
+		assert.ErrorIs(t, err, ErrParcelLimit)
+	})
+}
+
+func TestParcelSpec_Group_MatchesFirstFitDecreasing(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	sizes := []int{250, 500, 1000, 2000}
+
+	for i := 0; i < 500; i++ {
+		spec := ParcelSpec{Weights: map[int]int{}, Volumes: map[int]int{}, MaxWeight: 30000, MaxVolume: 50000}
+		packs := map[int]int{}
+		for _, size := range sizes {
+			spec.Weights[size] = 1000 + random.Intn(15000)
+			spec.Volumes[size] = 1000 + random.Intn(25000)
+			if count := random.Intn(8); count > 0 {
+				packs[size] = count
+			}
+		}
+
+		parcels, err := spec.Group(packs)
+		require.NoError(t, err)
+
+		expected := firstFitDecreasing(spec, packs)
+
+		var actual []map[int]int
+		for _, parcel := range parcels {
+			assert.LessOrEqual(t, parcel.Weight, spec.MaxWeight)
+			assert.LessOrEqual(t, parcel.Volume, spec.MaxVolume)
+			for j := 0; j < parcel.Count; j++ {
+				actual = append(actual, parcel.Packs)
+			}
+		}
+		assert.Equal(t, expected, actual, "spec %+v, packs %v", spec, packs)
+	}
+}
+
+// firstFitDecreasing places one pack at a time into the first parcel with room,
+// in the order used by ParcelSpec.Group.
+func firstFitDecreasing(spec ParcelSpec, packs map[int]int) []map[int]int {
+	sizes := []int{}
+	for size := range packs {
+		sizes = append(sizes, size)
+	}
+	for i := range sizes {
+		for j := i + 1; j < len(sizes); j++ {
+			a, b := sizes[i], sizes[j]
+			if spec.Weights[b] > spec.Weights[a] ||
+				(spec.Weights[b] == spec.Weights[a] && spec.Volumes[b] > spec.Volumes[a]) ||
+				(spec.Weights[b] == spec.Weights[a] && spec.Volumes[b] == spec.Volumes[a] && b > a) {
+				sizes[i], sizes[j] = b, a
+			}
+		}
+	}
+
+	var parcels []map[int]int
+	var weights, volumes []int
+
+	for _, size := range sizes {
+		for n := 0; n < packs[size]; n++ {
+			placed := false
+			for i := range parcels {
+				if weights[i]+spec.Weights[size] <= spec.MaxWeight && volumes[i]+spec.Volumes[size] <= spec.MaxVolume {
+					parcels[i][size]++
+					weights[i] += spec.Weights[size]
+					volumes[i] += spec.Volumes[size]
+					placed = true
+					break
+				}
+			}
+			if !placed {
+				parcels = append(parcels, map[int]int{size: 1})
+				weights = append(weights, spec.Weights[size])
+				volumes = append(volumes, spec.Volumes[size])
+			}
+		}
+	}
+
+	return parcels
+}
+
+func TestPackCalculator_CalculateWithOptions_Parcels(t *testing.T) {
+	calculator := NewPackCalculator([]int{250, 500, 1000, 2000, 5000})
+
+	t.Run("should not group results without parcel limits", func(t *testing.T) {
+		result, err := calculator.CalculateWithOptions(context.Background(), 12001, CalculateOptions{})
+		require.NoError(t, err)
+		assert.Nil(t, result.Parcels)
+	})
+
+	calculator.UpdateParcelSpec(ParcelSpec{
+		Weights:   map[int]int{250: 3000, 500: 5500, 1000: 10500, 2000: 21000, 5000: 30000},
+		MaxWeight: 31500,
+	})
+
+	t.Run("should group the chosen packs into parcels", func(t *testing.T) {
+		result, err := calculator.CalculateWithOptions(context.Background(), 12001, CalculateOptions{})
+		require.NoError(t, err)
+
+		assert.Equal(t, map[int]int{5000: 2, 2000: 1, 250: 1}, result.Packs)
+		assert.Equal(t, []Parcel{
+			{Count: 2, Packs: map[int]int{5000: 1}, Weight: 30000},
+			{Count: 1, Packs: map[int]int{2000: 1, 250: 1}, Weight: 24000},
+		}, result.Parcels)
+		assert.Equal(t, 3, result.GetParcelCount())
+	})
+
+	t.Run("should group calculated and batched results", func(t *testing.T) {
+		expected := []Parcel{
+			{Count: 2, Packs: map[int]int{5000: 1}, Weight: 30000},
+			{Count: 1, Packs: map[int]int{2000: 1, 250: 1}, Weight: 24000},
+		}
+
+		result, err := calculator.CalculateContext(context.Background(), 12001)
+		require.NoError(t, err)
+		assert.Equal(t, expected, result.Parcels)
+
+		results := calculator.CalculateBatch(context.Background(), []int{12001, 251}, 2)
+		require.NoError(t, results[0].Err)
+		require.NoError(t, results[1].Err)
+		assert.Equal(t, expected, results[0].Result.Parcels)
+		assert.Equal(t, []Parcel{{Count: 1, Packs: map[int]int{500: 1}, Weight: 5500}}, results[1].Result.Parcels)
+	})
+
+	t.Run("should group explained results", func(t *testing.T) {
+		result, _, err := calculator.Explain(context.Background(), 251)
+		require.NoError(t, err)
+		assert.Equal(t, []Parcel{{Count: 1, Packs: map[int]int{500: 1}, Weight: 5500}}, result.Parcels)
+	})
+
+	t.Run("should fail when a pack exceeds the parcel limits", func(t *testing.T) {
+		heavy := NewPackCalculator([]int{250})
+		heavy.UpdateParcelSpec(ParcelSpec{Weights: map[int]int{250: 40000}, MaxWeight: 31500})
+
+		_, err := heavy.CalculateWithOptions(context.Background(), 1, CalculateOptions{})
+		assert.ErrorIs(t, err, ErrParcelLimit)
+
+		_, err = heavy.CalculateContext(context.Background(), 1)
+		assert.ErrorIs(t, err, ErrParcelLimit)
+
+		assert.ErrorIs(t, heavy.CalculateBatch(context.Background(), []int{1}, 1)[0].Err, ErrParcelLimit)
+	})
+}
@@ -0,0 +1,66 @@
+package domain
+
+import "math"
+
+// periodThreshold returns the quantity above which every optimal combination
+// contains at least one pack of the largest size.
+//
+// All pack sizes are multiples of g = gcd(sizes), so partial sums of packs smaller
+// than the largest size L fall into at most L/g residue classes modulo L. Any
+// combination with L/g or more of those smaller packs therefore contains a subset
+// summing to a multiple of L, which can be swapped for fewer packs of size L with
+// the same item count. An optimal combination thus holds at most L/g-1 smaller
+// packs, worth at most (L/g-1) times the second largest size. Above that bound
+// (which is never below the Frobenius number of the set) the table repeats with
+// period L: quantity q is reachable exactly when q-L is, using one more pack.
+//
+// The threshold saturates at math.MaxInt for sets whose bound does not fit an int.
+func periodThreshold(packSizes []int) int {
+	largestPack := packSizes[len(packSizes)-1]
+
+	secondLargestPack := 0
+	divisor := largestPack
+	for _, packSize := range packSizes {
+		divisor = gcd(divisor, packSize)
+		if packSize < largestPack {
+			secondLargestPack = packSize
+		}
+	}
+
+	residueClasses := largestPack/divisor - 1
+	if secondLargestPack > 0 && residueClasses > math.MaxInt/secondLargestPack {
+		return math.MaxInt
+	}
+
+	return residueClasses * secondLargestPack
+}
+
+// reduceOrder splits an order into a number of largest packs and a residual order
+// that is small enough to be solved with the dynamic programming table.
+//
+// The residual stays above the period threshold, so every quantity searched for
+// the original order maps to the residual's search range by removing the same
+// number of largest packs, and the solutions found are identical.
+func reduceOrder(order int, packSizes []int) (residualOrder, largestPacks int) {
+	return reduceAbove(order, periodThreshold(packSizes), packSizes[len(packSizes)-1])
+}
+
+// reduceAbove takes as many packs of the size out of the order as it can while
+// the residual order stays above the threshold.
+func reduceAbove(order, threshold, packSize int) (residualOrder, packs int) {
+	if order <= threshold {
+		return order, 0
+	}
+
+	packs = (order - threshold - 1) / packSize
+
+	return order - packs*packSize, packs
+}
+
+// gcd returns the greatest common divisor of two positive integers.
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
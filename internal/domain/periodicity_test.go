@@ -0,0 +1,211 @@
+package domain
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPeriodThreshold(t *testing.T) {
+	tests := []struct {
+		name              string
+		packSizes         []int
+		expectedThreshold int
+	}{
+		{
+			name:              "default pack sizes",
+			packSizes:         []int{250, 500, 1000, 2000, 5000},
+			expectedThreshold: 38000,
+		},
+		{
+			name:              "coin sizes",
+			packSizes:         []int{1, 5, 10, 25},
+			expectedThreshold: 240,
+		},
+		{
+			name:              "sizes sharing a common divisor",
+			packSizes:         []int{6, 9},
+			expectedThreshold: 12,
+		},
+		{
+			name:              "single pack size",
+			packSizes:         []int{100},
+			expectedThreshold: 0,
+		},
+		{
+			name:              "duplicate largest size",
+			packSizes:         []int{3, 7, 7},
+			expectedThreshold: 18,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedThreshold, periodThreshold(tt.packSizes))
+		})
+	}
+}
+
+func TestReduceOrder(t *testing.T) {
+	packSizes := []int{250, 500, 1000, 2000, 5000}
+	threshold := periodThreshold(packSizes)
+
+	t.Run("orders up to the threshold are not reduced", func(t *testing.T) {
+		for _, order := range []int{1, 12001, threshold} {
+			residualOrder, largestPacks := reduceOrder(order, packSizes)
+
+			assert.Equal(t, order, residualOrder)
+			assert.Zero(t, largestPacks)
+		}
+	})
+
+	t.Run("larger orders keep a residual just above the threshold", func(t *testing.T) {
+		for _, order := range []int{threshold + 1, threshold + 5000, threshold + 5001, 1_000_000_000} {
+			residualOrder, largestPacks := reduceOrder(order, packSizes)
+
+			assert.Equal(t, order, residualOrder+largestPacks*5000)
+			assert.Greater(t, residualOrder, threshold)
+			assert.LessOrEqual(t, residualOrder, threshold+5000)
+		}
+	})
+}
+
+func TestPackCalculator_Calculate_MatchesExactSolver(t *testing.T) {
+	packSets := [][]int{
+		{250, 500, 1000, 2000, 5000},
+		{1, 5, 10, 25},
+		{6, 9, 20},
+		{17, 23, 29},
+		{4, 7},
+		{100},
+	}
+
+	for _, packSizes := range packSets {
+		calculator := NewPackCalculator(packSizes)
+		largestPack := packSizes[len(packSizes)-1]
+		limit := periodThreshold(packSizes) + 3*largestPack
+
+		step := 1
+		if limit > 2000 {
+			step = limit / 2000
+		}
+
+		for order := 1; order <= limit; order += step {
+			expected := exactSolution(t, calculator, order)
+			result := calculator.Calculate(order)
+
+			require.Equal(t, expected, result, "pack sizes %v, order %d", packSizes, order)
+		}
+	}
+}
+
+func TestPackCalculator_Calculate_MatchesBruteForce(t *testing.T) {
+	packSets := [][]int{
+		{3, 5},
+		{6, 9, 20},
+		{4, 7, 11},
+		{5, 12, 13},
+	}
+
+	for _, packSizes := range packSets {
+		calculator := NewPackCalculator(packSizes)
+		limit := periodThreshold(packSizes) + 3*packSizes[len(packSizes)-1]
+
+		for order := 1; order <= limit; order++ {
+			expectedItems, expectedPacks := bruteForceSolution(packSizes, order)
+			result := calculator.Calculate(order)
+
+			require.Equal(t, expectedItems, result.TotalItems, "pack sizes %v, order %d", packSizes, order)
+			require.Equal(t, expectedPacks, result.GetTotalPackCount(), "pack sizes %v, order %d", packSizes, order)
+		}
+	}
+}
+
+func TestPackCalculator_Calculate_HugeOrders(t *testing.T) {
+	tests := []struct {
+		name               string
+		packSizes          []int
+		order              int
+		expectedTotalItems int
+		expectedPacks      map[int]int
+	}{
+		{
+			name:               "one billion items with default sizes",
+			packSizes:          []int{250, 500, 1000, 2000, 5000},
+			order:              1_000_000_000,
+			expectedTotalItems: 1_000_000_000,
+			expectedPacks:      map[int]int{5000: 200_000},
+		},
+		{
+			name:               "one billion and one items with default sizes",
+			packSizes:          []int{250, 500, 1000, 2000, 5000},
+			order:              1_000_000_001,
+			expectedTotalItems: 1_000_000_250,
+			expectedPacks:      map[int]int{5000: 200_000, 250: 1},
+		},
+		{
+			name:               "huge order with coin sizes",
+			packSizes:          []int{1, 5, 10, 25},
+			order:              1_000_000_028,
+			expectedTotalItems: 1_000_000_028,
+			expectedPacks:      map[int]int{25: 40_000_001, 1: 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calculator := NewPackCalculator(tt.packSizes)
+			result := calculator.Calculate(tt.order)
+
+			assert.Equal(t, tt.order, result.Order)
+			assert.Equal(t, tt.expectedTotalItems, result.TotalItems)
+			assert.Equal(t, tt.expectedPacks, result.Packs)
+		})
+	}
+}
+
+// exactSolution solves the whole order with the dynamic programming table,
+// without reducing it by the period threshold.
+func exactSolution(t *testing.T, calculator *PackCalculator, order int) PackResult {
+	t.Helper()
+
+	packSizes := calculator.GetPackSizes()
+	searchLimit := order + packSizes[len(packSizes)-1]
+
+	table := newPackTable(packSizes, searchLimit)
+	require.NoError(t, calculator.buildOptimalSolutions(context.Background(), table, 1))
+
+	result, err := calculator.findBestSolutionForOrder(table, order, searchLimit, packSizes)
+	require.NoError(t, err)
+
+	return result
+}
+
+// bruteForceSolution enumerates every pack combination up to order+largestPack and
+// returns the fewest items that fulfill the order and the fewest packs for them.
+func bruteForceSolution(packSizes []int, order int) (totalItems, totalPacks int) {
+	searchLimit := order + packSizes[len(packSizes)-1]
+	totalItems, totalPacks = -1, -1
+
+	var enumerate func(index, items, packs int)
+	enumerate = func(index, items, packs int) {
+		if index == len(packSizes) {
+			if items < order {
+				return
+			}
+			if totalItems == -1 || items < totalItems || (items == totalItems && packs < totalPacks) {
+				totalItems, totalPacks = items, packs
+			}
+			return
+		}
+
+		for count := 0; items+count*packSizes[index] <= searchLimit; count++ {
+			enumerate(index+1, items+count*packSizes[index], packs+count)
+		}
+	}
+	enumerate(0, 0, 0)
+
+	return totalItems, totalPacks
+}
@@ -0,0 +1,77 @@
+package domain
+
+import "maps"
+
+// PackRule restricts how a pack size may be used. Zero fields do not restrict.
+type PackRule struct {
+	// MaxPacks is the most packs of the size a single order may use.
+	MaxPacks int
+
+	// MinOrder is the smallest order the size may be used for.
+	MinOrder int
+
+	// MaxOrder is the largest order the size may be used for.
+	MaxOrder int
+}
+
+// allows reports whether the rule lets the size be used for the order.
+func (r PackRule) allows(order int) bool {
+	return order >= r.MinOrder && (r.MaxOrder == 0 || order <= r.MaxOrder)
+}
+
+// PackRules maps a pack size to its rule. Sizes without a rule are unrestricted.
+type PackRules map[int]PackRule
+
+// Restricts reports whether any rule limits the packs available to the order.
+func (r PackRules) Restricts(order int) bool {
+	for _, rule := range r {
+		if rule.MaxPacks > 0 || !rule.allows(order) {
+			return true
+		}
+	}
+	return false
+}
+
+// limit returns the stock available to the order once the rules apply: sizes the
+// order may not use have no stock, and sizes with MaxPacks have at most that many
+// packs. The stock itself is never modified.
+func (r PackRules) limit(order int, stock map[int]int) map[int]int {
+	if !r.Restricts(order) {
+		return stock
+	}
+
+	limited := maps.Clone(stock)
+	if limited == nil {
+		limited = make(map[int]int)
+	}
+
+	for size, rule := range r {
+		count, inStock := limited[size]
+
+		switch {
+		case !rule.allows(order):
+			limited[size] = 0
+		case rule.MaxPacks > 0 && (!inStock || count > rule.MaxPacks):
+			limited[size] = rule.MaxPacks
+		}
+	}
+
+	return limited
+}
+
+// UpdateRules replaces the pack size rules respected by every calculation.
+// Rules must not be negative, and MinOrder must not exceed a non-zero MaxOrder.
+func (pc *PackCalculator) UpdateRules(rules PackRules) {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+
+	pc.rules = maps.Clone(rules)
+}
+
+// GetRules returns the currently configured pack size rules.
+func (pc *PackCalculator) GetRules() PackRules {
+	pc.mu.RLock()
+	defer pc.mu.RUnlock()
+
+	return maps.Clone(pc.rules)
+}
@@ -0,0 +1,153 @@
+package domain
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPackCalculator_Rules(t *testing.T) {
+	calculator := NewPackCalculator([]int{250, 500, 1000, 2000, 5000})
+	calculator.UpdateRules(PackRules{
+		250:  {MaxPacks: 2},
+		5000: {MinOrder: 10001},
+	})
+
+	tests := []struct {
+		name          string
+		order         int
+		expectedItems int
+		expectedPacks map[int]int
+	}{
+		{
+			name:          "should ignore rules that do not apply to the order",
+			order:         12001,
+			expectedItems: 12250,
+			expectedPacks: map[int]int{5000: 2, 2000: 1, 250: 1},
+		},
+		{
+			name:          "should not use a size below its minimum order",
+			order:         10000,
+			expectedItems: 10000,
+			expectedPacks: map[int]int{2000: 5},
+		},
+		{
+			name:          "should use a size from its minimum order",
+			order:         10001,
+			expectedItems: 10250,
+			expectedPacks: map[int]int{5000: 2, 250: 1},
+		},
+		{
+			name:          "should use no more packs of a size than allowed",
+			order:         750,
+			expectedItems: 750,
+			expectedPacks: map[int]int{500: 1, 250: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := calculator.CalculateContext(context.Background(), tt.order)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expectedItems, result.TotalItems)
+			assert.Equal(t, tt.expectedPacks, result.Packs)
+
+			result, err = calculator.CalculateWithOptions(context.Background(), tt.order, CalculateOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedPacks, result.Packs)
+		})
+	}
+
+	t.Run("should cap the packs of a size below the stock", func(t *testing.T) {
+		limited := NewPackCalculator([]int{250, 500})
+		limited.UpdateRules(PackRules{250: {MaxPacks: 2}, 500: {MaxPacks: 1}})
+
+		result, err := limited.CalculateWithOptions(context.Background(), 1000, CalculateOptions{Stock: map[int]int{250: 5}})
+		require.NoError(t, err)
+		assert.Equal(t, map[int]int{250: 2, 500: 1}, result.Packs)
+	})
+
+	t.Run("should report orders the rules cannot fulfill", func(t *testing.T) {
+		limited := NewPackCalculator([]int{250, 500})
+		limited.UpdateRules(PackRules{250: {MaxPacks: 2}, 500: {MaxPacks: 1}})
+
+		_, err := limited.CalculateContext(context.Background(), 1001)
+		assert.ErrorIs(t, err, ErrRulesInfeasible)
+		assert.ErrorIs(t, err, ErrInfeasible)
+
+		_, err = limited.CalculateWithOptions(context.Background(), 1001, CalculateOptions{Stock: map[int]int{250: 5}})
+		assert.ErrorIs(t, err, ErrInsufficientStock)
+	})
+
+	t.Run("should report orders no size may be used for", func(t *testing.T) {
+		windowed := NewPackCalculator([]int{250})
+		windowed.UpdateRules(PackRules{250: {MaxOrder: 1000}})
+
+		_, err := windowed.CalculateContext(context.Background(), 1001)
+		assert.ErrorIs(t, err, ErrRulesInfeasible)
+	})
+
+	t.Run("should reduce large orders by the largest size they may use without limit", func(t *testing.T) {
+		result, err := calculator.CalculateContext(context.Background(), 50_000_001)
+		require.NoError(t, err)
+		assert.Equal(t, map[int]int{5000: 10_000, 250: 1}, result.Packs)
+
+		capped := NewPackCalculator([]int{250, 500, 1000, 2000, 5000})
+		capped.UpdateRules(PackRules{5000: {MaxPacks: 2}})
+
+		result, err = capped.CalculateContext(context.Background(), 40_000_000)
+		require.NoError(t, err)
+		assert.Equal(t, map[int]int{5000: 2, 2000: 19_995}, result.Packs)
+
+		windowed := NewPackCalculator([]int{250, 500, 1000, 2000, 5000})
+		windowed.UpdateRules(PackRules{5000: {MaxOrder: 1_000_000}})
+
+		result, err = windowed.CalculateContext(context.Background(), 40_000_001)
+		require.NoError(t, err)
+		assert.Equal(t, map[int]int{2000: 20_000, 250: 1}, result.Packs)
+	})
+
+	t.Run("should apply the rules to batches", func(t *testing.T) {
+		results := calculator.CalculateBatch(context.Background(), []int{10000, 10001, 12001}, 2)
+
+		require.Len(t, results, 3)
+		assert.Equal(t, map[int]int{2000: 5}, results[0].Result.Packs)
+		assert.Equal(t, map[int]int{5000: 2, 250: 1}, results[1].Result.Packs)
+		assert.Equal(t, map[int]int{5000: 2, 2000: 1, 250: 1}, results[2].Result.Packs)
+	})
+
+	t.Run("should apply the rules to alternatives", func(t *testing.T) {
+		alternatives, err := calculator.Alternatives(context.Background(), 5000, CalculateOptions{}, 3)
+		require.NoError(t, err)
+
+		for _, alternative := range alternatives {
+			assert.Zero(t, alternative.Packs[5000])
+			assert.LessOrEqual(t, alternative.Packs[250], 2)
+		}
+	})
+
+	t.Run("should not explain restricted orders", func(t *testing.T) {
+		windowed := NewPackCalculator([]int{250, 500, 1000, 2000, 5000})
+		windowed.UpdateRules(PackRules{5000: {MinOrder: 10001}})
+
+		_, _, err := windowed.Explain(context.Background(), 10000)
+		assert.ErrorIs(t, err, ErrNotExplainable)
+
+		_, _, err = windowed.Explain(context.Background(), 12001)
+		assert.NoError(t, err)
+	})
+}
+
+func TestPackRules_Restricts(t *testing.T) {
+	rules := PackRules{250: {MinOrder: 100, MaxOrder: 1000}, 500: {}}
+
+	assert.True(t, rules.Restricts(99))
+	assert.False(t, rules.Restricts(100))
+	assert.False(t, rules.Restricts(1000))
+	assert.True(t, rules.Restricts(1001))
+	assert.False(t, PackRules(nil).Restricts(1))
+	assert.True(t, PackRules{250: {MaxPacks: 1}}.Restricts(1))
+}
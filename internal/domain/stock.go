@@ -0,0 +1,526 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// CalculateOptions adjusts a single calculation.
+type CalculateOptions struct {
+	// Stock limits how many packs of each size may be used. Sizes missing from
+	// the map are unlimited, so a nil map leaves every size unlimited.
+	Stock map[int]int
+
+	// Objective selects what the calculation optimizes. The zero value ships
+	// the fewest items, then the fewest packs.
+	Objective Objective
+
+	// Strategy ranks the combinations of the fewest items in place of the
+	// fewest packs. It only applies to ObjectiveMinItemsThenPacks; nil
+	// selects FewestPacks.
+	Strategy Strategy
+
+	// MaxSurplus caps the items shipped above the order. Nil leaves the surplus
+	// unlimited and zero requires an exact fit.
+	MaxSurplus *int
+
+	// Mode selects whether the order is fulfilled or under-fulfilled. The zero
+	// value fulfils it.
+	Mode Mode
+}
+
+// stockLayer is one step of the bounded knapsack: either count packs of a size
+// taken together, at most once, or any number of packs of an unlimited size.
+// cost is the cost of the packs the layer adds.
+type stockLayer struct {
+	packSize  int
+	count     int
+	unlimited bool
+	cost      int
+}
+
+// stockTable is the dynamic programming table for stock-bounded and cost-aware
+// calculations.
+//
+// Like packTable, packCounts holds the minimum number of packs that sum exactly to
+// each quantity. Since the back-pointer of a bounded table depends on the packs
+// still available, every layer records instead a bitset of the quantities it
+// improved, which is enough to walk the layers back and rebuild the packs.
+// For cost-aware objectives costs holds the cost of each quantity, which then
+// takes precedence over the pack count; it is nil otherwise.
+type stockTable struct {
+	layers     []stockLayer
+	packCounts []int32
+	costs      []int
+	taken      [][]uint64
+}
+
+// CalculateWithOptions computes the optimal pack combination like CalculateContext,
+// honoring the options. With stock limits the same rules apply to the packs in
+// stock only: the fewest items, then the fewest packs.
+//
+// Bounded stock is solved as a bounded knapsack: each limited size is split into
+// layers of 1, 2, 4, ... packs taken at most once, so every count up to the stock
+// can be formed. Orders that the stock cannot cover fail with ErrInsufficientStock.
+// Cost-aware objectives use the same table with every size unlimited unless
+// stocked. The periodicity reduction only applies to the fewest items while the
+// largest size is not limited; otherwise the table covers the whole order.
+//
+// Pack size rules are applied as further stock limits: sizes an order may not use
+// have no stock and MaxPacks caps the rest. Orders the rules alone cannot cover
+// fail with ErrRulesInfeasible.
+//
+// ModeUnderFulfil ships the most items that do not exceed the order instead,
+// then the fewest packs, from the same tables; the periodicity reduction applies
+// to it as well, see reduceUnderOrder. Orders no pack fits in ship nothing.
+//
+// With MaxSurplus, orders whose best combination ships too many items fail with
+// ErrNoExactFit when the maximum is zero and ErrSurplusExceeded otherwise. Only
+// ObjectiveMinCost can then choose another total: the cheapest one within it.
+//
+// A strategy other than FewestPacks ranks every combination of the chosen total
+// afterwards; see rankPacks. When the parcels are limited, the chosen packs are
+// grouped into parcels; see ParcelSpec.Group.
+func (pc *PackCalculator) CalculateWithOptions(ctx context.Context, order int, opts CalculateOptions) (PackResult, error) {
+	ctx, cancel, err := pc.bound(ctx, order)
+	defer cancel()
+	if err != nil {
+		return PackResult{}, err
+	}
+
+	if !opts.Objective.valid() {
+		return PackResult{}, fmt.Errorf("%w: %q", ErrInvalidObjective, opts.Objective)
+	}
+
+	if opts.Strategy != nil && opts.Objective.costAware() {
+		return PackResult{}, fmt.Errorf("%w: strategies only apply to the %s objective", ErrInvalidStrategy, ObjectiveMinItemsThenPacks)
+	}
+
+	if opts.MaxSurplus != nil && *opts.MaxSurplus < 0 {
+		return PackResult{}, ErrInvalidMaxSurplus
+	}
+
+	if !opts.Mode.valid() {
+		return PackResult{}, fmt.Errorf("%w: %q", ErrInvalidMode, opts.Mode)
+	}
+
+	if opts.Mode == ModeUnderFulfil && opts.Objective.costAware() {
+		return PackResult{}, fmt.Errorf("%w: %s only applies to the %s objective", ErrInvalidMode, ModeUnderFulfil, ObjectiveMinItemsThenPacks)
+	}
+
+	result, err := pc.calculateWithOptions(ctx, order, opts)
+	if err != nil {
+		return PackResult{}, err
+	}
+
+	if opts.MaxSurplus != nil && result.GetSurplus() > *opts.MaxSurplus {
+		return PackResult{}, surplusError(*opts.MaxSurplus)
+	}
+
+	if opts.Strategy != nil {
+		result, err = rankPacks(ctx, result, pc.GetRules().limit(order, opts.Stock), pc.GetCosts(), opts.Strategy)
+		if err != nil {
+			return PackResult{}, err
+		}
+	}
+
+	return pc.groupParcels(result)
+}
+
+// calculateWithOptions computes the optimal pack combination for the stock and
+// objective of the options and the pack size rules.
+func (pc *PackCalculator) calculateWithOptions(ctx context.Context, order int, opts CalculateOptions) (PackResult, error) {
+	for _, count := range opts.Stock {
+		if count < 0 {
+			return PackResult{}, ErrInvalidStock
+		}
+	}
+
+	stock := pc.GetRules().limit(order, opts.Stock)
+
+	if len(stock) == 0 && !opts.Objective.costAware() {
+		if opts.Mode == ModeUnderFulfil {
+			return pc.calculateUnderTable(ctx, order)
+		}
+		return pc.calculateTable(ctx, order)
+	}
+
+	result, err := pc.calculateStock(ctx, order, stock, opts)
+	if errors.Is(err, ErrInsufficientStock) && len(opts.Stock) == 0 {
+		return PackResult{}, ErrRulesInfeasible
+	}
+
+	return result, err
+}
+
+// calculateStock computes the optimal pack combination for the objective and
+// maximum surplus of the options from the packs in stock; sizes missing from the
+// stock are unlimited.
+func (pc *PackCalculator) calculateStock(ctx context.Context, order int, stock map[int]int, opts CalculateOptions) (PackResult, error) {
+	packSizes, costs := pc.configuration()
+
+	if order < 0 {
+		return PackResult{}, ErrInvalidOrder
+	}
+
+	if order == 0 {
+		return PackResult{
+			Order:      order,
+			TotalItems: 0,
+			Packs:      make(map[int]int),
+			PackSizes:  packSizes,
+		}, nil
+	}
+
+	if len(packSizes) == 0 {
+		return PackResult{}, ErrNoPackSizes
+	}
+
+	residualOrder, reducedPacks, reducedSize := reduceStockOrder(order, packSizes, stock, opts)
+
+	limit, err := stockSearchLimit(residualOrder, packSizes, stock, opts.Mode)
+	if err != nil {
+		return PackResult{}, err
+	}
+
+	layers := newStockLayers(packSizes, stock, costs, limit)
+	if len(layers)*(limit+1) > maxTableSize*64 {
+		return PackResult{}, fmt.Errorf("%w: stock layers exceed %d bits", ErrOrderTooLarge, maxTableSize*64)
+	}
+
+	if err := spend(ctx, len(layers)*(limit+1)); err != nil {
+		return PackResult{}, err
+	}
+	if err := admit(ctx, len(layers)*(limit+1)); err != nil {
+		return PackResult{}, err
+	}
+
+	table := newStockTable(layers, limit, opts.Objective.costAware())
+	if err := table.build(ctx); err != nil {
+		return PackResult{}, err
+	}
+
+	var quantity int
+	if opts.Mode == ModeUnderFulfil {
+		quantity = table.bestUnder(order)
+	} else if quantity, err = table.fulfil(residualOrder, limit, len(stock) > 0, opts); err != nil {
+		return PackResult{}, err
+	}
+
+	packs := table.packs(quantity)
+	if reducedPacks > 0 {
+		packs[reducedSize] += reducedPacks
+		quantity += reducedPacks * reducedSize
+	}
+
+	return PackResult{
+		Order:      order,
+		TotalItems: quantity,
+		Packs:      packs,
+		PackSizes:  packSizes,
+		TotalCost:  costs.Total(packs),
+	}, nil
+}
+
+// reduceStockOrder reduces the order like reduceOrder, by the largest size that
+// is not limited, when the objective fulfils the order with the fewest items, then
+// packs. It returns the residual order and the packs of that size taken out.
+//
+// The argument of periodThreshold holds for that size with the sizes below it,
+// since the swap only takes smaller packs away, whether they are limited or not.
+// Larger sizes are limited, so their packs are worth at most the items in stock,
+// which are added to the threshold. Cost-aware objectives and ModeUnderFulfil
+// are not reduced.
+func reduceStockOrder(order int, packSizes []int, stock map[int]int, opts CalculateOptions) (residualOrder, packs, packSize int) {
+	if opts.Mode == ModeUnderFulfil || opts.Objective.costAware() {
+		return order, 0, 0
+	}
+
+	unlimited := -1
+	for i, size := range packSizes {
+		if _, limited := stock[size]; !limited {
+			unlimited = i
+		}
+	}
+	if unlimited < 0 {
+		return order, 0, 0
+	}
+
+	var sizes []int
+	for _, size := range packSizes[:unlimited+1] {
+		if count, limited := stock[size]; !limited || count > 0 {
+			sizes = append(sizes, size)
+		}
+	}
+
+	// A best total is below the order plus the largest pack, so it never holds
+	// more than order/size+1 packs of a larger size.
+	threshold := periodThreshold(sizes)
+	for _, size := range packSizes[unlimited+1:] {
+		if threshold >= order {
+			return order, 0, 0
+		}
+		threshold += min(stock[size], order/size+1) * size
+	}
+
+	packSize = packSizes[unlimited]
+	residualOrder, packs = reduceAbove(order, threshold, packSize)
+	return residualOrder, packs, packSize
+}
+
+// stockSearchLimit returns the largest quantity the table must cover. For
+// ModeUnderFulfil that is the order itself.
+//
+// The best total is below order plus the largest pack in stock: removing any pack
+// from a larger total would still fulfill the order with fewer items. When every
+// size is limited the total can also never exceed the items in stock.
+func stockSearchLimit(order int, packSizes []int, stock map[int]int, mode Mode) (int, error) {
+	if mode == ModeUnderFulfil {
+		if order > maxTableSize {
+			return 0, fmt.Errorf("%w: search range exceeds %d quantities", ErrOrderTooLarge, maxTableSize)
+		}
+		return order, nil
+	}
+
+	largestPack := 0
+	capacity := 0
+	limited := true
+
+	for _, size := range slices.Compact(slices.Clone(packSizes)) {
+		count, isLimited := stock[size]
+		if !isLimited {
+			limited = false
+		} else if count == 0 {
+			continue
+		}
+
+		largestPack = max(largestPack, size)
+
+		if isLimited && capacity < order {
+			capacity += min(count, order/size+1) * size
+		}
+	}
+
+	if largestPack == 0 || (limited && capacity < order) {
+		return 0, ErrInsufficientStock
+	}
+
+	if order > maxTableSize-largestPack {
+		return 0, fmt.Errorf("%w: search range exceeds %d quantities", ErrOrderTooLarge, maxTableSize)
+	}
+
+	limit := order + largestPack - 1
+	if limited {
+		limit = min(limit, capacity)
+	}
+
+	return limit, nil
+}
+
+// newStockLayers splits the pack sizes into knapsack layers, largest size first.
+// Limited sizes are capped to the packs that fit in limit and split into powers
+// of two plus the remainder.
+func newStockLayers(packSizes []int, stock map[int]int, costs Costs, limit int) []stockLayer {
+	var layers []stockLayer
+
+	sizes := slices.Compact(slices.Clone(packSizes))
+	for i := len(sizes) - 1; i >= 0; i-- {
+		size := sizes[i]
+
+		count, isLimited := stock[size]
+		if !isLimited {
+			layers = append(layers, stockLayer{packSize: size, unlimited: true, cost: costs.PerPack[size]})
+			continue
+		}
+
+		count = min(count, limit/size)
+		for chunk := 1; count > 0; chunk *= 2 {
+			taken := min(chunk, count)
+			layers = append(layers, stockLayer{packSize: size, count: taken, cost: costs.PerPack[size] * taken})
+			count -= taken
+		}
+	}
+
+	return layers
+}
+
+// newStockTable allocates a table covering quantities 0..limit for the layers,
+// tracking the cost of every quantity when costAware is set.
+func newStockTable(layers []stockLayer, limit int, costAware bool) *stockTable {
+	table := &stockTable{
+		layers:     layers,
+		packCounts: make([]int32, limit+1),
+		taken:      make([][]uint64, len(layers)),
+	}
+
+	if costAware {
+		table.costs = make([]int, limit+1)
+	}
+
+	for quantity := 1; quantity <= limit; quantity++ {
+		table.packCounts[quantity] = unreachable
+	}
+
+	for i := range layers {
+		table.taken[i] = make([]uint64, (limit+64)/64)
+	}
+
+	return table
+}
+
+// build applies every layer in order. Only strictly better solutions replace the
+// current one, so ties are resolved in favor of the earlier, larger packs.
+func (t *stockTable) build(ctx context.Context) error {
+	limit := len(t.packCounts) - 1
+	checked := 0
+
+	improve := func(layer int, quantity, weight int, packs int32, cost int) error {
+		checked++
+		if checked%cancellationCheckInterval == 0 {
+			if err := spend(ctx, 0); err != nil {
+				return err
+			}
+		}
+
+		previous := t.packCounts[quantity-weight]
+		if previous == unreachable {
+			return nil
+		}
+
+		if t.costs != nil {
+			cost += t.costs[quantity-weight]
+		}
+
+		if t.packCounts[quantity] == unreachable || t.better(quantity, previous+packs, cost) {
+			t.packCounts[quantity] = previous + packs
+			if t.costs != nil {
+				t.costs[quantity] = cost
+			}
+			t.taken[layer][quantity/64] |= 1 << (quantity % 64)
+		}
+		return nil
+	}
+
+	for i, layer := range t.layers {
+		// Unlimited layers build on quantities improved by the same layer, so
+		// they run upwards; bounded layers are taken at most once and run downwards.
+		if layer.unlimited {
+			for quantity := layer.packSize; quantity <= limit; quantity++ {
+				if err := improve(i, quantity, layer.packSize, 1, layer.cost); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		weight := layer.packSize * layer.count
+		for quantity := limit; quantity >= weight; quantity-- {
+			if err := improve(i, quantity, weight, int32(layer.count), layer.cost); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// better reports whether packs at the given cost improve on the solution stored
+// for a reachable quantity: the cheaper one for cost-aware tables, then the one
+// with fewer packs.
+func (t *stockTable) better(quantity int, packs int32, cost int) bool {
+	if t.costs != nil && cost != t.costs[quantity] {
+		return cost < t.costs[quantity]
+	}
+	return packs < t.packCounts[quantity]
+}
+
+// fulfil returns the quantity of the optimal solution for the order within the
+// maximum surplus of the options, or why there is none.
+func (t *stockTable) fulfil(order, limit int, stocked bool, opts CalculateOptions) (int, error) {
+	largest := limit
+	if opts.MaxSurplus != nil {
+		largest = min(limit, order+*opts.MaxSurplus)
+	}
+
+	quantity, found := t.best(order, largest, opts.Objective)
+	if found {
+		return quantity, nil
+	}
+
+	if _, reachable := t.best(order, limit, opts.Objective); reachable && opts.MaxSurplus != nil {
+		return 0, surplusError(*opts.MaxSurplus)
+	}
+	if !stocked {
+		return 0, ErrInfeasible
+	}
+	return 0, ErrInsufficientStock
+}
+
+// bestUnder returns the largest reachable quantity not exceeding the order,
+// which the table holds with the fewest packs.
+func (t *stockTable) bestUnder(order int) int {
+	quantity := min(order, len(t.packCounts)-1)
+	for t.packCounts[quantity] == unreachable {
+		quantity--
+	}
+	return quantity
+}
+
+// best returns the quantity of the optimal solution for the order, at most
+// largest. ObjectiveMinCost
+// picks the cheapest quantity, the fewest items among equally cheap ones; every
+// other objective picks the first reachable quantity.
+func (t *stockTable) best(order, largest int, objective Objective) (int, bool) {
+	best := -1
+
+	for quantity := order; quantity <= largest && quantity < len(t.packCounts); quantity++ {
+		if t.packCounts[quantity] == unreachable {
+			continue
+		}
+
+		if objective != ObjectiveMinCost {
+			return quantity, true
+		}
+
+		if best == -1 || t.costs[quantity] < t.costs[best] {
+			best = quantity
+		}
+	}
+
+	return best, best != -1
+}
+
+// packs rebuilds the pack distribution of a reachable quantity by walking the
+// layers back from the last one.
+func (t *stockTable) packs(quantity int) map[int]int {
+	packsBySize := make(map[int]int)
+
+	for i := len(t.layers) - 1; i >= 0 && quantity > 0; {
+		layer := t.layers[i]
+		if t.taken[i][quantity/64]&(1<<(quantity%64)) == 0 {
+			i--
+			continue
+		}
+
+		count := max(layer.count, 1)
+		packsBySize[layer.packSize] += count
+		quantity -= layer.packSize * count
+
+		if !layer.unlimited {
+			i--
+		}
+	}
+
+	return packsBySize
+}
+
+// surplusError returns the error for an order with no combination within the
+// maximum surplus.
+func surplusError(maxSurplus int) error {
+	if maxSurplus == 0 {
+		return ErrNoExactFit
+	}
+	return fmt.Errorf("%w: more than %d items above the order", ErrSurplusExceeded, maxSurplus)
+}
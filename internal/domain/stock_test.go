@@ -0,0 +1,405 @@
+package domain
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPackCalculator_CalculateWithOptions_Stock(t *testing.T) {
+	calculator := NewPackCalculator([]int{250, 500, 1000, 2000, 5000})
+
+	tests := []struct {
+		name          string
+		order         int
+		stock         map[int]int
+		expectedItems int
+		expectedPacks map[int]int
+	}{
+		{
+			name:          "should match the unlimited result when stock suffices",
+			order:         12001,
+			stock:         map[int]int{250: 10, 500: 10, 1000: 10, 2000: 10, 5000: 10},
+			expectedItems: 12250,
+			expectedPacks: map[int]int{5000: 2, 2000: 1, 250: 1},
+		},
+		{
+			name:          "should replace missing large packs with smaller ones",
+			order:         12001,
+			stock:         map[int]int{5000: 1},
+			expectedItems: 12250,
+			expectedPacks: map[int]int{5000: 1, 2000: 3, 1000: 1, 250: 1},
+		},
+		{
+			name:          "should ship more items when small packs run out",
+			order:         1,
+			stock:         map[int]int{250: 0},
+			expectedItems: 500,
+			expectedPacks: map[int]int{500: 1},
+		},
+		{
+			name:          "should treat sizes missing from the stock as unlimited",
+			order:         751,
+			stock:         map[int]int{1000: 0},
+			expectedItems: 1000,
+			expectedPacks: map[int]int{500: 2},
+		},
+		{
+			name:          "should use exactly the packs in stock",
+			order:         1750,
+			stock:         map[int]int{250: 1, 500: 3, 1000: 0, 2000: 0, 5000: 0},
+			expectedItems: 1750,
+			expectedPacks: map[int]int{250: 1, 500: 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := calculator.CalculateWithOptions(context.Background(), tt.order, CalculateOptions{Stock: tt.stock})
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.order, result.Order)
+			assert.Equal(t, tt.expectedItems, result.TotalItems)
+			assert.Equal(t, tt.expectedPacks, result.Packs)
+			assert.Equal(t, []int{250, 500, 1000, 2000, 5000}, result.PackSizes)
+		})
+	}
+}
+
+func TestPackCalculator_CalculateWithOptions_Errors(t *testing.T) {
+	calculator := NewPackCalculator([]int{250, 500, 1000})
+
+	tests := []struct {
+		name        string
+		calculator  *PackCalculator
+		order       int
+		stock       map[int]int
+		expectedErr error
+	}{
+		{
+			name:        "should reject negative orders",
+			order:       -1,
+			stock:       map[int]int{250: 1},
+			expectedErr: ErrInvalidOrder,
+		},
+		{
+			name:        "should reject negative stock",
+			order:       1,
+			stock:       map[int]int{250: -1},
+			expectedErr: ErrInvalidStock,
+		},
+		{
+			name:        "should report stock that cannot cover the order",
+			order:       1751,
+			stock:       map[int]int{250: 1, 500: 1, 1000: 1},
+			expectedErr: ErrInsufficientStock,
+		},
+		{
+			name:        "should report an empty stock",
+			order:       1,
+			stock:       map[int]int{250: 0, 500: 0, 1000: 0},
+			expectedErr: ErrInsufficientStock,
+		},
+		{
+			name:        "should report missing pack sizes",
+			calculator:  NewPackCalculator(nil),
+			order:       1,
+			stock:       map[int]int{250: 1},
+			expectedErr: ErrNoPackSizes,
+		},
+		{
+			name:        "should refuse orders beyond the table size",
+			order:       maxTableSize,
+			stock:       map[int]int{1000: maxTableSize},
+			expectedErr: ErrOrderTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := calculator
+			if tt.calculator != nil {
+				c = tt.calculator
+			}
+
+			_, err := c.CalculateWithOptions(context.Background(), tt.order, CalculateOptions{Stock: tt.stock})
+			assert.ErrorIs(t, err, tt.expectedErr)
+		})
+	}
+
+	t.Run("insufficient stock should be an infeasible order", func(t *testing.T) {
+		assert.ErrorIs(t, ErrInsufficientStock, ErrInfeasible)
+	})
+
+	t.Run("should answer zero orders without a table", func(t *testing.T) {
+		result, err := calculator.CalculateWithOptions(context.Background(), 0, CalculateOptions{Stock: map[int]int{250: 0}})
+		require.NoError(t, err)
+		assert.Zero(t, result.TotalItems)
+		assert.Empty(t, result.Packs)
+	})
+
+	t.Run("should stop when the context is cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := NewPackCalculator([]int{997, 1009, 4999}).
+			CalculateWithOptions(ctx, 1_000_001, CalculateOptions{Stock: map[int]int{997: 1000}})
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestPackCalculator_CalculateWithOptions_MaxSurplus(t *testing.T) {
+	calculator := NewPackCalculator([]int{250, 500, 1000, 2000, 5000})
+	surplus := func(n int) *int { return &n }
+
+	tests := []struct {
+		name          string
+		order         int
+		opts          CalculateOptions
+		expectedPacks map[int]int
+		expectedErr   error
+	}{
+		{
+			name:          "should accept an exact fit",
+			order:         750,
+			opts:          CalculateOptions{MaxSurplus: surplus(0)},
+			expectedPacks: map[int]int{500: 1, 250: 1},
+		},
+		{
+			name:        "should refuse any surplus with an exact fit",
+			order:       751,
+			opts:        CalculateOptions{MaxSurplus: surplus(0)},
+			expectedErr: ErrNoExactFit,
+		},
+		{
+			name:          "should accept a surplus up to the maximum",
+			order:         12001,
+			opts:          CalculateOptions{MaxSurplus: surplus(249)},
+			expectedPacks: map[int]int{5000: 2, 2000: 1, 250: 1},
+		},
+		{
+			name:        "should refuse a surplus above the maximum",
+			order:       12001,
+			opts:        CalculateOptions{MaxSurplus: surplus(248)},
+			expectedErr: ErrSurplusExceeded,
+		},
+		{
+			name:        "should refuse a surplus above the maximum within stock",
+			order:       1,
+			opts:        CalculateOptions{Stock: map[int]int{250: 0}, MaxSurplus: surplus(300)},
+			expectedErr: ErrSurplusExceeded,
+		},
+		{
+			name:        "should reject a negative maximum surplus",
+			order:       1,
+			opts:        CalculateOptions{MaxSurplus: surplus(-1)},
+			expectedErr: ErrInvalidMaxSurplus,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := calculator.CalculateWithOptions(context.Background(), tt.order, tt.opts)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedPacks, result.Packs)
+		})
+	}
+
+	t.Run("surplus errors should be infeasible orders", func(t *testing.T) {
+		assert.ErrorIs(t, ErrNoExactFit, ErrInfeasible)
+		assert.ErrorIs(t, ErrSurplusExceeded, ErrInfeasible)
+	})
+
+	t.Run("should choose the cheapest total within the maximum surplus", func(t *testing.T) {
+		priced := NewPackCalculator([]int{250, 500, 1000})
+		priced.UpdateCosts(Costs{PerPack: map[int]int{250: 100, 500: 300, 1000: 120}})
+
+		result, err := priced.CalculateWithOptions(context.Background(), 300, CalculateOptions{Objective: ObjectiveMinCost})
+		require.NoError(t, err)
+		assert.Equal(t, map[int]int{1000: 1}, result.Packs)
+
+		result, err = priced.CalculateWithOptions(context.Background(), 300, CalculateOptions{Objective: ObjectiveMinCost, MaxSurplus: surplus(200)})
+		require.NoError(t, err)
+		assert.Equal(t, map[int]int{250: 2}, result.Packs)
+
+		_, err = priced.CalculateWithOptions(context.Background(), 300, CalculateOptions{Objective: ObjectiveMinCost, MaxSurplus: surplus(0)})
+		assert.ErrorIs(t, err, ErrNoExactFit)
+	})
+
+	t.Run("should leave alternatives above the maximum surplus out", func(t *testing.T) {
+		alternatives, err := calculator.Alternatives(context.Background(), 251, CalculateOptions{MaxSurplus: surplus(249)}, 5)
+		require.NoError(t, err)
+		require.Len(t, alternatives, 2)
+		assert.Equal(t, map[int]int{500: 1}, alternatives[0].Packs)
+		assert.Equal(t, map[int]int{250: 2}, alternatives[1].Packs)
+	})
+}
+
+func TestPackCalculator_CalculateWithOptions_MatchesBruteForce(t *testing.T) {
+	packSets := [][]int{
+		{250, 500, 1000, 2000, 5000},
+		{3, 7, 11},
+		{6, 9, 20},
+		{23, 31, 53},
+	}
+
+	random := rand.New(rand.NewSource(1))
+
+	for _, packSizes := range packSets {
+		calculator := NewPackCalculator(packSizes)
+
+		for i := 0; i < 200; i++ {
+			stock := make(map[int]int)
+			for _, size := range packSizes {
+				if random.Intn(4) > 0 {
+					stock[size] = random.Intn(6)
+				}
+			}
+			if len(stock) == 0 {
+				stock[packSizes[0]] = 1
+			}
+
+			order := 1 + random.Intn(6*packSizes[len(packSizes)-1])
+			expectedItems, expectedPacks := bruteForceStockSolution(packSizes, stock, order)
+
+			result, err := calculator.CalculateWithOptions(context.Background(), order, CalculateOptions{Stock: stock})
+			if expectedItems == -1 {
+				require.ErrorIs(t, err, ErrInsufficientStock, "sizes %v, stock %v, order %d", packSizes, stock, order)
+				continue
+			}
+
+			require.NoError(t, err, "sizes %v, stock %v, order %d", packSizes, stock, order)
+			assert.Equal(t, expectedItems, result.TotalItems, "sizes %v, stock %v, order %d", packSizes, stock, order)
+			assert.Equal(t, expectedPacks, result.GetTotalPackCount(), "sizes %v, stock %v, order %d", packSizes, stock, order)
+
+			items := 0
+			for size, count := range result.Packs {
+				items += size * count
+				if limit, limited := stock[size]; limited {
+					assert.LessOrEqual(t, count, limit, "sizes %v, stock %v, order %d", packSizes, stock, order)
+				}
+			}
+			assert.Equal(t, result.TotalItems, items)
+		}
+	}
+}
+
+func TestPackCalculator_CalculateWithOptions_ReducesUnlimitedLargestSize(t *testing.T) {
+	packSets := [][]int{
+		{250, 500, 1000, 2000, 5000},
+		{3, 7, 11},
+		{6, 9, 20},
+		{23, 31, 53},
+	}
+
+	random := rand.New(rand.NewSource(1))
+
+	for _, packSizes := range packSets {
+		calculator := NewPackCalculator(packSizes)
+		largestPack := packSizes[len(packSizes)-1]
+
+		for i := 0; i < 100; i++ {
+			stock := map[int]int{packSizes[random.Intn(len(packSizes)-1)]: random.Intn(6)}
+			order := 1 + random.Intn(40*largestPack)
+
+			reduced, err := calculator.CalculateWithOptions(context.Background(), order, CalculateOptions{Stock: stock})
+			require.NoError(t, err)
+
+			// A stock of the largest size that can never run out disables the reduction.
+			stock[largestPack] = order
+			expected, err := calculator.CalculateWithOptions(context.Background(), order, CalculateOptions{Stock: stock})
+			require.NoError(t, err)
+
+			assert.Equal(t, expected, reduced, "sizes %v, stock %v, order %d", packSizes, stock, order)
+		}
+	}
+}
+
+func TestPackCalculator_CalculateWithOptions_ReducesLargestUnlimitedSize(t *testing.T) {
+	packSets := [][]int{
+		{250, 500, 1000, 2000, 5000},
+		{3, 7, 11},
+		{6, 9, 20},
+		{23, 31, 53},
+	}
+
+	random := rand.New(rand.NewSource(1))
+
+	for _, packSizes := range packSets {
+		calculator := NewPackCalculator(packSizes)
+		largestPack := packSizes[len(packSizes)-1]
+
+		for i := 0; i < 100; i++ {
+			stock := map[int]int{largestPack: random.Intn(6)}
+			order := 1 + random.Intn(40*largestPack)
+
+			reduced, err := calculator.CalculateWithOptions(context.Background(), order, CalculateOptions{Stock: stock})
+			require.NoError(t, err)
+
+			// Stocks of the other sizes that can never run out disable the reduction.
+			for _, size := range packSizes[:len(packSizes)-1] {
+				stock[size] = order
+			}
+			expected, err := calculator.CalculateWithOptions(context.Background(), order, CalculateOptions{Stock: stock})
+			require.NoError(t, err)
+
+			assert.Equal(t, expected, reduced, "sizes %v, stock %v, order %d", packSizes, stock, order)
+		}
+	}
+
+	t.Run("should solve orders above the table size", func(t *testing.T) {
+		calculator := NewPackCalculator([]int{250, 500, 1000, 2000, 5000})
+
+		result, err := calculator.CalculateWithOptions(context.Background(), 40_000_000, CalculateOptions{Stock: map[int]int{5000: 100}})
+		require.NoError(t, err)
+		assert.Equal(t, map[int]int{5000: 100, 2000: 19_750}, result.Packs)
+	})
+}
+
+// bruteForceStockSolution enumerates every combination within the stock and
+// returns the fewest items, then the fewest packs, or -1 items if none fulfills
+// the order. Sizes missing from the stock are unlimited.
+func bruteForceStockSolution(packSizes []int, stock map[int]int, order int) (totalItems, totalPacks int) {
+	searchLimit := order + packSizes[len(packSizes)-1]
+	totalItems, totalPacks = -1, -1
+
+	var enumerate func(index, items, packs int)
+	enumerate = func(index, items, packs int) {
+		if index == len(packSizes) {
+			if items < order {
+				return
+			}
+			if totalItems == -1 || items < totalItems || (items == totalItems && packs < totalPacks) {
+				totalItems, totalPacks = items, packs
+			}
+			return
+		}
+
+		size := packSizes[index]
+		limit, limited := stock[size]
+		for count := 0; items+count*size <= searchLimit && (!limited || count <= limit); count++ {
+			enumerate(index+1, items+count*size, packs+count)
+		}
+	}
+	enumerate(0, 0, 0)
+
+	return totalItems, totalPacks
+}
+
+func BenchmarkCalculateWithOptions_Stock(b *testing.B) {
+	calculator := NewPackCalculator([]int{250, 500, 1000, 2000, 5000})
+	opts := CalculateOptions{Stock: map[int]int{250: 40, 500: 40, 1000: 40, 2000: 40, 5000: 4}}
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_, _ = calculator.CalculateWithOptions(context.Background(), 50001, opts)
+	}
+}
@@ -0,0 +1,572 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"math/bits"
+	"slices"
+	"strings"
+)
+
+// maxStrategyCombinations bounds the combinations visited while ranking the
+// packs of an order with a strategy that is not built in.
+const maxStrategyCombinations = 1 << 21
+
+// maxRankedSizes bounds the pack sizes of strategies ranking distinct sizes, which
+// rank every subset of the sizes.
+const maxRankedSizes = 12
+
+// Strategy ranks the pack combinations that fulfill an order. Only whole packs
+// are shipped and the fewest items always come first, so a strategy decides
+// between combinations of the same total, replacing the "fewest packs" rule.
+type Strategy interface {
+	// Name identifies the strategy, as accepted by ParseStrategy.
+	Name() string
+
+	// Less reports whether combination a ranks before combination b.
+	Less(a, b PackResult) bool
+}
+
+// Key is a criterion of a lexicographic strategy.
+type Key string
+
+// Supported keys of lexicographic strategies.
+const (
+	// KeyPacks prefers fewer packs.
+	KeyPacks Key = "packs"
+
+	// KeyDistinctSizes prefers fewer different pack sizes.
+	KeyDistinctSizes Key = "distinct_sizes"
+
+	// KeyLargerPacks prefers more packs of the largest size, then of the next
+	// largest size and so on.
+	KeyLargerPacks Key = "larger_packs"
+)
+
+// lexicographic ranks combinations by the fewest items, then by each key in turn.
+type lexicographic struct {
+	name string
+	keys []Key
+}
+
+// Built-in strategies.
+var (
+	// FewestPacks ships the fewest items, then the fewest packs. It is the
+	// default strategy.
+	FewestPacks Strategy = lexicographic{name: "fewest_packs", keys: []Key{KeyPacks}}
+
+	// LargerPacks ships the fewest items, then the fewest packs, and prefers
+	// larger packs between combinations that are still tied.
+	LargerPacks Strategy = lexicographic{name: "larger_packs", keys: []Key{KeyPacks, KeyLargerPacks}}
+
+	// FewestDistinctSizes ships the fewest items, then the fewest different pack
+	// sizes, then the fewest packs.
+	FewestDistinctSizes Strategy = lexicographic{name: "fewest_distinct_sizes", keys: []Key{KeyDistinctSizes, KeyPacks}}
+
+	// SurplusOnly ships the fewest items and accepts any combination of packs.
+	SurplusOnly Strategy = lexicographic{name: "surplus_only"}
+)
+
+// strategies lists the built-in strategies by name.
+var strategies = map[string]Strategy{
+	FewestPacks.Name():         FewestPacks,
+	LargerPacks.Name():         LargerPacks,
+	FewestDistinctSizes.Name(): FewestDistinctSizes,
+	SurplusOnly.Name():         SurplusOnly,
+}
+
+// lexicographicPrefix starts the names of custom lexicographic strategies.
+const lexicographicPrefix = "lexicographic:"
+
+// Lexicographic returns a strategy that ranks combinations by the fewest items,
+// then by each key in order.
+func Lexicographic(keys ...Key) (Strategy, error) {
+	names := make([]string, len(keys))
+	for i, key := range keys {
+		switch key {
+		case KeyPacks, KeyDistinctSizes, KeyLargerPacks:
+		default:
+			return nil, fmt.Errorf("%w: unknown key %q", ErrInvalidStrategy, key)
+		}
+		names[i] = string(key)
+	}
+
+	return lexicographic{name: lexicographicPrefix + strings.Join(names, ","), keys: slices.Clone(keys)}, nil
+}
+
+// ParseStrategy returns the strategy with the given name: a built-in strategy
+// or "lexicographic:" followed by comma-separated keys. An empty name selects
+// FewestPacks.
+func ParseStrategy(name string) (Strategy, error) {
+	if name == "" {
+		return FewestPacks, nil
+	}
+
+	if strategy, ok := strategies[name]; ok {
+		return strategy, nil
+	}
+
+	if keys, ok := strings.CutPrefix(name, lexicographicPrefix); ok {
+		var parsed []Key
+		for key := range strings.SplitSeq(keys, ",") {
+			parsed = append(parsed, Key(strings.TrimSpace(key)))
+		}
+		return Lexicographic(parsed...)
+	}
+
+	return nil, fmt.Errorf("%w: %q", ErrInvalidStrategy, name)
+}
+
+// Name implements Strategy.
+func (l lexicographic) Name() string {
+	return l.name
+}
+
+// Less implements Strategy.
+func (l lexicographic) Less(a, b PackResult) bool {
+	if a.TotalItems != b.TotalItems {
+		return a.TotalItems < b.TotalItems
+	}
+
+	for _, key := range l.keys {
+		if order := compareKey(key, a, b); order != 0 {
+			return order < 0
+		}
+	}
+
+	return false
+}
+
+// compareKey returns a negative number when a ranks before b on the key, a
+// positive number when b ranks before a, and zero when they are tied.
+func compareKey(key Key, a, b PackResult) int {
+	switch key {
+	case KeyPacks:
+		return a.GetTotalPackCount() - b.GetTotalPackCount()
+	case KeyDistinctSizes:
+		return a.GetDistinctSizeCount() - b.GetDistinctSizeCount()
+	case KeyLargerPacks:
+		sizes := make([]int, 0, len(a.Packs)+len(b.Packs))
+		for size := range a.Packs {
+			sizes = append(sizes, size)
+		}
+		for size := range b.Packs {
+			sizes = append(sizes, size)
+		}
+		slices.Sort(sizes)
+
+		for i := len(sizes) - 1; i >= 0; i-- {
+			if difference := b.Packs[sizes[i]] - a.Packs[sizes[i]]; difference != 0 {
+				return difference
+			}
+		}
+	}
+
+	return 0
+}
+
+// GetDistinctSizeCount returns the number of different pack sizes in this result.
+func (pr *PackResult) GetDistinctSizeCount() int {
+	distinct := 0
+	for _, quantity := range pr.Packs {
+		if quantity > 0 {
+			distinct++
+		}
+	}
+	return distinct
+}
+
+// Rank replaces the packs of a result returned by the calculator with the
+// combination of the same total that ranks first by the strategy, within the
+// pack size rules, as CalculateOptions.Strategy does. A nil strategy keeps the
+// packs. Failures are reported like CalculateWithOptions.
+func (pc *PackCalculator) Rank(ctx context.Context, result PackResult, strategy Strategy) (PackResult, error) {
+	if strategy == nil {
+		return result, nil
+	}
+
+	ctx, cancel, err := pc.bound(ctx, result.Order)
+	defer cancel()
+	if err != nil {
+		return PackResult{}, err
+	}
+
+	ranked, err := rankPacks(ctx, result, pc.GetRules().limit(result.Order, nil), pc.GetCosts(), strategy)
+	if err != nil {
+		return PackResult{}, err
+	}
+
+	return pc.groupParcels(ranked)
+}
+
+// rankPacks replaces the packs of a result with the combination of the same
+// total that ranks first by the strategy, drawing on the stock when given, and
+// prices them with the costs.
+//
+// Built-in strategies are ranked with rankLexicographic. Other strategies visit
+// every combination summing exactly to the total, larger packs first, and keep
+// the first of equally ranked combinations; orders whose total has more than
+// maxStrategyCombinations combinations then fail with ErrOrderTooLarge.
+func rankPacks(ctx context.Context, result PackResult, stock map[int]int, costs Costs, strategy Strategy) (PackResult, error) {
+	l, lexical := strategy.(lexicographic)
+	if lexical && l.solvedByTable(len(stock) > 0) {
+		return result, nil
+	}
+
+	if result.TotalItems == 0 {
+		return result, nil
+	}
+
+	var best PackResult
+	var found bool
+	var err error
+	if lexical {
+		best, found, err = rankLexicographic(ctx, result, stock, l)
+	} else {
+		best, found, err = walkPacks(ctx, result, stock, strategy)
+	}
+	if err != nil {
+		return PackResult{}, err
+	}
+
+	if !found {
+		return result, nil
+	}
+
+	best.TotalCost = costs.Total(best.Packs)
+	return best, nil
+}
+
+// solvedByTable reports whether every combination the table finds already ranks
+// first, so the packs need not be ranked again. Tables of unlimited sizes break
+// ties between the fewest packs in favor of larger packs; stock tables do not.
+func (l lexicographic) solvedByTable(stocked bool) bool {
+	switch {
+	case len(l.keys) == 0:
+		return true
+	case len(l.keys) == 1:
+		return l.keys[0] == KeyPacks
+	case len(l.keys) == 2:
+		return !stocked && l.keys[0] == KeyPacks && l.keys[1] == KeyLargerPacks
+	default:
+		return false
+	}
+}
+
+// largerFirst reports whether larger packs rank before fewer packs: when the
+// larger_packs key comes first, or when neither key is given and ties are broken
+// in favor of larger packs.
+func (l lexicographic) largerFirst() bool {
+	for _, key := range l.keys {
+		switch key {
+		case KeyPacks:
+			return false
+		case KeyLargerPacks:
+			return true
+		}
+	}
+	return true
+}
+
+// rankLexicographic returns the combination of the result's total that ranks
+// first by the strategy, and false when the stock leaves none.
+//
+// The packs and larger_packs keys add up pack by pack, so the best combination
+// of a set of sizes is found with a dynamic programming table per size, see
+// rankSizes. distinct_sizes does not, so strategies using it rank every subset
+// of the sizes with each of its sizes used at least once and keep the best,
+// stopping at the first subset size that has a combination when it is the
+// first key. Ties are broken in favor of larger packs.
+func rankLexicographic(ctx context.Context, result PackResult, stock map[int]int, l lexicographic) (PackResult, bool, error) {
+	sizes := slices.Compact(slices.Clone(result.PackSizes))
+	distinct := slices.Contains(l.keys, KeyDistinctSizes)
+
+	subsets := []int{1<<len(sizes) - 1}
+	if distinct {
+		if len(sizes) > maxRankedSizes {
+			return PackResult{}, false, fmt.Errorf("%w: distinct sizes are ranked for at most %d pack sizes", ErrOrderTooLarge, maxRankedSizes)
+		}
+
+		subsets = subsets[:0]
+		for subset := 1; subset < 1<<len(sizes); subset++ {
+			subsets = append(subsets, subset)
+		}
+		slices.SortStableFunc(subsets, func(a, b int) int {
+			return bits.OnesCount(uint(a)) - bits.OnesCount(uint(b))
+		})
+	}
+
+	best := PackResult{}
+	found := false
+
+	for _, subset := range subsets {
+		if found && l.keys[0] == KeyDistinctSizes && bits.OnesCount(uint(subset)) > best.GetDistinctSizeCount() {
+			break
+		}
+
+		packs, ok, err := rankSizes(ctx, sizes, subset, stock, result.TotalItems, distinct, l.largerFirst())
+		if err != nil {
+			return PackResult{}, false, err
+		}
+		if !ok {
+			continue
+		}
+
+		candidate := PackResult{
+			Order:      result.Order,
+			TotalItems: result.TotalItems,
+			Packs:      packs,
+			PackSizes:  result.PackSizes,
+		}
+
+		if !found || l.Less(candidate, best) ||
+			(!l.Less(best, candidate) && compareKey(KeyLargerPacks, candidate, best) < 0) {
+			best = candidate
+			found = true
+		}
+	}
+
+	return best, found, nil
+}
+
+// rankSizes returns the best combination summing exactly to total from the sizes
+// in the subset bitmask, within the stock, and false when there is none. When
+// required is set every size of the subset is used at least once. The best
+// combination has the fewest packs, then the most packs of the largest size, of
+// the next largest size and so on; with largerFirst, the pack count is ignored.
+//
+// When the largest size of the subset is unlimited the total is first reduced by
+// the period threshold of the subset like reduceOrder: swapping smaller packs
+// for fewer packs of the largest size improves both rankings, so the best
+// combination holds as many packs of the largest size as the reduction removes.
+// A table of the fewest packs is then built per size, smallest first, and the
+// packs are chosen from the largest size down, each time the most packs that
+// still leave a best combination of the rest.
+func rankSizes(ctx context.Context, sizes []int, subset int, stock map[int]int, total int, required, largerFirst bool) (map[int]int, bool, error) {
+	packs := make(map[int]int)
+	remaining := total
+
+	var used, bounds []int
+	for i, size := range sizes {
+		if subset&(1<<i) == 0 {
+			continue
+		}
+
+		bound := -1
+		if count, limited := stock[size]; limited {
+			bound = count
+		}
+
+		if required {
+			if bound == 0 {
+				return nil, false, nil
+			}
+			packs[size] = 1
+			remaining -= size
+			if bound > 0 {
+				bound--
+			}
+		}
+
+		used = append(used, size)
+		bounds = append(bounds, bound)
+	}
+
+	if remaining < 0 {
+		return nil, false, nil
+	}
+
+	largest := len(used) - 1
+	if bounds[largest] < 0 {
+		residual, largestPacks := reduceOrder(remaining, used)
+		if largestPacks > 0 {
+			packs[used[largest]] += largestPacks
+		}
+		remaining = residual
+	}
+
+	if remaining > maxTableSize {
+		return nil, false, fmt.Errorf("%w: ranking range exceeds %d quantities", ErrOrderTooLarge, maxTableSize)
+	}
+
+	if err := spend(ctx, len(used)*(remaining+1)); err != nil {
+		return nil, false, err
+	}
+	if err := admit(ctx, len(used)*(remaining+1)); err != nil {
+		return nil, false, err
+	}
+
+	tables, err := fewestPacksTables(ctx, used, bounds, remaining)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if tables[largest][remaining] == unreachable {
+		return nil, false, nil
+	}
+
+	quantity := remaining
+	for i := largest; i >= 0; i-- {
+		size := used[i]
+
+		most := quantity / size
+		if bounds[i] >= 0 {
+			most = min(most, bounds[i])
+		}
+
+		for count := most; count >= 0; count-- {
+			rest := quantity - count*size
+
+			previous := unreachable
+			if i > 0 {
+				previous = tables[i-1][rest]
+			} else if rest == 0 {
+				previous = 0
+			}
+
+			if previous == unreachable || (!largerFirst && previous+int32(count) != tables[i][quantity]) {
+				continue
+			}
+
+			if count > 0 {
+				packs[size] += count
+			}
+			quantity = rest
+			break
+		}
+	}
+
+	return packs, true, nil
+}
+
+// fewestPacksTables returns, for each size in turn, the fewest packs of that size
+// and the smaller ones summing to every quantity up to limit, within the bounds;
+// a negative bound is unlimited. Bounded sizes are split into chunks of 1, 2, 4,
+// ... packs taken at most once, like the layers of a stock table.
+func fewestPacksTables(ctx context.Context, sizes, bounds []int, limit int) ([][]int32, error) {
+	tables := make([][]int32, len(sizes))
+	checked := 0
+
+	improve := func(table []int32, quantity, weight int, packs int32) error {
+		checked++
+		if checked%cancellationCheckInterval == 0 {
+			if err := spend(ctx, 0); err != nil {
+				return err
+			}
+		}
+
+		if previous := table[quantity-weight]; previous != unreachable &&
+			(table[quantity] == unreachable || previous+packs < table[quantity]) {
+			table[quantity] = previous + packs
+		}
+		return nil
+	}
+
+	for i, size := range sizes {
+		table := make([]int32, limit+1)
+		if i > 0 {
+			copy(table, tables[i-1])
+		} else {
+			for quantity := 1; quantity <= limit; quantity++ {
+				table[quantity] = unreachable
+			}
+		}
+
+		if bounds[i] < 0 {
+			for quantity := size; quantity <= limit; quantity++ {
+				if err := improve(table, quantity, size, 1); err != nil {
+					return nil, err
+				}
+			}
+		} else {
+			count := min(bounds[i], limit/size)
+			for chunk := 1; count > 0; chunk *= 2 {
+				taken := min(chunk, count)
+				for quantity := limit; quantity >= taken*size; quantity-- {
+					if err := improve(table, quantity, taken*size, int32(taken)); err != nil {
+						return nil, err
+					}
+				}
+				count -= taken
+			}
+		}
+
+		tables[i] = table
+	}
+
+	return tables, nil
+}
+
+// walkPacks visits every combination summing exactly to the result's total,
+// larger packs first, and returns the first that ranks first by the strategy,
+// and false when the stock leaves none.
+func walkPacks(ctx context.Context, result PackResult, stock map[int]int, strategy Strategy) (PackResult, bool, error) {
+	sizes := slices.Compact(slices.Clone(result.PackSizes))
+	counts := make([]int, len(sizes))
+	best := PackResult{}
+	found := false
+	visited := 0
+
+	consider := func() {
+		candidate := PackResult{
+			Order:      result.Order,
+			TotalItems: result.TotalItems,
+			Packs:      make(map[int]int),
+			PackSizes:  result.PackSizes,
+		}
+		for i, count := range counts {
+			if count > 0 {
+				candidate.Packs[sizes[i]] = count
+			}
+		}
+
+		if !found || strategy.Less(candidate, best) {
+			best = candidate
+			found = true
+		}
+	}
+
+	var walk func(index, remaining int) error
+	walk = func(index, remaining int) error {
+		visited++
+		if visited > maxStrategyCombinations {
+			return fmt.Errorf("%w: more than %d combinations to rank", ErrOrderTooLarge, maxStrategyCombinations)
+		}
+		if visited%cancellationCheckInterval == 0 {
+			if err := spend(ctx, cancellationCheckInterval); err != nil {
+				return err
+			}
+		}
+
+		size := sizes[index]
+		most := remaining / size
+		if limit, limited := stock[size]; limited {
+			most = min(most, limit)
+		}
+
+		if index == 0 {
+			if remaining%size == 0 && remaining/size <= most {
+				counts[0] = remaining / size
+				consider()
+				counts[0] = 0
+			}
+			return nil
+		}
+
+		for count := most; count >= 0; count-- {
+			counts[index] = count
+			if err := walk(index-1, remaining-count*size); err != nil {
+				return err
+			}
+		}
+		counts[index] = 0
+
+		return nil
+	}
+
+	if err := walk(len(sizes)-1, result.TotalItems); err != nil {
+		return PackResult{}, false, err
+	}
+
+	return best, found, nil
+}
@@ -0,0 +1,291 @@
+package domain
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStrategy_Less_READMEExamples(t *testing.T) {
+	packs := func(order, totalItems int, packs map[int]int) PackResult {
+		return PackResult{Order: order, TotalItems: totalItems, Packs: packs}
+	}
+
+	// Each example lists combinations for the same order; first and second are
+	// compared in both directions.
+	examples := []struct {
+		name   string
+		first  PackResult
+		second PackResult
+	}{
+		{name: "order 1", first: packs(1, 250, map[int]int{250: 1}), second: packs(1, 500, map[int]int{500: 1})},
+		{name: "order 251", first: packs(251, 500, map[int]int{500: 1}), second: packs(251, 500, map[int]int{250: 2})},
+		{name: "order 501", first: packs(501, 750, map[int]int{500: 1, 250: 1}), second: packs(501, 750, map[int]int{250: 3})},
+		{name: "order 12001", first: packs(12001, 12250, map[int]int{5000: 2, 2000: 1, 250: 1}), second: packs(12001, 12250, map[int]int{2000: 6, 250: 1})},
+		{name: "tied packs", first: packs(1000, 1000, map[int]int{750: 1, 250: 1}), second: packs(1000, 1000, map[int]int{500: 2})},
+	}
+
+	// expected holds, per strategy and example, -1 when first ranks before
+	// second, 1 when second ranks first and 0 when they are tied.
+	tests := []struct {
+		strategy Strategy
+		expected []int
+	}{
+		{strategy: FewestPacks, expected: []int{-1, -1, -1, -1, 0}},
+		{strategy: LargerPacks, expected: []int{-1, -1, -1, -1, -1}},
+		{strategy: FewestDistinctSizes, expected: []int{-1, -1, 1, 1, 1}},
+		{strategy: SurplusOnly, expected: []int{-1, 0, 0, 0, 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.strategy.Name(), func(t *testing.T) {
+			for i, example := range examples {
+				got := 0
+				if tt.strategy.Less(example.first, example.second) {
+					got = -1
+				}
+				if tt.strategy.Less(example.second, example.first) {
+					require.Zero(t, got, "%s ranks both combinations first", example.name)
+					got = 1
+				}
+
+				assert.Equal(t, tt.expected[i], got, example.name)
+			}
+		})
+	}
+}
+
+func TestPackCalculator_CalculateWithOptions_Strategy(t *testing.T) {
+	defaultSizes := []int{250, 500, 1000, 2000, 5000}
+
+	tests := []struct {
+		name          string
+		packSizes     []int
+		strategy      Strategy
+		order         int
+		stock         map[int]int
+		expectedItems int
+		expectedPacks map[int]int
+	}{
+		{
+			name:          "fewest packs",
+			packSizes:     defaultSizes,
+			strategy:      FewestPacks,
+			order:         12001,
+			expectedItems: 12250,
+			expectedPacks: map[int]int{5000: 2, 2000: 1, 250: 1},
+		},
+		{
+			name:          "fewest distinct sizes",
+			packSizes:     defaultSizes,
+			strategy:      FewestDistinctSizes,
+			order:         501,
+			expectedItems: 750,
+			expectedPacks: map[int]int{250: 3},
+		},
+		{
+			name:          "fewest distinct sizes within stock",
+			packSizes:     defaultSizes,
+			strategy:      FewestDistinctSizes,
+			order:         12001,
+			stock:         map[int]int{250: 10},
+			expectedItems: 12250,
+			expectedPacks: map[int]int{2000: 6, 250: 1},
+		},
+		{
+			name:          "larger packs on ties",
+			packSizes:     []int{250, 500, 750},
+			strategy:      LargerPacks,
+			order:         1000,
+			expectedItems: 1000,
+			expectedPacks: map[int]int{750: 1, 250: 1},
+		},
+		{
+			name:          "custom keys",
+			packSizes:     []int{250, 500, 750},
+			strategy:      mustParseStrategy(t, "lexicographic:distinct_sizes,larger_packs"),
+			order:         1000,
+			expectedItems: 1000,
+			expectedPacks: map[int]int{500: 2},
+		},
+		{
+			name:          "empty order",
+			packSizes:     defaultSizes,
+			strategy:      FewestDistinctSizes,
+			order:         0,
+			expectedItems: 0,
+			expectedPacks: map[int]int{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calculator := NewPackCalculator(tt.packSizes)
+
+			result, err := calculator.CalculateWithOptions(context.Background(), tt.order, CalculateOptions{
+				Stock:    tt.stock,
+				Strategy: tt.strategy,
+			})
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedItems, result.TotalItems)
+			assert.Equal(t, tt.expectedPacks, result.Packs)
+		})
+	}
+
+	t.Run("should ship the fewest items with any strategy", func(t *testing.T) {
+		calculator := NewPackCalculator(defaultSizes)
+
+		result, err := calculator.CalculateWithOptions(context.Background(), 12001, CalculateOptions{Strategy: SurplusOnly})
+
+		require.NoError(t, err)
+		assert.Equal(t, 12250, result.TotalItems)
+	})
+
+	t.Run("should price the ranked packs", func(t *testing.T) {
+		calculator := NewPackCalculator(defaultSizes)
+		calculator.UpdateCosts(Costs{PerPack: map[int]int{250: 10, 500: 100}})
+
+		result, err := calculator.CalculateWithOptions(context.Background(), 501, CalculateOptions{Strategy: FewestDistinctSizes})
+
+		require.NoError(t, err)
+		assert.Equal(t, 30, result.TotalCost)
+	})
+
+	t.Run("should reject strategies with cost-aware objectives", func(t *testing.T) {
+		calculator := NewPackCalculator(defaultSizes)
+
+		_, err := calculator.CalculateWithOptions(context.Background(), 1, CalculateOptions{
+			Objective: ObjectiveMinCost,
+			Strategy:  FewestDistinctSizes,
+		})
+
+		assert.ErrorIs(t, err, ErrInvalidStrategy)
+	})
+
+	t.Run("should rank large orders with every strategy", func(t *testing.T) {
+		calculator := NewPackCalculator(defaultSizes)
+
+		for _, strategy := range []Strategy{LargerPacks, FewestDistinctSizes, mustParseStrategy(t, "lexicographic:larger_packs")} {
+			result, err := calculator.CalculateWithOptions(context.Background(), 1_002_000, CalculateOptions{Strategy: strategy})
+
+			require.NoError(t, err, strategy.Name())
+			assert.Equal(t, 1_002_000, result.TotalItems, strategy.Name())
+		}
+
+		result, err := calculator.CalculateWithOptions(context.Background(), 1_002_000, CalculateOptions{Strategy: FewestDistinctSizes})
+		require.NoError(t, err)
+		assert.Equal(t, map[int]int{2000: 501}, result.Packs)
+	})
+
+	t.Run("should answer large orders with the default strategy", func(t *testing.T) {
+		calculator := NewPackCalculator(defaultSizes)
+
+		result, err := calculator.CalculateWithOptions(context.Background(), 1_000_000_000, CalculateOptions{Strategy: FewestPacks})
+
+		require.NoError(t, err)
+		assert.Equal(t, 200000, result.Packs[5000])
+	})
+}
+
+func TestRankPacks_MatchesExhaustiveWalk(t *testing.T) {
+	strategies := []Strategy{
+		LargerPacks,
+		FewestDistinctSizes,
+		mustParseStrategy(t, "lexicographic:larger_packs"),
+		mustParseStrategy(t, "lexicographic:distinct_sizes"),
+		mustParseStrategy(t, "lexicographic:packs,distinct_sizes"),
+		mustParseStrategy(t, "lexicographic:larger_packs,distinct_sizes"),
+	}
+
+	tests := []struct {
+		name      string
+		packSizes []int
+		stock     map[int]int
+	}{
+		{name: "default sizes", packSizes: []int{250, 500, 1000, 2000, 5000}},
+		{name: "coprime sizes", packSizes: []int{3, 7, 10, 12}},
+		{name: "limited stock", packSizes: []int{3, 7, 10, 12}, stock: map[int]int{12: 2, 7: 3}},
+		{name: "limited largest size", packSizes: []int{2, 5, 9}, stock: map[int]int{9: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step := tt.packSizes[0]
+			for total := step; total <= 60*step; total += step {
+				result := PackResult{Order: total, TotalItems: total, PackSizes: tt.packSizes}
+
+				for _, strategy := range strategies {
+					expected, expectedFound, err := walkPacks(context.Background(), result, tt.stock, strategy)
+					require.NoError(t, err)
+
+					got, found, err := rankLexicographic(context.Background(), result, tt.stock, strategy.(lexicographic))
+					require.NoError(t, err)
+
+					require.Equal(t, expectedFound, found, "%s at %d", strategy.Name(), total)
+					if found {
+						require.Equal(t, expected.Packs, got.Packs, "%s at %d", strategy.Name(), total)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestLargerPacks_SolvedByTable(t *testing.T) {
+	for _, packSizes := range [][]int{{250, 500, 1000, 2000, 5000}, {3, 7, 10, 12}, {250, 500, 750}} {
+		calculator := NewPackCalculator(packSizes)
+
+		for order := 1; order <= 60*packSizes[0]; order += packSizes[0] / 3 {
+			result, err := calculator.CalculateContext(context.Background(), order)
+			require.NoError(t, err)
+
+			expected, found, err := walkPacks(context.Background(), result, nil, LargerPacks)
+			require.NoError(t, err)
+			require.True(t, found)
+			require.Equal(t, expected.Packs, result.Packs, "%v at %d", packSizes, order)
+		}
+	}
+}
+
+func TestParseStrategy(t *testing.T) {
+	tests := []struct {
+		name         string
+		input        string
+		expectedName string
+		expectedErr  error
+	}{
+		{name: "empty name", input: "", expectedName: "fewest_packs"},
+		{name: "fewest packs", input: "fewest_packs", expectedName: "fewest_packs"},
+		{name: "larger packs", input: "larger_packs", expectedName: "larger_packs"},
+		{name: "fewest distinct sizes", input: "fewest_distinct_sizes", expectedName: "fewest_distinct_sizes"},
+		{name: "surplus only", input: "surplus_only", expectedName: "surplus_only"},
+		{name: "lexicographic", input: "lexicographic:distinct_sizes, packs", expectedName: "lexicographic:distinct_sizes,packs"},
+		{name: "unknown key", input: "lexicographic:weight", expectedErr: ErrInvalidStrategy},
+		{name: "unknown strategy", input: "cheapest", expectedErr: ErrInvalidStrategy},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			strategy, err := ParseStrategy(tt.input)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedName, strategy.Name())
+		})
+	}
+}
+
+func mustParseStrategy(t *testing.T, name string) Strategy {
+	t.Helper()
+
+	strategy, err := ParseStrategy(name)
+	require.NoError(t, err)
+	return strategy
+}
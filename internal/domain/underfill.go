@@ -0,0 +1,101 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+)
+
+// Mode selects which side of the order a calculation ships.
+type Mode string
+
+// Supported modes. The zero value is ModeFulfil.
+const (
+	// ModeFulfil ships at least the order.
+	ModeFulfil Mode = "fulfil"
+
+	// ModeUnderFulfil ships the most items that do not exceed the order, then
+	// the fewest packs, leaving the rest as a shortfall.
+	ModeUnderFulfil Mode = "under_fulfil"
+)
+
+// valid reports whether the mode is one of the supported modes.
+func (m Mode) valid() bool {
+	switch m {
+	case "", ModeFulfil, ModeUnderFulfil:
+		return true
+	default:
+		return false
+	}
+}
+
+// reduceUnderOrder splits an order into a number of largest packs and a residual
+// order for ModeUnderFulfil, like reduceOrder.
+//
+// The best total not exceeding an order is above order minus the largest pack L,
+// since adding a pack of size L to any smaller total still does not exceed it.
+// The whole search range is therefore kept above the period threshold, where
+// removing the same number of largest packs maps every solution to the residual's.
+func reduceUnderOrder(order int, packSizes []int) (residualOrder, largestPacks int) {
+	threshold := periodThreshold(packSizes)
+	largestPack := packSizes[len(packSizes)-1]
+
+	if order-largestPack < threshold {
+		return order, 0
+	}
+
+	largestPacks = (order - largestPack - threshold) / largestPack
+
+	return order - largestPacks*largestPack, largestPacks
+}
+
+// calculateUnderTable computes the best combination not exceeding the order
+// from the dynamic programming table, ignoring the pack size rules.
+func (pc *PackCalculator) calculateUnderTable(ctx context.Context, order int) (PackResult, error) {
+	packSizes, costs := pc.configuration()
+
+	if order < 0 {
+		return PackResult{}, ErrInvalidOrder
+	}
+
+	if order == 0 {
+		return PackResult{
+			Order:      order,
+			TotalItems: 0,
+			Packs:      make(map[int]int),
+			PackSizes:  packSizes,
+		}, nil
+	}
+
+	if len(packSizes) == 0 {
+		return PackResult{}, ErrNoPackSizes
+	}
+
+	residualOrder, largestPacks := reduceUnderOrder(order, packSizes)
+	if residualOrder > maxTableSize {
+		return PackResult{}, fmt.Errorf("%w: search range exceeds %d quantities", ErrOrderTooLarge, maxTableSize)
+	}
+
+	table, err := pc.tableFor(ctx, packSizes, residualOrder)
+	if err != nil {
+		return PackResult{}, err
+	}
+
+	// Zero is always reachable, so the search ends there at the latest.
+	quantity := residualOrder
+	for !table.reachable(quantity) {
+		quantity--
+	}
+
+	packs := table.packs(quantity)
+	if largestPacks > 0 {
+		packs[packSizes[len(packSizes)-1]] += largestPacks
+	}
+
+	return PackResult{
+		Order:      order,
+		TotalItems: quantity + largestPacks*packSizes[len(packSizes)-1],
+		Packs:      packs,
+		PackSizes:  packSizes,
+		TotalCost:  costs.Total(packs),
+	}, nil
+}
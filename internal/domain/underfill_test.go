@@ -0,0 +1,151 @@
+package domain
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPackCalculator_CalculateWithOptions_UnderFulfil(t *testing.T) {
+	calculator := NewPackCalculator([]int{250, 500, 1000, 2000, 5000})
+
+	tests := []struct {
+		name              string
+		order             int
+		stock             map[int]int
+		expectedItems     int
+		expectedPacks     map[int]int
+		expectedShortfall int
+	}{
+		{
+			name:              "should ship the most items not exceeding the order",
+			order:             12001,
+			expectedItems:     12000,
+			expectedPacks:     map[int]int{5000: 2, 2000: 1},
+			expectedShortfall: 1,
+		},
+		{
+			name:          "should ship exact orders in full",
+			order:         750,
+			expectedItems: 750,
+			expectedPacks: map[int]int{500: 1, 250: 1},
+		},
+		{
+			name:              "should ship nothing when no pack fits",
+			order:             249,
+			expectedItems:     0,
+			expectedPacks:     map[int]int{},
+			expectedShortfall: 249,
+		},
+		{
+			name:              "should draw on the stock only",
+			order:             12001,
+			stock:             map[int]int{250: 0, 500: 1, 1000: 0, 2000: 1, 5000: 1},
+			expectedItems:     7500,
+			expectedPacks:     map[int]int{5000: 1, 2000: 1, 500: 1},
+			expectedShortfall: 4501,
+		},
+		{
+			name:              "should ship nothing from an empty stock",
+			order:             1000,
+			stock:             map[int]int{250: 0, 500: 0, 1000: 0, 2000: 0, 5000: 0},
+			expectedItems:     0,
+			expectedPacks:     map[int]int{},
+			expectedShortfall: 1000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := calculator.CalculateWithOptions(context.Background(), tt.order, CalculateOptions{Stock: tt.stock, Mode: ModeUnderFulfil})
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.order, result.Order)
+			assert.Equal(t, tt.expectedItems, result.TotalItems)
+			assert.Equal(t, tt.expectedPacks, result.Packs)
+			assert.Equal(t, tt.expectedShortfall, result.GetShortfall())
+			assert.Zero(t, result.GetSurplus())
+		})
+	}
+
+	t.Run("should solve large orders with the periodicity reduction", func(t *testing.T) {
+		result, err := calculator.CalculateWithOptions(context.Background(), 1_000_000_001, CalculateOptions{Mode: ModeUnderFulfil})
+		require.NoError(t, err)
+		assert.Equal(t, 1_000_000_000, result.TotalItems)
+		assert.Equal(t, map[int]int{5000: 200_000}, result.Packs)
+	})
+
+	t.Run("should reject cost-aware objectives", func(t *testing.T) {
+		_, err := calculator.CalculateWithOptions(context.Background(), 1, CalculateOptions{Mode: ModeUnderFulfil, Objective: ObjectiveMinCost})
+		assert.ErrorIs(t, err, ErrInvalidMode)
+	})
+
+	t.Run("should reject unknown modes", func(t *testing.T) {
+		_, err := calculator.CalculateWithOptions(context.Background(), 1, CalculateOptions{Mode: "overflow"})
+		assert.ErrorIs(t, err, ErrInvalidMode)
+	})
+
+	t.Run("should not rank alternatives", func(t *testing.T) {
+		_, err := calculator.Alternatives(context.Background(), 1, CalculateOptions{Mode: ModeUnderFulfil}, 1)
+		assert.ErrorIs(t, err, ErrInvalidMode)
+	})
+}
+
+func TestPackCalculator_CalculateWithOptions_UnderFulfilMatchesReference(t *testing.T) {
+	packSets := [][]int{
+		{250, 500, 1000, 2000, 5000},
+		{3, 7, 11},
+		{6, 9, 20},
+		{23, 31, 53},
+	}
+
+	random := rand.New(rand.NewSource(1))
+
+	for _, packSizes := range packSets {
+		calculator := NewPackCalculator(packSizes)
+		largestPack := packSizes[len(packSizes)-1]
+		maxOrder := periodThreshold(packSizes) + 4*largestPack
+		packCounts := referencePackCounts(packSizes, maxOrder)
+
+		for i := 0; i < 200; i++ {
+			order := random.Intn(maxOrder + 1)
+
+			expectedItems := order
+			for packCounts[expectedItems] == -1 {
+				expectedItems--
+			}
+
+			result, err := calculator.CalculateWithOptions(context.Background(), order, CalculateOptions{Mode: ModeUnderFulfil})
+			require.NoError(t, err, "sizes %v, order %d", packSizes, order)
+			assert.Equal(t, expectedItems, result.TotalItems, "sizes %v, order %d", packSizes, order)
+			assert.Equal(t, packCounts[expectedItems], result.GetTotalPackCount(), "sizes %v, order %d", packSizes, order)
+
+			stocked, err := calculator.CalculateWithOptions(context.Background(), order, CalculateOptions{
+				Stock: map[int]int{packSizes[0]: order},
+				Mode:  ModeUnderFulfil,
+			})
+			require.NoError(t, err, "sizes %v, order %d", packSizes, order)
+			assert.Equal(t, expectedItems, stocked.TotalItems, "sizes %v, order %d", packSizes, order)
+			assert.Equal(t, packCounts[expectedItems], stocked.GetTotalPackCount(), "sizes %v, order %d", packSizes, order)
+		}
+	}
+}
+
+// referencePackCounts returns the fewest packs summing exactly to every quantity
+// up to limit, or -1 for unreachable quantities, without any reduction.
+func referencePackCounts(packSizes []int, limit int) []int {
+	packCounts := make([]int, limit+1)
+	for quantity := 1; quantity <= limit; quantity++ {
+		packCounts[quantity] = -1
+		for _, size := range packSizes {
+			if size <= quantity && packCounts[quantity-size] != -1 &&
+				(packCounts[quantity] == -1 || packCounts[quantity-size]+1 < packCounts[quantity]) {
+				packCounts[quantity] = packCounts[quantity-size] + 1
+			}
+		}
+	}
+	return packCounts
+}
@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime"
+
+	"github.com/luisfernandomoraes/order-packing-api/internal/domain"
+	"github.com/luisfernandomoraes/order-packing-api/internal/response"
+)
+
+// maxBatchOrders is the maximum number of orders accepted in a single batch request
+const maxBatchOrders = 1000
+
+// BatchCalculateHandler handles the /api/calculate/batch endpoint
+type BatchCalculateHandler struct {
+	calculator *domain.PackCalculator
+	workers    int
+	strategy   domain.Strategy
+}
+
+// BatchOption configures a BatchCalculateHandler
+type BatchOption func(*BatchCalculateHandler)
+
+// WithBatchStrategy ranks the packs of every order with the given strategy
+// instead of the fewest packs
+func WithBatchStrategy(strategy domain.Strategy) BatchOption {
+	return func(h *BatchCalculateHandler) {
+		h.strategy = strategy
+	}
+}
+
+// NewBatchCalculateHandler creates a new BatchCalculateHandler that resolves orders
+// with one worker per available CPU
+func NewBatchCalculateHandler(calculator *domain.PackCalculator, opts ...BatchOption) *BatchCalculateHandler {
+	h := &BatchCalculateHandler{
+		calculator: calculator,
+		workers:    runtime.GOMAXPROCS(0),
+	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
+}
+
+// BatchOrder represents a single order within a batch request
+type BatchOrder struct {
+	ReferenceID string `json:"reference_id,omitempty" example:"PO-1001"`
+	Order       int    `json:"order" example:"501" minimum:"0"`
+}
+
+// BatchCalculateRequest represents the request body for the batch calculate endpoint
+type BatchCalculateRequest struct {
+	Orders []BatchOrder `json:"orders"`
+}
+
+// BatchCalculateItem represents the outcome of a single order in a batch.
+// Exactly one of Result and Error is set.
+type BatchCalculateItem struct {
+	ReferenceID string             `json:"reference_id,omitempty" example:"PO-1001"`
+	Result      *CalculateResponse `json:"result,omitempty"`
+	Error       string             `json:"error,omitempty" example:"Order must be positive"`
+}
+
+// BatchCalculateResponse represents the response from the batch calculate endpoint
+type BatchCalculateResponse struct {
+	Results   []BatchCalculateItem `json:"results"`
+	Succeeded int                  `json:"succeeded" example:"2"`
+	Failed    int                  `json:"failed" example:"0"`
+}
+
+// Handle godoc
+// @Summary Calculate optimal package combinations for many orders
+// @Description Calculates the best package combination for every order in the batch. Orders are computed concurrently from a shared calculation table and each one reports its own result or error, in the same order as the request.
+// @Description When the server is saturated and the batch cannot be admitted, the whole batch fails with 503 and Retry-After.
+// @Tags calculate
+// @Accept json
+// @Produce json
+// @Param request body BatchCalculateRequest true "Orders to calculate"
+// @Success 200 {object} BatchCalculateResponse
+// @Failure 400 {object} map[string]string "Bad Request - Invalid body, empty or oversized batch"
+// @Failure 405 {object} map[string]string "Method Not Allowed"
+// @Failure 503 {object} map[string]string "Service Unavailable - Server saturated (see Retry-After)"
+// @Router /api/calculate/batch [post]
+func (h *BatchCalculateHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		response.Error(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req BatchCalculateRequest
+
+	if err := response.DecodeJSON(r, &req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if len(req.Orders) == 0 {
+		response.Error(w, http.StatusBadRequest, "Orders cannot be empty")
+		return
+	}
+
+	if len(req.Orders) > maxBatchOrders {
+		response.Error(w, http.StatusBadRequest, fmt.Sprintf("Batch cannot contain more than %d orders", maxBatchOrders))
+		return
+	}
+
+	orders := make([]int, len(req.Orders))
+	for i, item := range req.Orders {
+		orders[i] = item.Order
+	}
+
+	ctx, cancel := h.calculator.Budget(r.Context())
+	defer cancel()
+
+	results := h.calculator.CalculateBatch(ctx, orders, h.workers)
+
+	responseData := BatchCalculateResponse{
+		Results: make([]BatchCalculateItem, len(results)),
+	}
+
+	for i, result := range results {
+		item := BatchCalculateItem{ReferenceID: req.Orders[i].ReferenceID}
+
+		ranked, err := result.Result, result.Err
+		if err == nil {
+			ranked, err = h.calculator.Rank(ctx, ranked, h.strategy)
+		}
+
+		if err != nil {
+			status, message := calculationError(err)
+			if errors.Is(err, domain.ErrSaturated) {
+				setRetryAfter(w, err)
+				response.ErrorWithCode(w, status, calculationErrorCode(err), message)
+				return
+			}
+
+			item.Error = message
+			responseData.Failed++
+		} else {
+			calculated := newCalculateResponse(ranked)
+			item.Result = &calculated
+			responseData.Succeeded++
+		}
+
+		responseData.Results[i] = item
+	}
+
+	response.JSON(w, http.StatusOK, responseData)
+}
@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/luisfernandomoraes/order-packing-api/internal/domain"
+)
+
+func TestNewBatchCalculateHandler(t *testing.T) {
+	calculator := domain.NewPackCalculator([]int{250, 500, 1000})
+	handler := NewBatchCalculateHandler(calculator)
+	assert.NotNil(t, handler)
+	assert.Positive(t, handler.workers)
+}
+
+func TestBatchCalculateHandler_Handle_MethodRouting(t *testing.T) {
+	for _, method := range []string{http.MethodGet, http.MethodPut, http.MethodDelete, http.MethodPatch} {
+		t.Run("should reject "+method+" method", func(t *testing.T) {
+			handler := NewBatchCalculateHandler(domain.NewPackCalculator([]int{250, 500, 1000}))
+			req := httptest.NewRequest(method, "/calculate/batch", nil)
+			w := httptest.NewRecorder()
+
+			handler.Handle(w, req)
+
+			assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+		})
+	}
+}
+
+func TestBatchCalculateHandler_HandlePost(t *testing.T) {
+	t.Run("should calculate every order and keep reference IDs", func(t *testing.T) {
+		handler := NewBatchCalculateHandler(domain.NewPackCalculator([]int{250, 500, 1000, 2000, 5000}))
+
+		body := `{"orders": [
+			{"reference_id": "PO-1", "order": 501},
+			{"order": 12001},
+			{"reference_id": "PO-3", "order": -1}
+		]}`
+		req := httptest.NewRequest(http.MethodPost, "/calculate/batch", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		handler.Handle(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response BatchCalculateResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+
+		require.Len(t, response.Results, 3)
+		assert.Equal(t, 2, response.Succeeded)
+		assert.Equal(t, 1, response.Failed)
+
+		assert.Equal(t, "PO-1", response.Results[0].ReferenceID)
+		require.NotNil(t, response.Results[0].Result)
+		assert.Equal(t, 750, response.Results[0].Result.TotalItems)
+		assert.Equal(t, 249, response.Results[0].Result.Surplus)
+		assert.Empty(t, response.Results[0].Error)
+
+		assert.Empty(t, response.Results[1].ReferenceID)
+		require.NotNil(t, response.Results[1].Result)
+		assert.Equal(t, 12250, response.Results[1].Result.TotalItems)
+		assert.Equal(t, 4, response.Results[1].Result.TotalPacks)
+
+		assert.Equal(t, "PO-3", response.Results[2].ReferenceID)
+		assert.Nil(t, response.Results[2].Result)
+		assert.Equal(t, "Order must be positive", response.Results[2].Error)
+	})
+
+	t.Run("should answer 503 when the batch cannot be admitted", func(t *testing.T) {
+		handler := NewBatchCalculateHandler(saturatedCalculator(t))
+
+		req := httptest.NewRequest(http.MethodPost, "/calculate/batch", bytes.NewBufferString(`{"orders": [{"order": 501}, {"order": 12001}]}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		handler.Handle(w, req)
+
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Equal(t, "1", w.Header().Get("Retry-After"))
+
+		var errorResponse map[string]string
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&errorResponse))
+		assert.Equal(t, "saturated", errorResponse["code"])
+	})
+
+	t.Run("should rank with the handler's strategy", func(t *testing.T) {
+		handler := NewBatchCalculateHandler(domain.NewPackCalculator([]int{250, 500, 1000}), WithBatchStrategy(domain.FewestDistinctSizes))
+
+		req := httptest.NewRequest(http.MethodPost, "/calculate/batch", bytes.NewBufferString(`{"orders": [{"order": 501}]}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		handler.Handle(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response BatchCalculateResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+		require.Len(t, response.Results, 1)
+		require.NotNil(t, response.Results[0].Result)
+		assert.Equal(t, map[int]int{250: 3}, response.Results[0].Result.Packs)
+	})
+
+	t.Run("should group the packs into parcels", func(t *testing.T) {
+		calculator := domain.NewPackCalculator([]int{250, 500})
+		calculator.UpdateParcelSpec(domain.ParcelSpec{Weights: map[int]int{250: 3000, 500: 5500}, MaxWeight: 6000})
+		handler := NewBatchCalculateHandler(calculator)
+
+		req := httptest.NewRequest(http.MethodPost, "/calculate/batch", bytes.NewBufferString(`{"orders": [{"order": 751}]}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		handler.Handle(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response BatchCalculateResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+		require.Len(t, response.Results, 1)
+		require.NotNil(t, response.Results[0].Result)
+		assert.Equal(t, []ParcelResponse{{Count: 2, Packs: map[int]int{500: 1}, Weight: 5500}}, response.Results[0].Result.Parcels)
+		assert.Equal(t, 2, response.Results[0].Result.TotalParcels)
+	})
+
+	tests := []struct {
+		name          string
+		body          string
+		expectedError string
+	}{
+		{
+			name:          "should reject malformed JSON",
+			body:          "{invalid json}",
+			expectedError: "Invalid request body",
+		},
+		{
+			name:          "should reject empty batch",
+			body:          `{"orders": []}`,
+			expectedError: "Orders cannot be empty",
+		},
+		{
+			name:          "should reject oversized batch",
+			body:          `{"orders": [` + strings.Repeat(`{"order": 1},`, maxBatchOrders) + `{"order": 1}]}`,
+			expectedError: fmt.Sprintf("Batch cannot contain more than %d orders", maxBatchOrders),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewBatchCalculateHandler(domain.NewPackCalculator([]int{250, 500, 1000}))
+			req := httptest.NewRequest(http.MethodPost, "/calculate/batch", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			handler.Handle(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+
+			var errorResponse map[string]string
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&errorResponse))
+			assert.Equal(t, tt.expectedError, errorResponse["error"])
+		})
+	}
+}
+
+// saturatedCalculator returns a calculator whose limiter is used up by another
+// calculation until the test ends, so that it rejects new tables at once.
+func saturatedCalculator(t *testing.T) *domain.PackCalculator {
+	limiter := domain.NewLimiter(1000, 0)
+	calculator := domain.NewPackCalculator([]int{250, 500, 1000, 2000, 5000}, domain.WithLimits(domain.Limits{Limiter: limiter}))
+	other := domain.NewPackCalculator([]int{997, 1009, 4999}, domain.WithLimits(calculator.GetLimits()))
+
+	ctx, cancel := other.Budget(context.Background())
+	t.Cleanup(cancel)
+
+	_, err := other.CalculateContext(ctx, 100_000)
+	require.NoError(t, err)
+	require.Equal(t, int64(1000), limiter.Stats().InUse)
+
+	return calculator
+}
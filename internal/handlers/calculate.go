@@ -2,27 +2,126 @@
 package handlers
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
 	"net/http"
+	"strconv"
 
 	"github.com/luisfernandomoraes/order-packing-api/internal/domain"
 	"github.com/luisfernandomoraes/order-packing-api/internal/response"
 )
 
+// errInventoryConflict is returned when a request asks to plan from the
+// inventory together with a SKU or an explicit stock
+var errInventoryConflict = errors.New("inventory planning cannot be combined with sku or stock")
+
+// errAlternativesWithReserve is returned when a request asks for alternatives to
+// the packs it reserves
+var errAlternativesWithReserve = errors.New("alternatives cannot be combined with reserve")
+
+// errSurplusConflict is returned when a request asks for an exact fit and a
+// maximum surplus at once
+var errSurplusConflict = errors.New("exact cannot be combined with max_surplus")
+
+// errFallbackWithoutMaxSurplus is returned when a request asks to fall back
+// without a maximum surplus to fall back from
+var errFallbackWithoutMaxSurplus = errors.New("fallback requires max_surplus")
+
+// errUnderFulfilConflict is returned when a request asks to under-fulfil the
+// order together with a surplus limit or alternatives
+var errUnderFulfilConflict = errors.New("under_fulfil cannot be combined with exact, max_surplus or alternatives")
+
+// errExplainUnsupported is returned when a request asks to explain a calculation
+// that is not answered by the dynamic programming table alone
+var errExplainUnsupported = errors.New("explain is not supported for this calculation")
+
+// errInvalidAlternatives is returned for alternative counts out of range
+var errInvalidAlternatives = errors.New("invalid number of alternatives")
+
+// maxAlternatives caps the alternatives returned for a single calculation
+const maxAlternatives = 10
+
+// errNoInventory is returned when a request asks to plan from the inventory but
+// the handler has none
+var errNoInventory = errors.New("no inventory configured")
+
 // CalculateHandler handles the /api/calculate endpoint
 type CalculateHandler struct {
 	calculator *domain.PackCalculator
+	catalog    *domain.Catalog
+	inventory  *domain.Inventory
+	strategy   domain.Strategy
+}
+
+// CalculateOption configures a CalculateHandler
+type CalculateOption func(*CalculateHandler)
+
+// WithCatalog lets requests name a product SKU to calculate with that product's pack sizes
+func WithCatalog(catalog *domain.Catalog) CalculateOption {
+	return func(h *CalculateHandler) {
+		h.catalog = catalog
+	}
+}
+
+// WithInventory lets requests plan from the unreserved packs of the inventory and
+// reserve the packs of the result
+func WithInventory(inventory *domain.Inventory) CalculateOption {
+	return func(h *CalculateHandler) {
+		h.inventory = inventory
+	}
+}
+
+// WithStrategy ranks the packs of requests that name no strategy with the given
+// strategy instead of the fewest packs
+func WithStrategy(strategy domain.Strategy) CalculateOption {
+	return func(h *CalculateHandler) {
+		h.strategy = strategy
+	}
 }
 
 // NewCalculateHandler creates a new CalculateHandler
-func NewCalculateHandler(calculator *domain.PackCalculator) *CalculateHandler {
-	return &CalculateHandler{
+func NewCalculateHandler(calculator *domain.PackCalculator, opts ...CalculateOption) *CalculateHandler {
+	h := &CalculateHandler{
 		calculator: calculator,
 	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
 }
 
-// CalculateRequest represents the request body for calculate endpoint
+// CalculateRequest represents the request body for calculate endpoint.
+// Without a SKU the order is calculated with the global pack sizes. Stock limits
+// the packs available per pack size; sizes missing from it are unlimited.
+// UseInventory plans from the unreserved packs of the inventory instead, and
+// Reserve also reserves the packs of the result. Objective selects what the
+// calculation optimizes and defaults to min_items_then_packs; with that objective
+// Strategy ranks combinations of the fewest items in place of the fewest packs.
+// Alternatives asks for up to that many next best combinations, omitted when
+// there are too many combinations to rank within the limits, and Explain for
+// a trace of how the result was chosen. Exact refuses any surplus and MaxSurplus
+// caps it; with Fallback a result above MaxSurplus is returned anyway and flagged.
+// Mode under_fulfil ships the most items not exceeding the order instead and
+// reports the rest as a shortfall.
 type CalculateRequest struct {
-	Order int `json:"order" example:"501" minimum:"0"`
+	Order        int         `json:"order" example:"501" minimum:"0"`
+	SKU          string      `json:"sku,omitempty" example:"WIDGET-01"`
+	Stock        map[int]int `json:"stock,omitempty" example:"5000:1,2000:3"`
+	UseInventory bool        `json:"use_inventory,omitempty" example:"false"`
+	Reserve      bool        `json:"reserve,omitempty" example:"false"`
+	Objective    string      `json:"objective,omitempty" example:"min_items_then_packs" enums:"min_items_then_packs,min_cost,min_items_then_cost"`
+	Strategy     string      `json:"strategy,omitempty" example:"fewest_distinct_sizes"`
+	Alternatives int         `json:"alternatives,omitempty" example:"2" minimum:"0" maximum:"10"`
+	Explain      bool        `json:"explain,omitempty" example:"false"`
+	Exact        bool        `json:"exact,omitempty" example:"false"`
+	MaxSurplus   *int        `json:"max_surplus,omitempty" example:"250" minimum:"0"`
+	Fallback     bool        `json:"fallback,omitempty" example:"false"`
+	Mode         string      `json:"mode,omitempty" example:"fulfil" enums:"fulfil,under_fulfil"`
 }
 
 // CalculateResponse represents the response from calculate endpoint
@@ -32,19 +131,84 @@ type CalculateResponse struct {
 	Packs      map[int]int `json:"packs" example:"250:1,500:1"`
 	PackSizes  []int       `json:"pack_sizes" example:"250,500,1000,2000,5000"`
 	Surplus    int         `json:"surplus" example:"249"`
+	Shortfall  int         `json:"shortfall" example:"0"`
+	TotalPacks int         `json:"total_packs" example:"2"`
+	TotalCost  int         `json:"total_cost" example:"320"`
+
+	Parcels         []ParcelResponse      `json:"parcels,omitempty"`
+	TotalParcels    int                   `json:"total_parcels,omitempty" example:"1"`
+	SurplusExceeded bool                  `json:"surplus_exceeded,omitempty" example:"false"`
+	ReservationID   string                `json:"reservation_id,omitempty" example:"rsv-1"`
+	Alternatives    []AlternativeResponse `json:"alternatives,omitempty"`
+	Explanation     *ExplanationResponse  `json:"explanation,omitempty"`
+}
+
+// ParcelResponse represents Count identical shipping parcels holding the same
+// packs. Weight is in grams and Volume in cubic centimetres, per parcel.
+type ParcelResponse struct {
+	Count  int         `json:"count" example:"1"`
+	Packs  map[int]int `json:"packs" example:"250:1,500:1"`
+	Weight int         `json:"weight" example:"8500"`
+	Volume int         `json:"volume" example:"11500"`
+}
+
+// ExplanationResponse describes how the result of a calculation was chosen.
+// NearestBelow and NearestAbove are 0 when there is no such total.
+type ExplanationResponse struct {
+	SearchFrom        int                          `json:"search_from" example:"12001"`
+	SearchTo          int                          `json:"search_to" example:"17001"`
+	LargestPacksAdded int                          `json:"largest_packs_added" example:"0"`
+	NearestBelow      int                          `json:"nearest_below" example:"12000"`
+	NearestAbove      int                          `json:"nearest_above" example:"12500"`
+	TieRule           string                       `json:"tie_rule" example:"fewest items, then fewest packs, then larger packs"`
+	Candidates        []ExplainedCandidateResponse `json:"candidates"`
+}
+
+// ExplainedCandidateResponse represents a combination considered by an explanation
+type ExplainedCandidateResponse struct {
+	TotalItems int         `json:"total_items" example:"12250"`
+	Packs      map[int]int `json:"packs" example:"5000:2,2000:1,250:1"`
+	TotalPacks int         `json:"total_packs" example:"4"`
+	Reason     string      `json:"reason" example:"chosen"`
+}
+
+// AlternativeResponse represents a combination of packs that ranks after the
+// calculated result
+type AlternativeResponse struct {
+	TotalItems int         `json:"total_items" example:"500"`
+	Packs      map[int]int `json:"packs" example:"250:2"`
+	Surplus    int         `json:"surplus" example:"249"`
 	TotalPacks int         `json:"total_packs" example:"2"`
+	TotalCost  int         `json:"total_cost" example:"0"`
 }
 
 // Handle godoc
 // @Summary Calculate optimal package combination
-// @Description Calculates the best package combination to fulfill an order, minimizing items shipped and number of packages
+// @Description Calculates the best package combination to fulfill an order, minimizing items shipped and number of packages.
+// @Description When a sku is given, the order is calculated with that product's pack sizes instead of the global ones.
+// @Description An optional stock object maps pack sizes to the packs available; sizes missing from it are unlimited. The same rules then apply to the packs in stock only.
+// @Description The objective selects what is optimized: min_items_then_packs (default), min_cost or min_items_then_cost. total_cost prices the packs with the configured pack sizes costs.
+// @Description The strategy ranks combinations of the fewest items: fewest_packs (default), larger_packs, fewest_distinct_sizes, surplus_only or lexicographic: followed by comma-separated keys among packs, distinct_sizes and larger_packs.
+// @Description With alternatives set to K (at most 10), the K next best combinations are listed after the result, ranked by the same objective and strategy.
+// @Description With explain, the response carries a trace of the searched range, the nearest reachable totals, the tie rule and the combinations that were rejected. It is available for the default objective and strategy without stock or inventory.
+// @Description With exact the order fails unless a combination ships exactly the order; max_surplus caps the items above the order instead, and with fallback the best result is returned anyway with surplus_exceeded set. These failures return 422 with code no_exact_fit or surplus_exceeded.
+// @Description With mode under_fulfil the most items not exceeding the order are shipped with the fewest packs, and shortfall reports the items left out; it cannot be combined with exact, max_surplus, alternatives or a cost-aware objective.
+// @Description Orders above the configured maximum order, and calculations exceeding the compute budget in iterations or time, return 422 with code order_limit_exceeded or compute_budget_exceeded and the limit in the message.
+// @Description Pack size rules configured with the pack sizes are always respected; orders they cannot fulfill are rejected with 422.
+// @Description When the pack sizes have a parcel weight or volume limit, parcels groups the chosen packs into shipping parcels within it, heaviest packs first.
+// @Description With use_inventory the order is planned from the unreserved packs of the inventory; with reserve those packs are also reserved and the response carries the reservation_id.
+// @Description A text/csv body of order_id,quantity records (header row optional) is calculated as a whole and answered with CSV columns order_id, quantity, total_items, surplus, total_packs, one pack_<size> column per pack size and error.
+// @Description JSON requests sent with "Accept: text/csv" receive the same CSV layout with a single record.
 // @Tags calculate
-// @Accept json
-// @Produce json
+// @Accept json,text/csv
+// @Produce json,text/csv
 // @Param request body CalculateRequest true "Order quantity"
 // @Success 200 {object} CalculateResponse
-// @Failure 400 {object} map[string]string "Bad Request - Invalid order or negative value"
+// @Failure 400 {object} map[string]string "Bad Request - Invalid order, negative value, unknown objective or invalid strategy"
+// @Failure 404 {object} map[string]string "Product not found"
 // @Failure 405 {object} map[string]string "Method Not Allowed"
+// @Failure 422 {object} map[string]string "Unprocessable Entity - Order cannot be calculated with the current pack sizes, rules, stock or parcel limits"
+// @Failure 503 {object} map[string]string "Service Unavailable - Server saturated (see Retry-After), calculation cancelled or timed out"
 // @Router /api/calculate [post]
 func (h *CalculateHandler) Handle(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -52,6 +216,11 @@ func (h *CalculateHandler) Handle(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if response.IsCSV(r) {
+		h.handleCSV(w, r)
+		return
+	}
+
 	var req CalculateRequest
 
 	if err := response.DecodeJSON(r, &req); err != nil {
@@ -59,21 +228,421 @@ func (h *CalculateHandler) Handle(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if req.Order < 0 {
-		response.Error(w, http.StatusBadRequest, "Order must be positive")
+	ctx, cancel := h.calculator.Budget(r.Context())
+	defer cancel()
+
+	responseData, err := h.calculate(ctx, req)
+	if err != nil {
+		status, message := calculationError(err)
+		setRetryAfter(w, err)
+		response.ErrorWithCode(w, status, calculationErrorCode(err), message)
+		return
+	}
+
+	if response.AcceptsCSV(r) {
+		calculation := csvCalculation{quantity: strconv.Itoa(req.Order), result: &responseData}
+		writeCalculationsCSV(w, []csvCalculation{calculation}, responseData.PackSizes)
 		return
 	}
 
-	result := h.calculator.Calculate(req.Order)
+	response.JSON(w, http.StatusOK, responseData)
+}
+
+// calculate validates a decoded request and runs the calculation for it.
+// Validation failures are reported with the same domain errors as the calculator.
+func (h *CalculateHandler) calculate(ctx context.Context, req CalculateRequest) (CalculateResponse, error) {
+	if req.Order < 0 {
+		return CalculateResponse{}, domain.ErrInvalidOrder
+	}
+
+	if req.Alternatives < 0 || req.Alternatives > maxAlternatives {
+		return CalculateResponse{}, errInvalidAlternatives
+	}
+
+	strategy, err := h.strategyFor(req)
+	if err != nil {
+		return CalculateResponse{}, err
+	}
+
+	opts := domain.CalculateOptions{
+		Objective: domain.Objective(req.Objective),
+		Strategy:  strategy,
+		Mode:      domain.Mode(req.Mode),
+	}
+
+	opts.MaxSurplus, err = maxSurplusFor(req)
+	if err != nil {
+		return CalculateResponse{}, err
+	}
+
+	if opts.Mode == domain.ModeUnderFulfil && (opts.MaxSurplus != nil || req.Alternatives > 0) {
+		return CalculateResponse{}, errUnderFulfilConflict
+	}
+
+	if req.Reserve {
+		return h.reserveFromInventory(ctx, req, opts)
+	}
+
+	calculator, opts, err := h.calculationFor(req, opts)
+	if err != nil {
+		return CalculateResponse{}, err
+	}
+
+	var result domain.PackResult
+	var explanation domain.Explanation
+	exceeded := false
+
+	if req.Explain {
+		if req.UseInventory || !explainable(opts) {
+			return CalculateResponse{}, errExplainUnsupported
+		}
+		result, explanation, err = calculator.Explain(ctx, req.Order)
+	} else {
+		result, err = calculator.CalculateWithOptions(ctx, req.Order, opts)
+		if req.Fallback && errors.Is(err, domain.ErrSurplusExceeded) {
+			opts.MaxSurplus = nil
+			result, err = calculator.CalculateWithOptions(ctx, req.Order, opts)
+			exceeded = true
+		}
+	}
+	if err != nil {
+		return CalculateResponse{}, err
+	}
+
+	responseData := newCalculateResponse(result)
+	responseData.SurplusExceeded = exceeded
+
+	if req.Explain {
+		responseData.Explanation = newExplanationResponse(explanation)
+	}
+
+	if req.Alternatives > 0 {
+		responseData.Alternatives, err = alternativesFor(ctx, calculator, result, opts, req.Alternatives)
+		if err != nil {
+			return CalculateResponse{}, err
+		}
+	}
+
+	return responseData, nil
+}
+
+// calculationFor returns the calculator and the stock a request is planned with:
+// the unreserved packs of the inventory, or the request's own stock with the
+// pack sizes of its product or the global ones
+func (h *CalculateHandler) calculationFor(req CalculateRequest, opts domain.CalculateOptions) (*domain.PackCalculator, domain.CalculateOptions, error) {
+	if !req.UseInventory {
+		calculator, err := h.calculatorFor(req.SKU)
+		opts.Stock = req.Stock
+		return calculator, opts, err
+	}
+
+	if req.SKU != "" || req.Stock != nil {
+		return nil, opts, errInventoryConflict
+	}
+
+	if h.inventory == nil {
+		return nil, opts, errNoInventory
+	}
+
+	opts.Stock = h.inventory.Available(h.calculator.GetPackSizes())
+	return h.calculator, opts, nil
+}
+
+// reserveFromInventory plans the order from the unreserved packs of the
+// inventory and reserves them
+func (h *CalculateHandler) reserveFromInventory(ctx context.Context, req CalculateRequest, opts domain.CalculateOptions) (CalculateResponse, error) {
+	if req.SKU != "" || req.Stock != nil {
+		return CalculateResponse{}, errInventoryConflict
+	}
+
+	if req.Alternatives > 0 {
+		return CalculateResponse{}, errAlternativesWithReserve
+	}
+
+	if req.Explain {
+		return CalculateResponse{}, errExplainUnsupported
+	}
+
+	if h.inventory == nil {
+		return CalculateResponse{}, errNoInventory
+	}
+
+	result, reservation, err := h.inventory.ReserveOrder(ctx, h.calculator, req.Order, opts)
+	exceeded := false
+	if req.Fallback && errors.Is(err, domain.ErrSurplusExceeded) {
+		opts.MaxSurplus = nil
+		result, reservation, err = h.inventory.ReserveOrder(ctx, h.calculator, req.Order, opts)
+		exceeded = true
+	}
+	if err != nil {
+		return CalculateResponse{}, err
+	}
+
+	responseData := newCalculateResponse(result)
+	responseData.ReservationID = reservation.ID
+	responseData.SurplusExceeded = exceeded
+	return responseData, nil
+}
+
+// explainable reports whether the options are calculated with the dynamic
+// programming table alone and fulfil the order, which is what an explanation
+// describes
+func explainable(opts domain.CalculateOptions) bool {
+	switch opts.Objective {
+	case "", domain.ObjectiveMinItemsThenPacks:
+	default:
+		return false
+	}
+
+	switch opts.Mode {
+	case "", domain.ModeFulfil:
+	default:
+		return false
+	}
+
+	return opts.Stock == nil && opts.MaxSurplus == nil &&
+		(opts.Strategy == nil || opts.Strategy.Name() == domain.FewestPacks.Name())
+}
+
+// maxSurplusFor returns the maximum surplus a request accepts: none with exact,
+// max_surplus otherwise, or nil when the surplus is unlimited
+func maxSurplusFor(req CalculateRequest) (*int, error) {
+	if req.Exact && req.MaxSurplus != nil {
+		return nil, errSurplusConflict
+	}
+
+	if req.Fallback && req.MaxSurplus == nil {
+		return nil, errFallbackWithoutMaxSurplus
+	}
+
+	if req.Exact {
+		exact := 0
+		return &exact, nil
+	}
+
+	return req.MaxSurplus, nil
+}
+
+// newExplanationResponse builds the response body for an explanation
+func newExplanationResponse(explanation domain.Explanation) *ExplanationResponse {
+	responseData := &ExplanationResponse{
+		SearchFrom:        explanation.SearchFrom,
+		SearchTo:          explanation.SearchTo,
+		LargestPacksAdded: explanation.LargestPacksAdded,
+		NearestBelow:      explanation.NearestBelow,
+		NearestAbove:      explanation.NearestAbove,
+		TieRule:           explanation.TieRule,
+		Candidates:        make([]ExplainedCandidateResponse, len(explanation.Candidates)),
+	}
+
+	for i, candidate := range explanation.Candidates {
+		responseData.Candidates[i] = ExplainedCandidateResponse{
+			TotalItems: candidate.TotalItems,
+			Packs:      candidate.Packs,
+			TotalPacks: candidate.TotalPacks,
+			Reason:     candidate.Reason,
+		}
+	}
+
+	return responseData
+}
+
+// alternativesFor returns the k best combinations for the order other than the
+// calculated result, or none when they cannot be ranked within the limits so that
+// the result is still returned
+func alternativesFor(
+	ctx context.Context,
+	calculator *domain.PackCalculator,
+	result domain.PackResult,
+	opts domain.CalculateOptions,
+	k int,
+) ([]AlternativeResponse, error) {
+	ranked, err := calculator.Alternatives(ctx, result.Order, opts, k+1)
+	if errors.Is(err, domain.ErrOrderTooLarge) || errors.Is(err, domain.ErrComputeBudget) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	alternatives := make([]AlternativeResponse, 0, k)
+	skipped := false
+	for _, alternative := range ranked {
+		if !skipped && maps.Equal(alternative.Packs, result.Packs) {
+			skipped = true
+			continue
+		}
+		if len(alternatives) == k {
+			break
+		}
+
+		alternatives = append(alternatives, AlternativeResponse{
+			TotalItems: alternative.TotalItems,
+			Packs:      alternative.Packs,
+			Surplus:    alternative.GetSurplus(),
+			TotalPacks: alternative.GetTotalPackCount(),
+			TotalCost:  alternative.TotalCost,
+		})
+	}
+
+	return alternatives, nil
+}
+
+// strategyFor returns the strategy the request names, or the handler's default
+// strategy for requests that keep the min_items_then_packs objective
+func (h *CalculateHandler) strategyFor(req CalculateRequest) (domain.Strategy, error) {
+	if req.Strategy != "" {
+		return domain.ParseStrategy(req.Strategy)
+	}
+
+	switch domain.Objective(req.Objective) {
+	case "", domain.ObjectiveMinItemsThenPacks:
+		return h.strategy, nil
+	default:
+		return nil, nil
+	}
+}
+
+// calculatorFor returns the calculator of the product with the given SKU, or the
+// global calculator when no SKU is given
+func (h *CalculateHandler) calculatorFor(sku string) (*domain.PackCalculator, error) {
+	if sku == "" {
+		return h.calculator, nil
+	}
+
+	if h.catalog == nil {
+		return nil, domain.ErrProductNotFound
+	}
+
+	return h.catalog.Calculator(sku)
+}
 
+// newCalculateResponse builds the response body for a calculation result
+func newCalculateResponse(result domain.PackResult) CalculateResponse {
 	responseData := CalculateResponse{
 		Order:      result.Order,
 		TotalItems: result.TotalItems,
 		Packs:      result.Packs,
 		PackSizes:  result.PackSizes,
 		Surplus:    result.GetSurplus(),
+		Shortfall:  result.GetShortfall(),
 		TotalPacks: result.GetTotalPackCount(),
+		TotalCost:  result.TotalCost,
 	}
 
-	response.JSON(w, http.StatusOK, responseData)
+	for _, parcel := range result.Parcels {
+		responseData.Parcels = append(responseData.Parcels, ParcelResponse{
+			Count:  parcel.Count,
+			Packs:  parcel.Packs,
+			Weight: parcel.Weight,
+			Volume: parcel.Volume,
+		})
+	}
+	responseData.TotalParcels = result.GetParcelCount()
+
+	return responseData
+}
+
+// calculationError maps an error returned by the calculator to an HTTP status code
+// and a client-facing message.
+func calculationError(err error) (int, string) {
+	var limitErr *domain.LimitError
+
+	switch {
+	case errors.Is(err, domain.ErrInvalidOrder):
+		return http.StatusBadRequest, "Order must be positive"
+	case errors.Is(err, domain.ErrInvalidStock):
+		return http.StatusBadRequest, "Stock must not be negative"
+	case errors.Is(err, domain.ErrInvalidObjective):
+		return http.StatusBadRequest, "Objective must be one of min_items_then_packs, min_cost, min_items_then_cost"
+	case errors.Is(err, domain.ErrInvalidStrategy):
+		return http.StatusBadRequest, "Strategy must be fewest_packs, larger_packs, fewest_distinct_sizes, surplus_only or lexicographic:<keys>, with the min_items_then_packs objective"
+	case errors.Is(err, errInvalidAlternatives):
+		return http.StatusBadRequest, fmt.Sprintf("Alternatives must be between 0 and %d", maxAlternatives)
+	case errors.Is(err, errAlternativesWithReserve):
+		return http.StatusBadRequest, "alternatives cannot be combined with reserve"
+	case errors.Is(err, domain.ErrInvalidMaxSurplus):
+		return http.StatusBadRequest, "Max surplus must not be negative"
+	case errors.Is(err, errSurplusConflict):
+		return http.StatusBadRequest, "exact cannot be combined with max_surplus"
+	case errors.Is(err, errFallbackWithoutMaxSurplus):
+		return http.StatusBadRequest, "fallback requires max_surplus"
+	case errors.Is(err, domain.ErrInvalidMode):
+		return http.StatusBadRequest, "Mode must be fulfil or under_fulfil, with the min_items_then_packs objective"
+	case errors.Is(err, errUnderFulfilConflict):
+		return http.StatusBadRequest, "under_fulfil cannot be combined with exact, max_surplus or alternatives"
+	case errors.Is(err, errExplainUnsupported):
+		return http.StatusBadRequest, "explain is only available for the default objective, strategy and mode without stock or inventory"
+	case errors.Is(err, domain.ErrNotExplainable):
+		return http.StatusBadRequest, "explain is not available for orders restricted by pack size rules"
+	case errors.Is(err, errInventoryConflict):
+		return http.StatusBadRequest, "use_inventory and reserve cannot be combined with sku or stock"
+	case errors.Is(err, domain.ErrInvalidPackCount):
+		return http.StatusBadRequest, "Pack counts must be positive"
+	case errors.Is(err, domain.ErrEmptyReservation):
+		return http.StatusBadRequest, "Reservation must contain packs"
+	case errors.Is(err, domain.ErrReservationNotFound):
+		return http.StatusNotFound, "Reservation not found"
+	case errors.Is(err, domain.ErrProductNotFound):
+		return http.StatusNotFound, "Product not found"
+	case errors.Is(err, domain.ErrNoPackSizes):
+		return http.StatusUnprocessableEntity, "No pack sizes configured"
+	case errors.As(err, &limitErr) && errors.Is(err, domain.ErrOrderLimit):
+		return http.StatusUnprocessableEntity, fmt.Sprintf("Order exceeds the maximum of %s", limitErr.Limit)
+	case errors.As(err, &limitErr):
+		return http.StatusUnprocessableEntity, fmt.Sprintf("Calculation exceeds the compute budget of %s", limitErr.Limit)
+	case errors.Is(err, domain.ErrOrderTooLarge):
+		return http.StatusUnprocessableEntity, "Order is too large to calculate"
+	case errors.Is(err, domain.ErrParcelLimit):
+		return http.StatusUnprocessableEntity, "A pack exceeds the parcel limits"
+	case errors.Is(err, domain.ErrNoExactFit):
+		return http.StatusUnprocessableEntity, "No combination matches the order exactly"
+	case errors.Is(err, domain.ErrSurplusExceeded):
+		return http.StatusUnprocessableEntity, "Every combination exceeds the maximum surplus"
+	case errors.Is(err, domain.ErrRulesInfeasible):
+		return http.StatusUnprocessableEntity, "Pack size rules cannot fulfill the order"
+	case errors.Is(err, domain.ErrInsufficientStock):
+		return http.StatusUnprocessableEntity, "Not enough packs in stock to fulfill the order"
+	case errors.Is(err, domain.ErrInfeasible):
+		return http.StatusUnprocessableEntity, "Order cannot be fulfilled with the available pack sizes"
+	case errors.Is(err, domain.ErrSaturated):
+		return http.StatusServiceUnavailable, "Server is busy, retry later"
+	case errors.Is(err, errNoInventory):
+		return http.StatusServiceUnavailable, "Inventory is not available"
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusServiceUnavailable, "Calculation timed out"
+	case errors.Is(err, context.Canceled):
+		return http.StatusServiceUnavailable, "Calculation cancelled"
+	default:
+		return http.StatusInternalServerError, "Internal server error"
+	}
+}
+
+// calculationErrorCode returns the machine-readable code of an error returned by
+// the calculator, for errors clients are expected to react to, or an empty string.
+func calculationErrorCode(err error) string {
+	switch {
+	case errors.Is(err, domain.ErrNoExactFit):
+		return "no_exact_fit"
+	case errors.Is(err, domain.ErrSurplusExceeded):
+		return "surplus_exceeded"
+	case errors.Is(err, domain.ErrOrderLimit):
+		return "order_limit_exceeded"
+	case errors.Is(err, domain.ErrComputeBudget):
+		return "compute_budget_exceeded"
+	case errors.Is(err, domain.ErrSaturated):
+		return "saturated"
+	default:
+		return ""
+	}
+}
+
+// setRetryAfter sets the Retry-After header, in whole seconds, when the calculator
+// rejected the calculation because the server is saturated.
+func setRetryAfter(w http.ResponseWriter, err error) {
+	var saturatedErr *domain.SaturatedError
+	if errors.As(err, &saturatedErr) {
+		seconds := int64(math.Ceil(saturatedErr.RetryAfter.Seconds()))
+		w.Header().Set("Retry-After", strconv.FormatInt(seconds, 10))
+	}
 }
@@ -2,10 +2,14 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -163,6 +167,7 @@ func TestCalculateHandler_HandlePost(t *testing.T) {
 				assert.Contains(t, response, "pack_sizes")
 				assert.Contains(t, response, "surplus")
 				assert.Contains(t, response, "total_packs")
+				assert.Contains(t, response, "total_cost")
 			}
 		})
 	}
@@ -234,6 +239,7 @@ func TestCalculateHandler_ResponseFormat(t *testing.T) {
 		assert.Contains(t, response, "pack_sizes")
 		assert.Contains(t, response, "surplus")
 		assert.Contains(t, response, "total_packs")
+		assert.Contains(t, response, "total_cost")
 
 		// Verify response structure
 		assert.Equal(t, float64(501), response["order"])
@@ -244,3 +250,956 @@ func TestCalculateHandler_ResponseFormat(t *testing.T) {
 		assert.Equal(t, float64(2), response["total_packs"])
 	})
 }
+
+func TestCalculateHandler_HandlePost_CalculationErrors(t *testing.T) {
+	t.Run("should report missing pack sizes as unprocessable", func(t *testing.T) {
+		calculator := domain.NewPackCalculator([]int{})
+		handler := NewCalculateHandler(calculator)
+
+		req := httptest.NewRequest(http.MethodPost, "/calculate", bytes.NewBufferString(`{"order": 100}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		handler.Handle(w, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+		var errorResponse map[string]string
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&errorResponse))
+		assert.Equal(t, "No pack sizes configured", errorResponse["error"])
+	})
+
+	t.Run("should stop calculating when the request is cancelled", func(t *testing.T) {
+		calculator := domain.NewPackCalculator([]int{997, 1009, 4999})
+		handler := NewCalculateHandler(calculator)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		req := httptest.NewRequest(http.MethodPost, "/calculate", bytes.NewBufferString(`{"order": 1000001}`))
+		req = req.WithContext(ctx)
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		handler.Handle(w, req)
+
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+		var errorResponse map[string]string
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&errorResponse))
+		assert.Equal(t, "Calculation cancelled", errorResponse["error"])
+	})
+}
+
+func TestCalculateHandler_HandlePost_SKU(t *testing.T) {
+	catalog := domain.NewCatalog()
+	_, err := catalog.Add("WIDGET", "Widget", []int{23, 31, 53})
+	require.NoError(t, err)
+
+	handler := NewCalculateHandler(domain.NewPackCalculator([]int{250, 500, 1000}), WithCatalog(catalog))
+
+	tests := []struct {
+		name               string
+		body               string
+		expectedStatus     int
+		expectedTotalItems int
+		expectedPackSizes  []int
+		expectedError      string
+	}{
+		{
+			name:               "should use the product pack sizes",
+			body:               `{"order": 263, "sku": "WIDGET"}`,
+			expectedStatus:     http.StatusOK,
+			expectedTotalItems: 263,
+			expectedPackSizes:  []int{23, 31, 53},
+		},
+		{
+			name:               "should use the global pack sizes without a SKU",
+			body:               `{"order": 263}`,
+			expectedStatus:     http.StatusOK,
+			expectedTotalItems: 500,
+			expectedPackSizes:  []int{250, 500, 1000},
+		},
+		{
+			name:           "should report unknown products",
+			body:           `{"order": 263, "sku": "MISSING"}`,
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "Product not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/calculate", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			handler.Handle(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			if tt.expectedError != "" {
+				var errorResponse map[string]string
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&errorResponse))
+				assert.Equal(t, tt.expectedError, errorResponse["error"])
+				return
+			}
+
+			var responseData CalculateResponse
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&responseData))
+			assert.Equal(t, tt.expectedTotalItems, responseData.TotalItems)
+			assert.Equal(t, tt.expectedPackSizes, responseData.PackSizes)
+		})
+	}
+
+	t.Run("should report products as not found without a catalog", func(t *testing.T) {
+		handler := NewCalculateHandler(domain.NewPackCalculator([]int{250}))
+
+		req := httptest.NewRequest(http.MethodPost, "/calculate", bytes.NewBufferString(`{"order": 1, "sku": "WIDGET"}`))
+		w := httptest.NewRecorder()
+
+		handler.Handle(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestCalculateHandler_HandlePost_Stock(t *testing.T) {
+	handler := NewCalculateHandler(domain.NewPackCalculator([]int{250, 500, 1000, 2000, 5000}))
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expectedPacks  map[int]int
+		expectedError  string
+	}{
+		{
+			name:           "should plan with the packs in stock",
+			body:           `{"order": 12001, "stock": {"5000": 1}}`,
+			expectedStatus: http.StatusOK,
+			expectedPacks:  map[int]int{5000: 1, 2000: 3, 1000: 1, 250: 1},
+		},
+		{
+			name:           "should report stock that cannot cover the order",
+			body:           `{"order": 1001, "stock": {"250": 1, "500": 1, "1000": 0, "2000": 0, "5000": 0}}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedError:  "Not enough packs in stock to fulfill the order",
+		},
+		{
+			name:           "should reject negative stock",
+			body:           `{"order": 1, "stock": {"250": -1}}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Stock must not be negative",
+		},
+		{
+			name:           "should reject non numeric pack sizes in stock",
+			body:           `{"order": 1, "stock": {"large": 1}}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Invalid request body",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/calculate", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			handler.Handle(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			if tt.expectedError != "" {
+				var errorResponse map[string]string
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&errorResponse))
+				assert.Equal(t, tt.expectedError, errorResponse["error"])
+				return
+			}
+
+			var responseData CalculateResponse
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&responseData))
+			assert.Equal(t, tt.expectedPacks, responseData.Packs)
+		})
+	}
+}
+
+func TestCalculateHandler_HandlePost_Parcels(t *testing.T) {
+	calculator := domain.NewPackCalculator([]int{250, 500, 1000, 2000, 5000})
+	handler := NewCalculateHandler(calculator)
+
+	post := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/calculate", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		handler.Handle(w, req)
+		return w
+	}
+
+	t.Run("should omit parcels without parcel limits", func(t *testing.T) {
+		w := post(`{"order": 12001}`)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.NotContains(t, w.Body.String(), "parcels")
+	})
+
+	t.Run("should group the packs into parcels", func(t *testing.T) {
+		calculator.UpdateParcelSpec(domain.ParcelSpec{
+			Weights:   map[int]int{250: 3000, 2000: 21000, 5000: 30000},
+			Volumes:   map[int]int{250: 4000, 2000: 30000, 5000: 55000},
+			MaxWeight: 31500,
+			MaxVolume: 60000,
+		})
+
+		w := post(`{"order": 12001}`)
+
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var responseData CalculateResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&responseData))
+		assert.Equal(t, map[int]int{5000: 2, 2000: 1, 250: 1}, responseData.Packs)
+		assert.Equal(t, []ParcelResponse{
+			{Count: 2, Packs: map[int]int{5000: 1}, Weight: 30000, Volume: 55000},
+			{Count: 1, Packs: map[int]int{2000: 1, 250: 1}, Weight: 24000, Volume: 34000},
+		}, responseData.Parcels)
+		assert.Equal(t, 3, responseData.TotalParcels)
+	})
+
+	t.Run("should reject packs that exceed the parcel limits", func(t *testing.T) {
+		calculator.UpdateParcelSpec(domain.ParcelSpec{Weights: map[int]int{250: 40000}, MaxWeight: 31500})
+
+		w := post(`{"order": 1}`)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Contains(t, w.Body.String(), "A pack exceeds the parcel limits")
+	})
+}
+
+func TestCalculateHandler_HandlePost_Rules(t *testing.T) {
+	tests := []struct {
+		name           string
+		rules          domain.PackRules
+		body           string
+		expectedStatus int
+		expectedPacks  map[int]int
+	}{
+		{
+			name:           "should respect the pack size rules",
+			rules:          domain.PackRules{250: {MaxPacks: 2}, 5000: {MinOrder: 10001}},
+			body:           `{"order": 10000}`,
+			expectedStatus: http.StatusOK,
+			expectedPacks:  map[int]int{2000: 5},
+		},
+		{
+			name:           "should reject orders the rules cannot fulfill",
+			rules:          domain.PackRules{250: {MaxOrder: 1000}, 500: {MaxOrder: 1000}, 1000: {MaxOrder: 1000}, 2000: {MaxPacks: 1}, 5000: {MaxPacks: 1}},
+			body:           `{"order": 7001}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calculator := domain.NewPackCalculator([]int{250, 500, 1000, 2000, 5000})
+			calculator.UpdateRules(tt.rules)
+			handler := NewCalculateHandler(calculator)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/calculate", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			handler.Handle(w, req)
+
+			require.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedPacks != nil {
+				var responseData CalculateResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&responseData))
+				assert.Equal(t, tt.expectedPacks, responseData.Packs)
+			} else {
+				assert.Contains(t, w.Body.String(), "Pack size rules cannot fulfill the order")
+			}
+		})
+	}
+}
+
+func TestCalculateHandler_HandlePost_Surplus(t *testing.T) {
+	tests := []struct {
+		name             string
+		body             string
+		expectedStatus   int
+		expectedCode     string
+		expectedPacks    map[int]int
+		expectedExceeded bool
+	}{
+		{
+			name:           "should accept an exact fit",
+			body:           `{"order": 750, "exact": true}`,
+			expectedStatus: http.StatusOK,
+			expectedPacks:  map[int]int{500: 1, 250: 1},
+		},
+		{
+			name:           "should refuse an inexact fit",
+			body:           `{"order": 751, "exact": true}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedCode:   "no_exact_fit",
+		},
+		{
+			name:           "should accept a surplus up to the maximum",
+			body:           `{"order": 251, "max_surplus": 249}`,
+			expectedStatus: http.StatusOK,
+			expectedPacks:  map[int]int{500: 1},
+		},
+		{
+			name:           "should refuse a surplus above the maximum",
+			body:           `{"order": 251, "max_surplus": 100}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedCode:   "surplus_exceeded",
+		},
+		{
+			name:             "should fall back to the best result when asked",
+			body:             `{"order": 251, "max_surplus": 100, "fallback": true}`,
+			expectedStatus:   http.StatusOK,
+			expectedPacks:    map[int]int{500: 1},
+			expectedExceeded: true,
+		},
+		{
+			name:           "should reject a negative maximum surplus",
+			body:           `{"order": 251, "max_surplus": -1}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "should reject exact with a maximum surplus",
+			body:           `{"order": 251, "exact": true, "max_surplus": 10}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "should reject a fallback without a maximum surplus",
+			body:           `{"order": 251, "exact": true, "fallback": true}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "should reject explaining with a maximum surplus",
+			body:           `{"order": 251, "max_surplus": 300, "explain": true}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewCalculateHandler(domain.NewPackCalculator([]int{250, 500, 1000, 2000, 5000}))
+
+			req := httptest.NewRequest(http.MethodPost, "/api/calculate", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			handler.Handle(w, req)
+
+			require.Equal(t, tt.expectedStatus, w.Code)
+
+			if tt.expectedStatus != http.StatusOK {
+				var errorResponse map[string]string
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&errorResponse))
+				assert.Equal(t, tt.expectedCode, errorResponse["code"])
+				return
+			}
+
+			var responseData CalculateResponse
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&responseData))
+			assert.Equal(t, tt.expectedPacks, responseData.Packs)
+			assert.Equal(t, tt.expectedExceeded, responseData.SurplusExceeded)
+		})
+	}
+
+	t.Run("should report the error code on stream lines", func(t *testing.T) {
+		handler := NewCalculateHandler(domain.NewPackCalculator([]int{250, 500}))
+
+		req := httptest.NewRequest(http.MethodPost, "/api/calculate/stream", bytes.NewBufferString(`{"order": 251, "exact": true}`+"\n"))
+		w := httptest.NewRecorder()
+
+		handler.HandleStream(w, req)
+
+		assert.JSONEq(t, `{"line": 1, "error": "No combination matches the order exactly", "code": "no_exact_fit"}`, w.Body.String())
+	})
+}
+
+func TestCalculateHandler_HandlePost_UnderFulfil(t *testing.T) {
+	tests := []struct {
+		name              string
+		body              string
+		expectedStatus    int
+		expectedItems     int
+		expectedPacks     map[int]int
+		expectedShortfall int
+	}{
+		{
+			name:              "should ship the most items not exceeding the order",
+			body:              `{"order": 12001, "mode": "under_fulfil"}`,
+			expectedStatus:    http.StatusOK,
+			expectedItems:     12000,
+			expectedPacks:     map[int]int{5000: 2, 2000: 1},
+			expectedShortfall: 1,
+		},
+		{
+			name:              "should under-fulfil from the stock",
+			body:              `{"order": 1000, "mode": "under_fulfil", "stock": {"250": 1, "500": 1, "1000": 0, "2000": 0, "5000": 0}}`,
+			expectedStatus:    http.StatusOK,
+			expectedItems:     750,
+			expectedPacks:     map[int]int{500: 1, 250: 1},
+			expectedShortfall: 250,
+		},
+		{
+			name:           "should fulfil orders in the default mode",
+			body:           `{"order": 12001, "mode": "fulfil"}`,
+			expectedStatus: http.StatusOK,
+			expectedItems:  12250,
+			expectedPacks:  map[int]int{5000: 2, 2000: 1, 250: 1},
+		},
+		{
+			name:           "should reject unknown modes",
+			body:           `{"order": 251, "mode": "overflow"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "should reject cost-aware objectives",
+			body:           `{"order": 251, "mode": "under_fulfil", "objective": "min_cost"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "should reject a maximum surplus",
+			body:           `{"order": 251, "mode": "under_fulfil", "exact": true}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "should reject alternatives",
+			body:           `{"order": 251, "mode": "under_fulfil", "alternatives": 2}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "should reject explaining",
+			body:           `{"order": 251, "mode": "under_fulfil", "explain": true}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewCalculateHandler(domain.NewPackCalculator([]int{250, 500, 1000, 2000, 5000}))
+
+			req := httptest.NewRequest(http.MethodPost, "/api/calculate", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			handler.Handle(w, req)
+
+			require.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var responseData CalculateResponse
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&responseData))
+			assert.Equal(t, tt.expectedItems, responseData.TotalItems)
+			assert.Equal(t, tt.expectedPacks, responseData.Packs)
+			assert.Equal(t, tt.expectedShortfall, responseData.Shortfall)
+		})
+	}
+}
+
+func TestCalculateHandler_HandlePost_Limits(t *testing.T) {
+	calculator := domain.NewPackCalculator([]int{250, 500, 1000, 2000, 5000}, domain.WithLimits(domain.Limits{
+		MaxOrder:      1_000_000,
+		MaxIterations: 20_000,
+	}))
+	handler := NewCalculateHandler(calculator)
+
+	tests := []struct {
+		name            string
+		body            string
+		expectedStatus  int
+		expectedCode    string
+		expectedMessage string
+	}{
+		{
+			name:           "should calculate orders within the limits",
+			body:           `{"order": 12001}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:            "should reject orders above the maximum order",
+			body:            `{"order": 2000000000}`,
+			expectedStatus:  http.StatusUnprocessableEntity,
+			expectedCode:    "order_limit_exceeded",
+			expectedMessage: "Order exceeds the maximum of 1000000 items",
+		},
+		{
+			name:            "should reject calculations beyond the compute budget",
+			body:            `{"order": 40000, "stock": {"5000": 1}}`,
+			expectedStatus:  http.StatusUnprocessableEntity,
+			expectedCode:    "compute_budget_exceeded",
+			expectedMessage: "Calculation exceeds the compute budget of 20000 iterations",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/calculate", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			handler.Handle(w, req)
+
+			require.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				return
+			}
+
+			var errorResponse map[string]string
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&errorResponse))
+			assert.Equal(t, tt.expectedCode, errorResponse["code"])
+			assert.Equal(t, tt.expectedMessage, errorResponse["error"])
+		})
+	}
+}
+
+func TestCalculateHandler_HandlePost_Objective(t *testing.T) {
+	newHandler := func() *CalculateHandler {
+		calculator := domain.NewPackCalculator([]int{250, 500, 1000})
+		calculator.UpdateCosts(domain.Costs{PerPack: map[int]int{250: 100, 500: 300, 1000: 120}, PerShipment: 10})
+		return NewCalculateHandler(calculator)
+	}
+
+	tests := []struct {
+		name          string
+		body          string
+		expectedPacks map[int]int
+		expectedCost  int
+	}{
+		{
+			name:          "should minimize items then packs by default",
+			body:          `{"order": 300}`,
+			expectedPacks: map[int]int{500: 1},
+			expectedCost:  310,
+		},
+		{
+			name:          "should minimize items then cost",
+			body:          `{"order": 300, "objective": "min_items_then_cost"}`,
+			expectedPacks: map[int]int{250: 2},
+			expectedCost:  210,
+		},
+		{
+			name:          "should minimize cost",
+			body:          `{"order": 300, "objective": "min_cost"}`,
+			expectedPacks: map[int]int{1000: 1},
+			expectedCost:  130,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/calculate", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			newHandler().Handle(w, req)
+
+			require.Equal(t, http.StatusOK, w.Code)
+
+			var responseData CalculateResponse
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&responseData))
+			assert.Equal(t, tt.expectedPacks, responseData.Packs)
+			assert.Equal(t, tt.expectedCost, responseData.TotalCost)
+		})
+	}
+
+	t.Run("should reject unknown objectives", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/calculate", bytes.NewBufferString(`{"order": 300, "objective": "fastest"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		newHandler().Handle(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestCalculateHandler_HandlePost_Strategy(t *testing.T) {
+	tests := []struct {
+		name           string
+		opts           []CalculateOption
+		body           string
+		expectedStatus int
+		expectedPacks  map[int]int
+	}{
+		{
+			name:           "should rank by the fewest packs by default",
+			body:           `{"order": 501}`,
+			expectedStatus: http.StatusOK,
+			expectedPacks:  map[int]int{500: 1, 250: 1},
+		},
+		{
+			name:           "should rank with the requested strategy",
+			body:           `{"order": 501, "strategy": "fewest_distinct_sizes"}`,
+			expectedStatus: http.StatusOK,
+			expectedPacks:  map[int]int{250: 3},
+		},
+		{
+			name:           "should rank with the handler's default strategy",
+			opts:           []CalculateOption{WithStrategy(domain.FewestDistinctSizes)},
+			body:           `{"order": 501}`,
+			expectedStatus: http.StatusOK,
+			expectedPacks:  map[int]int{250: 3},
+		},
+		{
+			name:           "should prefer the requested strategy to the default",
+			opts:           []CalculateOption{WithStrategy(domain.FewestDistinctSizes)},
+			body:           `{"order": 501, "strategy": "fewest_packs"}`,
+			expectedStatus: http.StatusOK,
+			expectedPacks:  map[int]int{500: 1, 250: 1},
+		},
+		{
+			name:           "should not apply the default strategy to cost objectives",
+			opts:           []CalculateOption{WithStrategy(domain.FewestDistinctSizes)},
+			body:           `{"order": 501, "objective": "min_items_then_cost"}`,
+			expectedStatus: http.StatusOK,
+			expectedPacks:  map[int]int{500: 1, 250: 1},
+		},
+		{
+			name:           "should reject unknown strategies",
+			body:           `{"order": 501, "strategy": "cheapest"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "should reject strategies with cost objectives",
+			body:           `{"order": 501, "strategy": "larger_packs", "objective": "min_cost"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewCalculateHandler(domain.NewPackCalculator([]int{250, 500, 1000}), tt.opts...)
+			req := httptest.NewRequest(http.MethodPost, "/api/calculate", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			handler.Handle(w, req)
+
+			require.Equal(t, tt.expectedStatus, w.Code)
+
+			if tt.expectedPacks != nil {
+				var responseData CalculateResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&responseData))
+				assert.Equal(t, tt.expectedPacks, responseData.Packs)
+			}
+		})
+	}
+}
+
+func TestCalculateHandler_HandlePost_Alternatives(t *testing.T) {
+	post := func(handler *CalculateHandler, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/calculate", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		handler.Handle(w, req)
+		return w
+	}
+
+	t.Run("should list the next best combinations", func(t *testing.T) {
+		handler := NewCalculateHandler(domain.NewPackCalculator([]int{250, 500, 1000, 2000, 5000}))
+
+		w := post(handler, `{"order": 251, "alternatives": 2}`)
+
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var responseData CalculateResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&responseData))
+		assert.Equal(t, map[int]int{500: 1}, responseData.Packs)
+		assert.Equal(t, []AlternativeResponse{
+			{TotalItems: 500, Packs: map[int]int{250: 2}, Surplus: 249, TotalPacks: 2},
+			{TotalItems: 1000, Packs: map[int]int{1000: 1}, Surplus: 749, TotalPacks: 1},
+		}, responseData.Alternatives)
+	})
+
+	t.Run("should rank alternatives within the stock", func(t *testing.T) {
+		handler := NewCalculateHandler(domain.NewPackCalculator([]int{250, 500, 1000}))
+
+		w := post(handler, `{"order": 251, "stock": {"500": 0, "1000": 0}, "alternatives": 3}`)
+
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var responseData CalculateResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&responseData))
+		assert.Equal(t, map[int]int{250: 2}, responseData.Packs)
+		assert.Empty(t, responseData.Alternatives)
+	})
+
+	t.Run("should list alternatives for large orders", func(t *testing.T) {
+		handler := NewCalculateHandler(domain.NewPackCalculator([]int{250, 500, 1000, 2000, 5000}))
+
+		w := post(handler, `{"order": 100000, "alternatives": 1}`)
+
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var responseData CalculateResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&responseData))
+		assert.Equal(t, map[int]int{5000: 20}, responseData.Packs)
+		require.Len(t, responseData.Alternatives, 1)
+		assert.Equal(t, 100000, responseData.Alternatives[0].TotalItems)
+	})
+
+	t.Run("should return the result without alternatives too many to rank", func(t *testing.T) {
+		handler := NewCalculateHandler(domain.NewPackCalculator([]int{250, 500, 1000, 2000, 5000}))
+
+		w := post(handler, `{"order": 1000000, "objective": "min_cost", "alternatives": 1}`)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.NotContains(t, w.Body.String(), "alternatives")
+	})
+
+	t.Run("should omit alternatives unless asked", func(t *testing.T) {
+		handler := NewCalculateHandler(domain.NewPackCalculator([]int{250, 500}))
+
+		w := post(handler, `{"order": 251}`)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.NotContains(t, w.Body.String(), "alternatives")
+	})
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "negative count", body: `{"order": 251, "alternatives": -1}`},
+		{name: "count above the limit", body: `{"order": 251, "alternatives": 11}`},
+		{name: "combined with reserve", body: `{"order": 251, "alternatives": 1, "reserve": true}`},
+	}
+
+	for _, tt := range tests {
+		t.Run("should reject "+tt.name, func(t *testing.T) {
+			handler := NewCalculateHandler(domain.NewPackCalculator([]int{250, 500}), WithInventory(domain.NewInventory()))
+
+			w := post(handler, tt.body)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
+
+func TestCalculateHandler_HandlePost_Explain(t *testing.T) {
+	post := func(handler *CalculateHandler, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/calculate", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		handler.Handle(w, req)
+		return w
+	}
+
+	t.Run("should explain why order 12001 ships 12250 items", func(t *testing.T) {
+		handler := NewCalculateHandler(domain.NewPackCalculator([]int{250, 500, 1000, 2000, 5000}))
+
+		w := post(handler, `{"order": 12001, "explain": true}`)
+
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var responseData CalculateResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&responseData))
+		require.NotNil(t, responseData.Explanation)
+		assert.Equal(t, 12250, responseData.TotalItems)
+
+		explanation := responseData.Explanation
+		assert.Equal(t, 12001, explanation.SearchFrom)
+		assert.Equal(t, 17001, explanation.SearchTo)
+		assert.Equal(t, 12000, explanation.NearestBelow)
+		assert.Equal(t, 12500, explanation.NearestAbove)
+		assert.NotEmpty(t, explanation.TieRule)
+		require.NotEmpty(t, explanation.Candidates)
+		assert.Equal(t, ExplainedCandidateResponse{
+			TotalItems: 12250,
+			Packs:      map[int]int{5000: 2, 2000: 1, 250: 1},
+			TotalPacks: 4,
+			Reason:     domain.ReasonChosen,
+		}, explanation.Candidates[0])
+	})
+
+	t.Run("should omit the explanation unless asked", func(t *testing.T) {
+		handler := NewCalculateHandler(domain.NewPackCalculator([]int{250, 500}))
+
+		w := post(handler, `{"order": 251}`)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.NotContains(t, w.Body.String(), "explanation")
+	})
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "stock", body: `{"order": 251, "explain": true, "stock": {"250": 1}}`},
+		{name: "cost objective", body: `{"order": 251, "explain": true, "objective": "min_cost"}`},
+		{name: "other strategy", body: `{"order": 251, "explain": true, "strategy": "larger_packs"}`},
+		{name: "inventory", body: `{"order": 251, "explain": true, "use_inventory": true}`},
+		{name: "reserve", body: `{"order": 251, "explain": true, "reserve": true}`},
+	}
+
+	for _, tt := range tests {
+		t.Run("should reject explaining with "+tt.name, func(t *testing.T) {
+			handler := NewCalculateHandler(domain.NewPackCalculator([]int{250, 500}), WithInventory(domain.NewInventory()))
+
+			w := post(handler, tt.body)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
+
+func TestCalculateHandler_HandlePost_Inventory(t *testing.T) {
+	newHandler := func(t *testing.T) (*CalculateHandler, *domain.Inventory) {
+		t.Helper()
+
+		inventory := domain.NewInventory()
+		require.NoError(t, inventory.Receive(250, 1))
+		require.NoError(t, inventory.Receive(500, 1))
+		require.NoError(t, inventory.Receive(1000, 1))
+
+		calculator := domain.NewPackCalculator([]int{250, 500, 1000})
+		return NewCalculateHandler(calculator, WithInventory(inventory)), inventory
+	}
+
+	post := func(handler *CalculateHandler, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/calculate", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		handler.Handle(w, req)
+		return w
+	}
+
+	t.Run("should plan from the available packs without reserving them", func(t *testing.T) {
+		handler, inventory := newHandler(t)
+
+		w := post(handler, `{"order": 1001, "use_inventory": true}`)
+
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var responseData CalculateResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&responseData))
+		assert.Equal(t, map[int]int{250: 1, 1000: 1}, responseData.Packs)
+		assert.Empty(t, responseData.ReservationID)
+		assert.Empty(t, inventory.Reservations())
+	})
+
+	t.Run("should reserve the packs of the result", func(t *testing.T) {
+		handler, inventory := newHandler(t)
+
+		w := post(handler, `{"order": 1001, "reserve": true}`)
+
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var responseData CalculateResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&responseData))
+		assert.Equal(t, "rsv-1", responseData.ReservationID)
+		assert.Equal(t, map[int]int{250: 0, 500: 1, 1000: 0}, inventory.Available([]int{250, 500, 1000}))
+
+		w = post(handler, `{"order": 1001, "reserve": true}`)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+
+	t.Run("should reject inventory planning combined with a sku or stock", func(t *testing.T) {
+		handler, _ := newHandler(t)
+
+		for _, body := range []string{
+			`{"order": 1, "use_inventory": true, "sku": "WIDGET"}`,
+			`{"order": 1, "reserve": true, "stock": {"250": 1}}`,
+		} {
+			w := post(handler, body)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code, body)
+		}
+	})
+
+	t.Run("should report a missing inventory as unavailable", func(t *testing.T) {
+		handler := NewCalculateHandler(domain.NewPackCalculator([]int{250}))
+
+		w := post(handler, `{"order": 1, "use_inventory": true}`)
+
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	})
+}
+
+func TestCalculationError(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		expectedStatus int
+	}{
+		{name: "invalid order", err: domain.ErrInvalidOrder, expectedStatus: http.StatusBadRequest},
+		{name: "no pack sizes", err: domain.ErrNoPackSizes, expectedStatus: http.StatusUnprocessableEntity},
+		{name: "order too large", err: fmt.Errorf("wrapped: %w", domain.ErrOrderTooLarge), expectedStatus: http.StatusUnprocessableEntity},
+		{name: "infeasible", err: domain.ErrInfeasible, expectedStatus: http.StatusUnprocessableEntity},
+		{name: "unknown product", err: domain.ErrProductNotFound, expectedStatus: http.StatusNotFound},
+		{name: "invalid stock", err: domain.ErrInvalidStock, expectedStatus: http.StatusBadRequest},
+		{name: "insufficient stock", err: domain.ErrInsufficientStock, expectedStatus: http.StatusUnprocessableEntity},
+		{name: "invalid objective", err: fmt.Errorf("%w: \"fastest\"", domain.ErrInvalidObjective), expectedStatus: http.StatusBadRequest},
+		{name: "invalid strategy", err: domain.ErrInvalidStrategy, expectedStatus: http.StatusBadRequest},
+		{name: "invalid alternatives", err: errInvalidAlternatives, expectedStatus: http.StatusBadRequest},
+		{name: "alternatives with reserve", err: errAlternativesWithReserve, expectedStatus: http.StatusBadRequest},
+		{name: "parcel limit", err: domain.ErrParcelLimit, expectedStatus: http.StatusUnprocessableEntity},
+		{name: "no exact fit", err: domain.ErrNoExactFit, expectedStatus: http.StatusUnprocessableEntity},
+		{name: "surplus exceeded", err: domain.ErrSurplusExceeded, expectedStatus: http.StatusUnprocessableEntity},
+		{name: "invalid max surplus", err: domain.ErrInvalidMaxSurplus, expectedStatus: http.StatusBadRequest},
+		{name: "invalid mode", err: domain.ErrInvalidMode, expectedStatus: http.StatusBadRequest},
+		{name: "under fulfil conflict", err: errUnderFulfilConflict, expectedStatus: http.StatusBadRequest},
+		{name: "order limit", err: &domain.LimitError{Err: domain.ErrOrderLimit, Limit: "100 items"}, expectedStatus: http.StatusUnprocessableEntity},
+		{name: "compute budget", err: fmt.Errorf("wrapped: %w", &domain.LimitError{Err: domain.ErrComputeBudget, Limit: "2s"}), expectedStatus: http.StatusUnprocessableEntity},
+		{name: "rules infeasible", err: domain.ErrRulesInfeasible, expectedStatus: http.StatusUnprocessableEntity},
+		{name: "not explainable", err: domain.ErrNotExplainable, expectedStatus: http.StatusBadRequest},
+		{name: "explain unsupported", err: errExplainUnsupported, expectedStatus: http.StatusBadRequest},
+		{name: "inventory conflict", err: errInventoryConflict, expectedStatus: http.StatusBadRequest},
+		{name: "invalid pack count", err: domain.ErrInvalidPackCount, expectedStatus: http.StatusBadRequest},
+		{name: "empty reservation", err: domain.ErrEmptyReservation, expectedStatus: http.StatusBadRequest},
+		{name: "unknown reservation", err: domain.ErrReservationNotFound, expectedStatus: http.StatusNotFound},
+		{name: "saturated", err: &domain.SaturatedError{RetryAfter: time.Second}, expectedStatus: http.StatusServiceUnavailable},
+		{name: "no inventory", err: errNoInventory, expectedStatus: http.StatusServiceUnavailable},
+		{name: "deadline exceeded", err: context.DeadlineExceeded, expectedStatus: http.StatusServiceUnavailable},
+		{name: "cancelled", err: context.Canceled, expectedStatus: http.StatusServiceUnavailable},
+		{name: "unexpected error", err: errors.New("boom"), expectedStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, message := calculationError(tt.err)
+
+			assert.Equal(t, tt.expectedStatus, status)
+			assert.NotEmpty(t, message)
+		})
+	}
+}
+
+func TestSetRetryAfter(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{name: "saturated", err: &domain.SaturatedError{RetryAfter: 2 * time.Second}, expected: "2"},
+		{name: "rounds up", err: fmt.Errorf("wrapped: %w", &domain.SaturatedError{RetryAfter: 1500 * time.Millisecond}), expected: "2"},
+		{name: "other errors", err: domain.ErrInfeasible, expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+
+			setRetryAfter(w, tt.err)
+
+			assert.Equal(t, tt.expected, w.Header().Get("Retry-After"))
+			if tt.expected != "" {
+				assert.Equal(t, "saturated", calculationErrorCode(tt.err))
+			}
+		})
+	}
+}
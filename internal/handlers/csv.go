@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/luisfernandomoraes/order-packing-api/internal/domain"
+	"github.com/luisfernandomoraes/order-packing-api/internal/response"
+)
+
+// csvCalculation is a calculated order ready to be written as a CSV record
+type csvCalculation struct {
+	orderID  string
+	quantity string
+	result   *CalculateResponse
+	err      string
+}
+
+// handleCSV calculates every order_id,quantity record of a CSV request body and
+// writes the results back as CSV, one record per order. Records that cannot be
+// calculated carry the error message in the last column, unless the server is
+// saturated, which fails the whole request like a single calculation.
+func (h *CalculateHandler) handleCSV(w http.ResponseWriter, r *http.Request) {
+	records, err := response.DecodeCSV(r)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid CSV body")
+		return
+	}
+
+	if len(records) > 0 && strings.EqualFold(strings.TrimSpace(records[0][0]), "order_id") {
+		records = records[1:]
+	}
+
+	if len(records) == 0 {
+		response.Error(w, http.StatusBadRequest, "Orders cannot be empty")
+		return
+	}
+
+	if len(records) > maxBatchOrders {
+		response.Error(w, http.StatusBadRequest, fmt.Sprintf("CSV cannot contain more than %d orders", maxBatchOrders))
+		return
+	}
+
+	if len(records[0]) != 2 {
+		response.Error(w, http.StatusBadRequest, "CSV records must have the columns order_id,quantity")
+		return
+	}
+
+	calculations := make([]csvCalculation, len(records))
+	orders := make([]int, 0, len(records))
+	pending := make([]int, 0, len(records))
+
+	for i, record := range records {
+		calculations[i] = csvCalculation{orderID: record[0], quantity: record[1]}
+
+		order, err := strconv.Atoi(strings.TrimSpace(record[1]))
+		if err != nil {
+			calculations[i].err = "Invalid quantity"
+			continue
+		}
+
+		orders = append(orders, order)
+		pending = append(pending, i)
+	}
+
+	ctx, cancel := h.calculator.Budget(r.Context())
+	defer cancel()
+
+	results := h.calculator.CalculateBatch(ctx, orders, runtime.GOMAXPROCS(0))
+	for j, result := range results {
+		calculation := &calculations[pending[j]]
+
+		ranked, err := result.Result, result.Err
+		if err == nil {
+			ranked, err = h.calculator.Rank(ctx, ranked, h.strategy)
+		}
+		if err != nil {
+			status, message := calculationError(err)
+			if errors.Is(err, domain.ErrSaturated) {
+				setRetryAfter(w, err)
+				response.ErrorWithCode(w, status, calculationErrorCode(err), message)
+				return
+			}
+
+			calculation.err = message
+			continue
+		}
+
+		calculated := newCalculateResponse(ranked)
+		calculation.result = &calculated
+	}
+
+	writeCalculationsCSV(w, calculations, h.calculator.GetPackSizes())
+}
+
+// writeCalculationsCSV writes calculated orders as CSV with the columns
+// order_id, quantity, total_items, surplus, total_packs, total_parcels when the
+// packs were grouped into parcels, one pack_<size> column per pack size and error
+func writeCalculationsCSV(w http.ResponseWriter, calculations []csvCalculation, packSizes []int) {
+	for _, calculation := range calculations {
+		if calculation.result != nil {
+			packSizes = calculation.result.PackSizes
+			break
+		}
+	}
+	packSizes = slices.Compact(slices.Clone(packSizes))
+
+	parcels := slices.ContainsFunc(calculations, func(calculation csvCalculation) bool {
+		return calculation.result != nil && calculation.result.TotalParcels > 0
+	})
+
+	header := []string{"order_id", "quantity", "total_items", "surplus", "total_packs"}
+	if parcels {
+		header = append(header, "total_parcels")
+	}
+	for _, size := range packSizes {
+		header = append(header, "pack_"+strconv.Itoa(size))
+	}
+	header = append(header, "error")
+
+	records := make([][]string, len(calculations))
+	for i, calculation := range calculations {
+		record := make([]string, 0, len(header))
+		record = append(record, calculation.orderID, calculation.quantity)
+
+		if calculation.result == nil {
+			record = append(record, make([]string, len(header)-3)...)
+			record = append(record, calculation.err)
+			records[i] = record
+			continue
+		}
+
+		result := calculation.result
+		record = append(record,
+			strconv.Itoa(result.TotalItems),
+			strconv.Itoa(result.Surplus),
+			strconv.Itoa(result.TotalPacks),
+		)
+		if parcels {
+			record = append(record, strconv.Itoa(result.TotalParcels))
+		}
+		for _, size := range packSizes {
+			record = append(record, strconv.Itoa(result.Packs[size]))
+		}
+		record = append(record, "")
+
+		records[i] = record
+	}
+
+	response.CSV(w, http.StatusOK, header, records)
+}
@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/luisfernandomoraes/order-packing-api/internal/domain"
+)
+
+func TestCalculateHandler_HandleCSV(t *testing.T) {
+	t.Run("should calculate every CSV record and answer with CSV", func(t *testing.T) {
+		handler := NewCalculateHandler(domain.NewPackCalculator([]int{250, 500, 1000, 2000, 5000}))
+
+		body := "order_id,quantity\nPO-1,501\nPO-2,12001\nPO-3,-1\nPO-4,abc\n"
+		req := httptest.NewRequest(http.MethodPost, "/calculate", strings.NewReader(body))
+		req.Header.Set("Content-Type", "text/csv")
+		w := httptest.NewRecorder()
+
+		handler.Handle(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+
+		records := readCSV(t, w.Body.String())
+		assert.Equal(t, [][]string{
+			{"order_id", "quantity", "total_items", "surplus", "total_packs", "pack_250", "pack_500", "pack_1000", "pack_2000", "pack_5000", "error"},
+			{"PO-1", "501", "750", "249", "2", "1", "1", "0", "0", "0", ""},
+			{"PO-2", "12001", "12250", "249", "4", "1", "0", "0", "1", "2", ""},
+			{"PO-3", "-1", "", "", "", "", "", "", "", "", "Order must be positive"},
+			{"PO-4", "abc", "", "", "", "", "", "", "", "", "Invalid quantity"},
+		}, records)
+	})
+
+	t.Run("should answer 503 when the records cannot be admitted", func(t *testing.T) {
+		handler := NewCalculateHandler(saturatedCalculator(t))
+
+		req := httptest.NewRequest(http.MethodPost, "/calculate", strings.NewReader("PO-1,501\nPO-2,12001\n"))
+		req.Header.Set("Content-Type", "text/csv")
+		w := httptest.NewRecorder()
+
+		handler.Handle(w, req)
+
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Equal(t, "1", w.Header().Get("Retry-After"))
+
+		var errorResponse map[string]string
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&errorResponse))
+		assert.Equal(t, "saturated", errorResponse["code"])
+	})
+
+	t.Run("should rank with the handler's default strategy", func(t *testing.T) {
+		handler := NewCalculateHandler(domain.NewPackCalculator([]int{250, 500, 1000}), WithStrategy(domain.FewestDistinctSizes))
+
+		req := httptest.NewRequest(http.MethodPost, "/calculate", strings.NewReader("A,501\n"))
+		req.Header.Set("Content-Type", "text/csv")
+		w := httptest.NewRecorder()
+
+		handler.Handle(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		records := readCSV(t, w.Body.String())
+		require.Len(t, records, 2)
+		assert.Equal(t, []string{"A", "501", "750", "249", "3", "3", "0", "0", ""}, records[1])
+	})
+
+	t.Run("should count the parcels when they are limited", func(t *testing.T) {
+		calculator := domain.NewPackCalculator([]int{250, 500})
+		calculator.UpdateParcelSpec(domain.ParcelSpec{Weights: map[int]int{250: 3000, 500: 5500}, MaxWeight: 6000})
+		handler := NewCalculateHandler(calculator)
+
+		req := httptest.NewRequest(http.MethodPost, "/calculate", strings.NewReader("A,751\n"))
+		req.Header.Set("Content-Type", "text/csv")
+		w := httptest.NewRecorder()
+
+		handler.Handle(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, [][]string{
+			{"order_id", "quantity", "total_items", "surplus", "total_packs", "total_parcels", "pack_250", "pack_500", "error"},
+			{"A", "751", "1000", "249", "2", "2", "0", "2", ""},
+		}, readCSV(t, w.Body.String()))
+	})
+
+	t.Run("should accept records without a header row", func(t *testing.T) {
+		handler := NewCalculateHandler(domain.NewPackCalculator([]int{250, 500}))
+
+		req := httptest.NewRequest(http.MethodPost, "/calculate", strings.NewReader("A,251\n"))
+		req.Header.Set("Content-Type", "text/csv; charset=utf-8")
+		w := httptest.NewRecorder()
+
+		handler.Handle(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		records := readCSV(t, w.Body.String())
+		require.Len(t, records, 2)
+		assert.Equal(t, []string{"A", "251", "500", "249", "1", "0", "1", ""}, records[1])
+	})
+
+	tests := []struct {
+		name          string
+		body          string
+		expectedError string
+	}{
+		{
+			name:          "should reject malformed CSV",
+			body:          "order_id,quantity\nA,1,extra\n",
+			expectedError: "Invalid CSV body",
+		},
+		{
+			name:          "should reject CSV without records",
+			body:          "order_id,quantity\n",
+			expectedError: "Orders cannot be empty",
+		},
+		{
+			name:          "should reject records with the wrong columns",
+			body:          "A\nB\n",
+			expectedError: "CSV records must have the columns order_id,quantity",
+		},
+		{
+			name:          "should reject oversized CSV",
+			body:          strings.Repeat("A,1\n", maxBatchOrders+1),
+			expectedError: "CSV cannot contain more than 1000 orders",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewCalculateHandler(domain.NewPackCalculator([]int{250, 500}))
+
+			req := httptest.NewRequest(http.MethodPost, "/calculate", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "text/csv")
+			w := httptest.NewRecorder()
+
+			handler.Handle(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+
+			var errorResponse map[string]string
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&errorResponse))
+			assert.Equal(t, tt.expectedError, errorResponse["error"])
+		})
+	}
+
+	t.Run("should answer a JSON request with CSV when asked to", func(t *testing.T) {
+		handler := NewCalculateHandler(domain.NewPackCalculator([]int{250, 500, 1000}))
+
+		req := httptest.NewRequest(http.MethodPost, "/calculate", bytes.NewBufferString(`{"order": 501}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json;q=0.5, text/csv")
+		w := httptest.NewRecorder()
+
+		handler.Handle(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, [][]string{
+			{"order_id", "quantity", "total_items", "surplus", "total_packs", "pack_250", "pack_500", "pack_1000", "error"},
+			{"", "501", "750", "249", "2", "1", "1", "0", ""},
+		}, readCSV(t, w.Body.String()))
+	})
+}
+
+func readCSV(t *testing.T, body string) [][]string {
+	t.Helper()
+
+	records, err := csv.NewReader(strings.NewReader(body)).ReadAll()
+	require.NoError(t, err)
+
+	return records
+}
@@ -0,0 +1,242 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/luisfernandomoraes/order-packing-api/internal/domain"
+	"github.com/luisfernandomoraes/order-packing-api/internal/response"
+)
+
+// InventoryHandler handles the /api/inventory endpoints
+type InventoryHandler struct {
+	inventory *domain.Inventory
+}
+
+// NewInventoryHandler creates a new InventoryHandler
+func NewInventoryHandler(inventory *domain.Inventory) *InventoryHandler {
+	return &InventoryHandler{
+		inventory: inventory,
+	}
+}
+
+// StockLevelResponse represents the stock of a single pack size
+type StockLevelResponse struct {
+	PackSize  int `json:"pack_size" example:"500"`
+	OnHand    int `json:"on_hand" example:"10"`
+	Reserved  int `json:"reserved" example:"2"`
+	Available int `json:"available" example:"8"`
+}
+
+// InventoryResponse represents the stock levels of every pack size
+type InventoryResponse struct {
+	Levels []StockLevelResponse `json:"levels"`
+}
+
+// ReceiveStockRequest represents the request body for receiving stock
+type ReceiveStockRequest struct {
+	PackSize int `json:"pack_size" example:"500"`
+	Count    int `json:"count" example:"10"`
+}
+
+// ReservationRequest represents the request body for reserving packs
+type ReservationRequest struct {
+	Packs map[int]int `json:"packs" example:"250:1,500:1"`
+}
+
+// ReservationResponse represents a reservation of packs
+type ReservationResponse struct {
+	ID        string      `json:"id" example:"rsv-1"`
+	Packs     map[int]int `json:"packs" example:"250:1,500:1"`
+	CreatedAt time.Time   `json:"created_at" example:"2025-01-31T12:00:00Z"`
+}
+
+// ReservationsResponse represents the open reservations
+type ReservationsResponse struct {
+	Reservations []ReservationResponse `json:"reservations"`
+}
+
+// HandleLevels godoc
+// @Summary Get stock levels
+// @Description Returns the packs on hand, reserved and available for every pack size ever received. The inventory is kept in memory and is lost on restart.
+// @Tags inventory
+// @Produce json
+// @Success 200 {object} InventoryResponse
+// @Failure 405 {object} map[string]string "Method Not Allowed"
+// @Router /api/inventory [get]
+func (h *InventoryHandler) HandleLevels(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		response.Error(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	response.JSON(w, http.StatusOK, h.levels())
+}
+
+// HandleReceive godoc
+// @Summary Receive stock
+// @Description Adds packs of a size to the stock on hand and returns the new stock levels
+// @Tags inventory
+// @Accept json
+// @Produce json
+// @Param request body ReceiveStockRequest true "Packs received"
+// @Success 200 {object} InventoryResponse
+// @Failure 400 {object} map[string]string "Bad Request - Invalid body or non-positive pack size or count"
+// @Failure 405 {object} map[string]string "Method Not Allowed"
+// @Router /api/inventory/receive [post]
+func (h *InventoryHandler) HandleReceive(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		response.Error(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req ReceiveStockRequest
+
+	if err := response.DecodeJSON(r, &req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := h.inventory.Receive(req.PackSize, req.Count); err != nil {
+		response.Error(w, http.StatusBadRequest, "Pack size and count must be positive")
+		return
+	}
+
+	response.JSON(w, http.StatusOK, h.levels())
+}
+
+// HandleReservations acts as a router for GET and POST methods on /api/inventory/reservations
+func (h *InventoryHandler) HandleReservations(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.handleListReservations(w, r)
+	case http.MethodPost:
+		h.handleReserve(w, r)
+	default:
+		response.Error(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// handleListReservations godoc
+// @Summary List reservations
+// @Description Returns the reservations that are neither confirmed nor released, oldest first
+// @Tags inventory
+// @Produce json
+// @Success 200 {object} ReservationsResponse
+// @Router /api/inventory/reservations [get]
+func (h *InventoryHandler) handleListReservations(w http.ResponseWriter, _ *http.Request) {
+	reservations := h.inventory.Reservations()
+
+	responseData := ReservationsResponse{
+		Reservations: make([]ReservationResponse, len(reservations)),
+	}
+	for i, reservation := range reservations {
+		responseData.Reservations[i] = newReservationResponse(reservation)
+	}
+
+	response.JSON(w, http.StatusOK, responseData)
+}
+
+// handleReserve godoc
+// @Summary Reserve packs
+// @Description Reserves the packs of a calculated result. Either every pack is reserved or none is, so concurrent reservations can never claim the same packs.
+// @Tags inventory
+// @Accept json
+// @Produce json
+// @Param request body ReservationRequest true "Packs to reserve"
+// @Success 201 {object} ReservationResponse
+// @Failure 400 {object} map[string]string "Bad Request - Invalid body, empty reservation or negative counts"
+// @Failure 409 {object} map[string]string "Not enough packs available"
+// @Router /api/inventory/reservations [post]
+func (h *InventoryHandler) handleReserve(w http.ResponseWriter, r *http.Request) {
+	var req ReservationRequest
+
+	if err := response.DecodeJSON(r, &req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	reservation, err := h.inventory.Reserve(req.Packs)
+	if errors.Is(err, domain.ErrInsufficientStock) {
+		response.Error(w, http.StatusConflict, "Not enough packs available")
+		return
+	}
+	if err != nil {
+		status, message := calculationError(err)
+		response.Error(w, status, message)
+		return
+	}
+
+	response.JSON(w, http.StatusCreated, newReservationResponse(reservation))
+}
+
+// HandleConfirm godoc
+// @Summary Confirm a reservation
+// @Description Settles a reservation as shipped, removing its packs from the stock on hand
+// @Tags inventory
+// @Produce json
+// @Param id path string true "Reservation ID"
+// @Success 200 {object} ReservationResponse
+// @Failure 404 {object} map[string]string "Reservation not found"
+// @Failure 405 {object} map[string]string "Method Not Allowed"
+// @Router /api/inventory/reservations/{id}/confirm [post]
+func (h *InventoryHandler) HandleConfirm(w http.ResponseWriter, r *http.Request) {
+	h.settle(w, r, h.inventory.Confirm)
+}
+
+// HandleRelease godoc
+// @Summary Release a reservation
+// @Description Cancels a reservation, making its packs available again
+// @Tags inventory
+// @Produce json
+// @Param id path string true "Reservation ID"
+// @Success 200 {object} ReservationResponse
+// @Failure 404 {object} map[string]string "Reservation not found"
+// @Failure 405 {object} map[string]string "Method Not Allowed"
+// @Router /api/inventory/reservations/{id}/release [post]
+func (h *InventoryHandler) HandleRelease(w http.ResponseWriter, r *http.Request) {
+	h.settle(w, r, h.inventory.Release)
+}
+
+func (h *InventoryHandler) settle(w http.ResponseWriter, r *http.Request, settle func(string) (domain.Reservation, error)) {
+	if r.Method != http.MethodPost {
+		response.Error(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	reservation, err := settle(r.PathValue("id"))
+	if err != nil {
+		status, message := calculationError(err)
+		response.Error(w, status, message)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, newReservationResponse(reservation))
+}
+
+func (h *InventoryHandler) levels() InventoryResponse {
+	levels := h.inventory.Levels()
+
+	responseData := InventoryResponse{
+		Levels: make([]StockLevelResponse, len(levels)),
+	}
+	for i, level := range levels {
+		responseData.Levels[i] = StockLevelResponse{
+			PackSize:  level.PackSize,
+			OnHand:    level.OnHand,
+			Reserved:  level.Reserved,
+			Available: level.Available,
+		}
+	}
+
+	return responseData
+}
+
+func newReservationResponse(reservation domain.Reservation) ReservationResponse {
+	return ReservationResponse{
+		ID:        reservation.ID,
+		Packs:     reservation.Packs,
+		CreatedAt: reservation.CreatedAt,
+	}
+}
@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/luisfernandomoraes/order-packing-api/internal/domain"
+)
+
+func TestInventoryHandler_MethodRouting(t *testing.T) {
+	handler := NewInventoryHandler(domain.NewInventory())
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		handle http.HandlerFunc
+	}{
+		{name: "levels", method: http.MethodPost, target: "/api/inventory", handle: handler.HandleLevels},
+		{name: "receive", method: http.MethodGet, target: "/api/inventory/receive", handle: handler.HandleReceive},
+		{name: "reservations", method: http.MethodDelete, target: "/api/inventory/reservations", handle: handler.HandleReservations},
+		{name: "confirm", method: http.MethodGet, target: "/api/inventory/reservations/rsv-1/confirm", handle: handler.HandleConfirm},
+		{name: "release", method: http.MethodGet, target: "/api/inventory/reservations/rsv-1/release", handle: handler.HandleRelease},
+	}
+
+	for _, tt := range tests {
+		t.Run("should reject "+tt.method+" on "+tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, nil)
+			w := httptest.NewRecorder()
+
+			tt.handle(w, req)
+
+			assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+		})
+	}
+}
+
+func TestInventoryHandler_HandleReceive(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+	}{
+		{name: "valid stock", body: `{"pack_size": 500, "count": 3}`, expectedStatus: http.StatusOK},
+		{name: "zero count", body: `{"pack_size": 500, "count": 0}`, expectedStatus: http.StatusBadRequest},
+		{name: "negative pack size", body: `{"pack_size": -1, "count": 3}`, expectedStatus: http.StatusBadRequest},
+		{name: "invalid JSON", body: `{"pack_size":`, expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewInventoryHandler(domain.NewInventory())
+			req := httptest.NewRequest(http.MethodPost, "/api/inventory/receive", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			handler.HandleReceive(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+
+	t.Run("should return the new stock levels", func(t *testing.T) {
+		handler := NewInventoryHandler(domain.NewInventory())
+
+		for range 2 {
+			req := httptest.NewRequest(http.MethodPost, "/api/inventory/receive", bytes.NewBufferString(`{"pack_size": 250, "count": 2}`))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			handler.HandleReceive(w, req)
+			require.Equal(t, http.StatusOK, w.Code)
+		}
+
+		req := httptest.NewRequest(http.MethodGet, "/api/inventory", nil)
+		w := httptest.NewRecorder()
+
+		handler.HandleLevels(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var responseData InventoryResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&responseData))
+		assert.Equal(t, []StockLevelResponse{{PackSize: 250, OnHand: 4, Reserved: 0, Available: 4}}, responseData.Levels)
+	})
+}
+
+func TestInventoryHandler_Reservations(t *testing.T) {
+	newHandler := func(t *testing.T) (*InventoryHandler, *domain.Inventory) {
+		t.Helper()
+
+		inventory := domain.NewInventory()
+		require.NoError(t, inventory.Receive(250, 2))
+		require.NoError(t, inventory.Receive(500, 1))
+
+		return NewInventoryHandler(inventory), inventory
+	}
+
+	reserve := func(handler *InventoryHandler, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/inventory/reservations", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		handler.HandleReservations(w, req)
+		return w
+	}
+
+	settle := func(handle http.HandlerFunc, id string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/inventory/reservations/"+id+"/confirm", nil)
+		req.SetPathValue("id", id)
+		w := httptest.NewRecorder()
+
+		handle(w, req)
+		return w
+	}
+
+	t.Run("should reserve packs and list the reservation", func(t *testing.T) {
+		handler, inventory := newHandler(t)
+
+		w := reserve(handler, `{"packs": {"250": 1, "500": 1}}`)
+
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		var created ReservationResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&created))
+		assert.Equal(t, "rsv-1", created.ID)
+		assert.Equal(t, map[int]int{250: 1, 500: 1}, created.Packs)
+		assert.Equal(t, map[int]int{250: 1, 500: 0}, inventory.Available([]int{250, 500}))
+
+		req := httptest.NewRequest(http.MethodGet, "/api/inventory/reservations", nil)
+		w = httptest.NewRecorder()
+
+		handler.HandleReservations(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var listed ReservationsResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&listed))
+		require.Len(t, listed.Reservations, 1)
+		assert.Equal(t, "rsv-1", listed.Reservations[0].ID)
+	})
+
+	t.Run("should reject invalid reservations", func(t *testing.T) {
+		tests := []struct {
+			name           string
+			body           string
+			expectedStatus int
+		}{
+			{name: "more than available", body: `{"packs": {"500": 2}}`, expectedStatus: http.StatusConflict},
+			{name: "unknown pack size", body: `{"packs": {"1000": 1}}`, expectedStatus: http.StatusConflict},
+			{name: "empty", body: `{"packs": {}}`, expectedStatus: http.StatusBadRequest},
+			{name: "negative count", body: `{"packs": {"250": -1}}`, expectedStatus: http.StatusBadRequest},
+			{name: "invalid JSON", body: `{"packs":`, expectedStatus: http.StatusBadRequest},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				handler, _ := newHandler(t)
+
+				w := reserve(handler, tt.body)
+
+				assert.Equal(t, tt.expectedStatus, w.Code)
+			})
+		}
+	})
+
+	t.Run("should remove confirmed packs from the stock on hand", func(t *testing.T) {
+		handler, inventory := newHandler(t)
+		require.Equal(t, http.StatusCreated, reserve(handler, `{"packs": {"250": 1}}`).Code)
+
+		w := settle(handler.HandleConfirm, "rsv-1")
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, []domain.StockLevel{
+			{PackSize: 250, OnHand: 1, Reserved: 0, Available: 1},
+			{PackSize: 500, OnHand: 1, Reserved: 0, Available: 1},
+		}, inventory.Levels())
+
+		w = settle(handler.HandleConfirm, "rsv-1")
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("should return released packs to the available stock", func(t *testing.T) {
+		handler, inventory := newHandler(t)
+		require.Equal(t, http.StatusCreated, reserve(handler, `{"packs": {"500": 1}}`).Code)
+
+		w := settle(handler.HandleRelease, "rsv-1")
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, map[int]int{250: 2, 500: 1}, inventory.Available([]int{250, 500}))
+		assert.Empty(t, inventory.Reservations())
+	})
+
+	t.Run("should report unknown reservations as not found", func(t *testing.T) {
+		handler, _ := newHandler(t)
+
+		w := settle(handler.HandleRelease, "rsv-42")
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/luisfernandomoraes/order-packing-api/internal/domain"
+	"github.com/luisfernandomoraes/order-packing-api/internal/response"
+)
+
+// MetricsHandler handles the /metrics endpoint
+type MetricsHandler struct {
+	calculator *domain.PackCalculator
+}
+
+// NewMetricsHandler creates a new MetricsHandler
+func NewMetricsHandler(calculator *domain.PackCalculator) *MetricsHandler {
+	return &MetricsHandler{calculator: calculator}
+}
+
+// metric is a single sample in the Prometheus text format.
+type metric struct {
+	name  string
+	kind  string
+	help  string
+	value int64
+}
+
+// Handle godoc
+// @Summary Calculation metrics
+// @Description Returns the usage of the calculation limiter and the result cache in the Prometheus text format
+// @Tags health
+// @Produce plain
+// @Success 200 {string} string "Metrics"
+// @Failure 405 {object} map[string]string "Method Not Allowed"
+// @Router /metrics [get]
+func (h *MetricsHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		response.Error(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var limiter domain.LimiterStats
+	if l := h.calculator.GetLimits().Limiter; l != nil {
+		limiter = l.Stats()
+	}
+	cache := h.calculator.CacheStats()
+
+	metrics := []metric{
+		{"calculation_capacity", "gauge", "Table quantities that may be calculated at once (0 when unlimited).", limiter.Capacity},
+		{"calculation_in_use", "gauge", "Table quantities held by running calculations.", limiter.InUse},
+		{"calculation_waiting", "gauge", "Calculations waiting for capacity.", limiter.Waiting},
+		{"calculation_admitted_total", "counter", "Calculations admitted by the limiter.", limiter.Admitted},
+		{"calculation_rejected_total", "counter", "Calculations rejected because the server was saturated.", limiter.Rejected},
+		{"result_cache_hits_total", "counter", "Results served from the result cache.", cache.Hits},
+		{"result_cache_misses_total", "counter", "Results not found in the result cache.", cache.Misses},
+		{"result_cache_size", "gauge", "Results held in the result cache.", int64(cache.Size)},
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	for _, m := range metrics {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n%s %d\n", m.name, m.help, m.name, m.kind, m.name, m.value)
+	}
+}
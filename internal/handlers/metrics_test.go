@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/luisfernandomoraes/order-packing-api/internal/domain"
+)
+
+func TestMetricsHandler_Handle(t *testing.T) {
+	t.Run("should report the limiter and the result cache", func(t *testing.T) {
+		calculator := domain.NewPackCalculator([]int{250, 500, 1000},
+			domain.WithResultCache(10),
+			domain.WithLimits(domain.Limits{Limiter: domain.NewLimiter(1000, time.Second)}),
+		)
+		_, err := calculator.CalculateContext(context.Background(), 1001)
+		require.NoError(t, err)
+		_, err = calculator.CalculateContext(context.Background(), 1001)
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		w := httptest.NewRecorder()
+
+		NewMetricsHandler(calculator).Handle(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Header().Get("Content-Type"), "text/plain")
+
+		body := w.Body.String()
+		assert.Contains(t, body, "# TYPE calculation_capacity gauge\ncalculation_capacity 1000\n")
+		assert.Contains(t, body, "calculation_in_use 0\n")
+		assert.Contains(t, body, "calculation_waiting 0\n")
+		assert.Contains(t, body, "calculation_admitted_total 1\n")
+		assert.Contains(t, body, "calculation_rejected_total 0\n")
+		assert.Contains(t, body, "result_cache_hits_total 1\n")
+		assert.Contains(t, body, "result_cache_misses_total 1\n")
+		assert.Contains(t, body, "result_cache_size 1\n")
+	})
+
+	t.Run("should report zeros without a limiter", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		w := httptest.NewRecorder()
+
+		NewMetricsHandler(domain.NewPackCalculator([]int{250})).Handle(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "calculation_capacity 0\n")
+	})
+
+	t.Run("should reject other methods", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/metrics", nil)
+		w := httptest.NewRecorder()
+
+		NewMetricsHandler(domain.NewPackCalculator([]int{250})).Handle(w, req)
+
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	})
+}
@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/luisfernandomoraes/order-packing-api/internal/domain"
+	"github.com/luisfernandomoraes/order-packing-api/internal/response"
+)
+
+// maxOrderLines is the maximum number of lines accepted in a single order
+const maxOrderLines = 100
+
+// OrdersHandler handles the /api/orders endpoint
+type OrdersHandler struct {
+	calculator *domain.PackCalculator
+	catalog    *domain.Catalog
+	strategy   domain.Strategy
+}
+
+// OrdersOption configures an OrdersHandler
+type OrdersOption func(*OrdersHandler)
+
+// WithOrdersStrategy ranks the packs of every line with the given strategy
+// instead of the fewest packs
+func WithOrdersStrategy(strategy domain.Strategy) OrdersOption {
+	return func(h *OrdersHandler) {
+		h.strategy = strategy
+	}
+}
+
+// NewOrdersHandler creates a new OrdersHandler. Lines without their own pack
+// sizes or SKU are packed with the calculator's pack sizes; lines with a SKU
+// are packed with that product's pack sizes from the catalog, which may be nil.
+func NewOrdersHandler(calculator *domain.PackCalculator, catalog *domain.Catalog, opts ...OrdersOption) *OrdersHandler {
+	h := &OrdersHandler{
+		calculator: calculator,
+		catalog:    catalog,
+	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
+}
+
+// OrderLine represents a single product line of a customer order.
+// At most one of PackSizes and SKU may be set.
+type OrderLine struct {
+	Quantity  int    `json:"quantity" example:"501" minimum:"0"`
+	PackSizes []int  `json:"pack_sizes,omitempty" example:"250,500,1000"`
+	SKU       string `json:"sku,omitempty" example:"WIDGET-01"`
+}
+
+// OrderRequest represents the request body for the orders endpoint
+type OrderRequest struct {
+	Lines []OrderLine `json:"lines"`
+}
+
+// OrderLineResponse represents the packing of a single order line
+type OrderLineResponse struct {
+	Line int    `json:"line" example:"1"`
+	SKU  string `json:"sku,omitempty" example:"WIDGET-01"`
+	CalculateResponse
+}
+
+// OrderResponse represents the response from the orders endpoint
+type OrderResponse struct {
+	Lines         []OrderLineResponse `json:"lines"`
+	TotalQuantity int                 `json:"total_quantity" example:"764"`
+	TotalItems    int                 `json:"total_items" example:"1013"`
+	TotalPacks    int                 `json:"total_packs" example:"9"`
+	TotalSurplus  int                 `json:"total_surplus" example:"249"`
+	TotalCost     int                 `json:"total_cost" example:"0"`
+}
+
+// Handle godoc
+// @Summary Calculate optimal package combinations for a multi-line order
+// @Description Packs every line of a customer order and returns the per-line results with order-level totals. Each line is packed with its own pack_sizes, with the pack sizes of its sku, or with the global pack sizes.
+// @Description The order succeeds or fails as a whole: the first line that cannot be packed fails the request and the error names the line, numbered from 1.
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param request body OrderRequest true "Order lines"
+// @Success 200 {object} OrderResponse
+// @Failure 400 {object} map[string]string "Bad Request - Invalid body, empty or oversized order, invalid line"
+// @Failure 404 {object} map[string]string "Product not found"
+// @Failure 405 {object} map[string]string "Method Not Allowed"
+// @Failure 422 {object} map[string]string "Unprocessable Entity - A line cannot be packed"
+// @Failure 503 {object} map[string]string "Service Unavailable - Calculation cancelled or timed out"
+// @Router /api/orders [post]
+func (h *OrdersHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		response.Error(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req OrderRequest
+
+	if err := response.DecodeJSON(r, &req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if len(req.Lines) == 0 {
+		response.Error(w, http.StatusBadRequest, "Order lines cannot be empty")
+		return
+	}
+
+	if len(req.Lines) > maxOrderLines {
+		response.Error(w, http.StatusBadRequest, fmt.Sprintf("Order cannot contain more than %d lines", maxOrderLines))
+		return
+	}
+
+	ctx, cancel := h.calculator.Budget(r.Context())
+	defer cancel()
+
+	responseData := OrderResponse{
+		Lines: make([]OrderLineResponse, len(req.Lines)),
+	}
+
+	for i, line := range req.Lines {
+		number := i + 1
+
+		calculator, status, message := h.lineCalculator(line)
+		if message != "" {
+			response.Error(w, status, fmt.Sprintf("Line %d: %s", number, message))
+			return
+		}
+
+		result, err := calculator.CalculateContext(ctx, line.Quantity)
+		if err == nil {
+			result, err = calculator.Rank(ctx, result, h.strategy)
+		}
+		if err != nil {
+			status, message := calculationError(err)
+			setRetryAfter(w, err)
+			response.Error(w, status, fmt.Sprintf("Line %d: %s", number, message))
+			return
+		}
+
+		calculated := newCalculateResponse(result)
+		responseData.Lines[i] = OrderLineResponse{
+			Line:              number,
+			SKU:               line.SKU,
+			CalculateResponse: calculated,
+		}
+
+		responseData.TotalQuantity += calculated.Order
+		responseData.TotalItems += calculated.TotalItems
+		responseData.TotalPacks += calculated.TotalPacks
+		responseData.TotalSurplus += calculated.Surplus
+		responseData.TotalCost += calculated.TotalCost
+	}
+
+	response.JSON(w, http.StatusOK, responseData)
+}
+
+// lineCalculator returns the calculator a line is packed with. When the line
+// cannot be packed it returns the status code and message to answer with.
+func (h *OrdersHandler) lineCalculator(line OrderLine) (*domain.PackCalculator, int, string) {
+	switch {
+	case line.PackSizes != nil && line.SKU != "":
+		return nil, http.StatusBadRequest, "Line cannot have both pack_sizes and sku"
+
+	case line.PackSizes != nil:
+		if message := validatePackSizes(line.PackSizes); message != "" {
+			return nil, http.StatusBadRequest, message
+		}
+		return domain.NewPackCalculator(line.PackSizes, domain.WithLimits(h.calculator.GetLimits())), 0, ""
+
+	case line.SKU != "":
+		if h.catalog == nil {
+			status, message := calculationError(domain.ErrProductNotFound)
+			return nil, status, message
+		}
+
+		calculator, err := h.catalog.Calculator(line.SKU)
+		if err != nil {
+			status, message := calculationError(err)
+			return nil, status, message
+		}
+		return calculator, 0, ""
+
+	default:
+		return h.calculator, 0, ""
+	}
+}
@@ -0,0 +1,246 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/luisfernandomoraes/order-packing-api/internal/domain"
+)
+
+func TestOrdersHandler_Handle_MethodRouting(t *testing.T) {
+	for _, method := range []string{http.MethodGet, http.MethodPut, http.MethodDelete, http.MethodPatch} {
+		t.Run("should reject "+method+" method", func(t *testing.T) {
+			handler := NewOrdersHandler(domain.NewPackCalculator([]int{250, 500}), nil)
+			req := httptest.NewRequest(method, "/api/orders", nil)
+			w := httptest.NewRecorder()
+
+			handler.Handle(w, req)
+
+			assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+		})
+	}
+}
+
+func TestOrdersHandler_HandlePost(t *testing.T) {
+	t.Run("should pack every line and total the order", func(t *testing.T) {
+		catalog := domain.NewCatalog()
+		_, err := catalog.Add("WIDGET", "Widget", []int{23, 31, 53})
+		require.NoError(t, err)
+
+		handler := NewOrdersHandler(domain.NewPackCalculator([]int{250, 500, 1000, 2000, 5000}), catalog)
+
+		body := `{"lines": [
+			{"quantity": 501},
+			{"quantity": 263, "sku": "WIDGET"},
+			{"quantity": 12, "pack_sizes": [5, 10]},
+			{"quantity": 0}
+		]}`
+		req := httptest.NewRequest(http.MethodPost, "/api/orders", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		handler.Handle(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var responseData OrderResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&responseData))
+		require.Len(t, responseData.Lines, 4)
+
+		first := responseData.Lines[0]
+		assert.Equal(t, 1, first.Line)
+		assert.Equal(t, 750, first.TotalItems)
+		assert.Equal(t, map[int]int{250: 1, 500: 1}, first.Packs)
+		assert.Equal(t, []int{250, 500, 1000, 2000, 5000}, first.PackSizes)
+
+		second := responseData.Lines[1]
+		assert.Equal(t, "WIDGET", second.SKU)
+		assert.Equal(t, 263, second.TotalItems)
+		assert.Equal(t, []int{23, 31, 53}, second.PackSizes)
+
+		third := responseData.Lines[2]
+		assert.Equal(t, 15, third.TotalItems)
+		assert.Equal(t, map[int]int{5: 1, 10: 1}, third.Packs)
+
+		fourth := responseData.Lines[3]
+		assert.Equal(t, 0, fourth.TotalItems)
+		assert.Empty(t, fourth.Packs)
+
+		assert.Equal(t, 501+263+12, responseData.TotalQuantity)
+		assert.Equal(t, 750+263+15, responseData.TotalItems)
+		assert.Equal(t, first.TotalPacks+second.TotalPacks+third.TotalPacks, responseData.TotalPacks)
+		assert.Equal(t, 249+0+3, responseData.TotalSurplus)
+	})
+
+	t.Run("should rank every line with the handler's strategy", func(t *testing.T) {
+		handler := NewOrdersHandler(domain.NewPackCalculator([]int{250, 500, 1000}), nil, WithOrdersStrategy(domain.FewestDistinctSizes))
+
+		req := httptest.NewRequest(http.MethodPost, "/api/orders", bytes.NewBufferString(`{"lines": [{"quantity": 501}, {"quantity": 501, "pack_sizes": [250, 500]}]}`))
+		w := httptest.NewRecorder()
+
+		handler.Handle(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var responseData OrderResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&responseData))
+		require.Len(t, responseData.Lines, 2)
+		assert.Equal(t, map[int]int{250: 3}, responseData.Lines[0].Packs)
+		assert.Equal(t, map[int]int{250: 3}, responseData.Lines[1].Packs)
+	})
+
+	t.Run("should group the packs of every line into parcels", func(t *testing.T) {
+		calculator := domain.NewPackCalculator([]int{250, 500})
+		calculator.UpdateParcelSpec(domain.ParcelSpec{Weights: map[int]int{250: 3000, 500: 5500}, MaxWeight: 6000})
+		handler := NewOrdersHandler(calculator, nil)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/orders", bytes.NewBufferString(`{"lines": [{"quantity": 751}]}`))
+		w := httptest.NewRecorder()
+
+		handler.Handle(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var responseData OrderResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&responseData))
+		require.Len(t, responseData.Lines, 1)
+		assert.Equal(t, []ParcelResponse{{Count: 2, Packs: map[int]int{500: 1}, Weight: 5500}}, responseData.Lines[0].Parcels)
+	})
+
+	t.Run("should share one compute budget between the lines", func(t *testing.T) {
+		calculator := domain.NewPackCalculator([]int{250, 500}, domain.WithLimits(domain.Limits{MaxIterations: 150_000}))
+		handler := NewOrdersHandler(calculator, nil)
+
+		for _, body := range []string{
+			`{"lines": [{"quantity": 100000, "pack_sizes": [997, 1009, 4999]}]}`,
+			`{"lines": [{"quantity": 100000, "pack_sizes": [991, 1013]}]}`,
+		} {
+			w := httptest.NewRecorder()
+			handler.Handle(w, httptest.NewRequest(http.MethodPost, "/api/orders", bytes.NewBufferString(body)))
+			require.Equal(t, http.StatusOK, w.Code, body)
+		}
+
+		req := httptest.NewRequest(http.MethodPost, "/api/orders", bytes.NewBufferString(
+			`{"lines": [{"quantity": 100000, "pack_sizes": [997, 1009, 4999]}, {"quantity": 100000, "pack_sizes": [991, 1013]}]}`))
+		w := httptest.NewRecorder()
+
+		handler.Handle(w, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+		var errorResponse map[string]string
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&errorResponse))
+		assert.Equal(t, "Line 2: Calculation exceeds the compute budget of 150000 iterations", errorResponse["error"])
+	})
+
+	t.Run("should flatten each line result", func(t *testing.T) {
+		handler := NewOrdersHandler(domain.NewPackCalculator([]int{250, 500}), nil)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/orders", bytes.NewBufferString(`{"lines": [{"quantity": 251}]}`))
+		w := httptest.NewRecorder()
+
+		handler.Handle(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{
+			"lines": [{"line": 1, "order": 251, "total_items": 500, "packs": {"500": 1}, "pack_sizes": [250, 500], "surplus": 249, "shortfall": 0, "total_packs": 1, "total_cost": 0}],
+			"total_quantity": 251,
+			"total_items": 500,
+			"total_packs": 1,
+			"total_surplus": 249,
+			"total_cost": 0
+		}`, w.Body.String())
+	})
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:           "should reject invalid JSON",
+			body:           `{"lines": [`,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Invalid request body",
+		},
+		{
+			name:           "should reject orders without lines",
+			body:           `{"lines": []}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Order lines cannot be empty",
+		},
+		{
+			name:           "should reject oversized orders",
+			body:           `{"lines": [` + strings.TrimSuffix(strings.Repeat(`{"quantity": 1},`, maxOrderLines+1), ",") + `]}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Order cannot contain more than 100 lines",
+		},
+		{
+			name:           "should report negative quantities with their line",
+			body:           `{"lines": [{"quantity": 1}, {"quantity": -5}]}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Line 2: Order must be positive",
+		},
+		{
+			name:           "should reject lines with both pack sizes and a SKU",
+			body:           `{"lines": [{"quantity": 1, "pack_sizes": [5], "sku": "WIDGET"}]}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Line 1: Line cannot have both pack_sizes and sku",
+		},
+		{
+			name:           "should reject empty line pack sizes",
+			body:           `{"lines": [{"quantity": 1, "pack_sizes": []}]}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Line 1: Pack sizes cannot be empty",
+		},
+		{
+			name:           "should reject non-positive line pack sizes",
+			body:           `{"lines": [{"quantity": 1, "pack_sizes": [5, -5]}]}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Line 1: All pack sizes must be positive",
+		},
+		{
+			name:           "should report unknown products",
+			body:           `{"lines": [{"quantity": 1, "sku": "MISSING"}]}`,
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "Line 1: Product not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewOrdersHandler(domain.NewPackCalculator([]int{250, 500}), domain.NewCatalog())
+
+			req := httptest.NewRequest(http.MethodPost, "/api/orders", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			handler.Handle(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			var errorResponse map[string]string
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&errorResponse))
+			assert.Equal(t, tt.expectedError, errorResponse["error"])
+		})
+	}
+
+	t.Run("should report products as not found without a catalog", func(t *testing.T) {
+		handler := NewOrdersHandler(domain.NewPackCalculator([]int{250}), nil)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/orders", bytes.NewBufferString(`{"lines": [{"quantity": 1, "sku": "WIDGET"}]}`))
+		w := httptest.NewRecorder()
+
+		handler.Handle(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
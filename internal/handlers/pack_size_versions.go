@@ -0,0 +1,300 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/luisfernandomoraes/order-packing-api/internal/response"
+	"github.com/luisfernandomoraes/order-packing-api/internal/storage"
+)
+
+// PackSizesVersion represents one recorded version of the pack sizes
+// configuration: the pack sizes with their costs, parcel limits and rules
+type PackSizesVersion struct {
+	Version         int                  `json:"version" example:"2"`
+	PackSizes       []int                `json:"pack_sizes" example:"250,500,1000,2000,5000"`
+	Costs           map[int]int          `json:"costs,omitempty" example:"250:120,500:200"`
+	ShipmentCost    int                  `json:"shipment_cost,omitempty" example:"500"`
+	Weights         map[int]int          `json:"weights,omitempty" example:"250:3000,500:5500"`
+	Volumes         map[int]int          `json:"volumes,omitempty" example:"250:4000,500:7500"`
+	MaxParcelWeight int                  `json:"max_parcel_weight,omitempty" example:"31500"`
+	MaxParcelVolume int                  `json:"max_parcel_volume,omitempty" example:"60000"`
+	Rules           map[int]PackSizeRule `json:"rules,omitempty"`
+	CreatedAt       time.Time            `json:"created_at" example:"2025-01-31T12:00:00Z"`
+	Author          string               `json:"author,omitempty" example:"jane.doe"`
+	Reason          string               `json:"reason,omitempty" example:"New 100 item box"`
+}
+
+// PackSizesVersionsResponse represents the response from the list versions endpoint
+type PackSizesVersionsResponse struct {
+	Current  int                `json:"current" example:"2"`
+	Versions []PackSizesVersion `json:"versions"`
+}
+
+// PackSizesDiffResponse represents the response from the diff versions endpoint.
+// Added, Removed and Unchanged compare the pack sizes, and Changes lists every
+// other setting that differs.
+type PackSizesDiffResponse struct {
+	From      int               `json:"from" example:"1"`
+	To        int               `json:"to" example:"2"`
+	Added     []int             `json:"added" example:"100"`
+	Removed   []int             `json:"removed" example:"5000"`
+	Unchanged []int             `json:"unchanged" example:"250,500,1000,2000"`
+	Changes   []PackSizesChange `json:"changes"`
+}
+
+// PackSizesChange represents a setting that differs between two versions. Field
+// is costs, shipment_cost, weights, volumes, max_parcel_weight, max_parcel_volume
+// or rules, and Size names the pack size of per-size settings. From and To are
+// null when the setting is not set in that version.
+type PackSizesChange struct {
+	Field string `json:"field" example:"costs"`
+	Size  int    `json:"size,omitempty" example:"250"`
+	From  any    `json:"from"`
+	To    any    `json:"to"`
+}
+
+// PackSizesRollbackRequest represents the request body for the rollback endpoint
+type PackSizesRollbackRequest struct {
+	Version int    `json:"version" example:"1"`
+	Author  string `json:"author,omitempty" example:"jane.doe"`
+	Reason  string `json:"reason,omitempty" example:"New sizes broke the warehouse labels"`
+}
+
+// HandleVersions godoc
+// @Summary List pack sizes versions
+// @Description Returns every recorded version of the pack sizes with its costs, parcel limits and rules, oldest first, and the current version number
+// @Tags pack-sizes
+// @Produce json
+// @Success 200 {object} PackSizesVersionsResponse
+// @Failure 405 {object} map[string]string "Method Not Allowed"
+// @Failure 500 {object} map[string]string "Versions could not be loaded"
+// @Router /api/pack-sizes/versions [get]
+func (h *PackSizesHandler) HandleVersions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		response.Error(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	versions, err := h.repository.Versions()
+	if err != nil {
+		log.Printf("Failed to load pack sizes versions: %v", err)
+		response.Error(w, http.StatusInternalServerError, "Failed to load pack sizes versions")
+		return
+	}
+
+	responseData := PackSizesVersionsResponse{
+		Versions: make([]PackSizesVersion, len(versions)),
+	}
+	for i, version := range versions {
+		responseData.Versions[i] = newPackSizesVersion(version)
+		responseData.Current = version.Version
+	}
+
+	response.JSON(w, http.StatusOK, responseData)
+}
+
+// HandleDiff godoc
+// @Summary Compare two pack sizes versions
+// @Description Returns the pack sizes added, removed and kept when going from one version to another, and every cost, weight, volume, parcel limit and rule that changed
+// @Tags pack-sizes
+// @Produce json
+// @Param from query int true "Version to compare from"
+// @Param to query int true "Version to compare to"
+// @Success 200 {object} PackSizesDiffResponse
+// @Failure 400 {object} map[string]string "Bad Request - Missing or invalid version numbers"
+// @Failure 404 {object} map[string]string "Version not found"
+// @Failure 405 {object} map[string]string "Method Not Allowed"
+// @Router /api/pack-sizes/diff [get]
+func (h *PackSizesHandler) HandleDiff(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		response.Error(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	from, errFrom := strconv.Atoi(r.URL.Query().Get("from"))
+	to, errTo := strconv.Atoi(r.URL.Query().Get("to"))
+	if errFrom != nil || errTo != nil {
+		response.Error(w, http.StatusBadRequest, "Query parameters from and to must be version numbers")
+		return
+	}
+
+	fromVersion, ok := h.loadVersion(w, from)
+	if !ok {
+		return
+	}
+
+	toVersion, ok := h.loadVersion(w, to)
+	if !ok {
+		return
+	}
+
+	responseData := PackSizesDiffResponse{
+		From:      from,
+		To:        to,
+		Added:     []int{},
+		Removed:   []int{},
+		Unchanged: []int{},
+		Changes:   diffSettings(fromVersion, toVersion),
+	}
+	for _, size := range toVersion.PackSizes {
+		if slices.Contains(fromVersion.PackSizes, size) {
+			responseData.Unchanged = append(responseData.Unchanged, size)
+		} else {
+			responseData.Added = append(responseData.Added, size)
+		}
+	}
+	for _, size := range fromVersion.PackSizes {
+		if !slices.Contains(toVersion.PackSizes, size) {
+			responseData.Removed = append(responseData.Removed, size)
+		}
+	}
+
+	response.JSON(w, http.StatusOK, responseData)
+}
+
+// HandleRollback godoc
+// @Summary Roll back to a previous pack sizes version
+// @Description Restores the pack sizes, costs, parcel limits and rules of a previous version. The rollback is recorded as a new version, so the history is never rewritten.
+// @Tags pack-sizes
+// @Accept json
+// @Produce json
+// @Param request body PackSizesRollbackRequest true "Version to restore"
+// @Success 200 {object} PackSizesUpdateResponse
+// @Failure 400 {object} map[string]string "Bad Request - Invalid request body"
+// @Failure 404 {object} map[string]string "Version not found"
+// @Failure 405 {object} map[string]string "Method Not Allowed"
+// @Failure 500 {object} map[string]string "Pack sizes could not be saved"
+// @Router /api/pack-sizes/rollback [post]
+func (h *PackSizesHandler) HandleRollback(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		response.Error(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req PackSizesRollbackRequest
+
+	if err := response.DecodeJSON(r, &req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	version, ok := h.loadVersion(w, req.Version)
+	if !ok {
+		return
+	}
+
+	reason := req.Reason
+	if reason == "" {
+		reason = fmt.Sprintf("Rollback to version %d", version.Version)
+	}
+
+	h.applyChange(w, storage.PackSizeChange{
+		PackSizes:       version.PackSizes,
+		Costs:           version.Costs,
+		ShipmentCost:    version.ShipmentCost,
+		Weights:         version.Weights,
+		Volumes:         version.Volumes,
+		MaxParcelWeight: version.MaxParcelWeight,
+		MaxParcelVolume: version.MaxParcelVolume,
+		Rules:           version.Rules,
+		Author:          req.Author,
+		Reason:          reason,
+	}, fmt.Sprintf("Pack sizes rolled back to version %d", version.Version))
+}
+
+// loadVersion loads a version from the repository, writing the error response
+// and returning false if it cannot be loaded
+func (h *PackSizesHandler) loadVersion(w http.ResponseWriter, number int) (storage.PackSizeVersion, bool) {
+	version, err := h.repository.Version(number)
+	if errors.Is(err, storage.ErrVersionNotFound) {
+		response.Error(w, http.StatusNotFound, fmt.Sprintf("Version %d not found", number))
+		return version, false
+	}
+	if err != nil {
+		log.Printf("Failed to load pack sizes version %d: %v", number, err)
+		response.Error(w, http.StatusInternalServerError, "Failed to load pack sizes versions")
+		return version, false
+	}
+
+	return version, true
+}
+
+func newPackSizesVersion(version storage.PackSizeVersion) PackSizesVersion {
+	return PackSizesVersion{
+		Version:         version.Version,
+		PackSizes:       version.PackSizes,
+		Costs:           version.Costs,
+		ShipmentCost:    version.ShipmentCost,
+		Weights:         version.Weights,
+		Volumes:         version.Volumes,
+		MaxParcelWeight: version.MaxParcelWeight,
+		MaxParcelVolume: version.MaxParcelVolume,
+		Rules:           versionRules(version.Rules),
+		CreatedAt:       version.CreatedAt,
+		Author:          version.Author,
+		Reason:          version.Reason,
+	}
+}
+
+// diffSettings lists the settings other than the pack sizes that differ between
+// two versions, in the order of PackSizesChange.Field and by pack size
+func diffSettings(from, to storage.PackSizeVersion) []PackSizesChange {
+	changes := []PackSizesChange{}
+	changes = appendSizeChanges(changes, "costs", from.Costs, to.Costs)
+	changes = appendValueChange(changes, "shipment_cost", from.ShipmentCost, to.ShipmentCost)
+	changes = appendSizeChanges(changes, "weights", from.Weights, to.Weights)
+	changes = appendSizeChanges(changes, "volumes", from.Volumes, to.Volumes)
+	changes = appendValueChange(changes, "max_parcel_weight", from.MaxParcelWeight, to.MaxParcelWeight)
+	changes = appendValueChange(changes, "max_parcel_volume", from.MaxParcelVolume, to.MaxParcelVolume)
+	return appendSizeChanges(changes, "rules", versionRules(from.Rules), versionRules(to.Rules))
+}
+
+// appendValueChange appends the change of a setting when its values differ;
+// zero values are unset
+func appendValueChange(changes []PackSizesChange, field string, from, to int) []PackSizesChange {
+	if from == to {
+		return changes
+	}
+
+	change := PackSizesChange{Field: field}
+	if from != 0 {
+		change.From = from
+	}
+	if to != 0 {
+		change.To = to
+	}
+	return append(changes, change)
+}
+
+// appendSizeChanges appends the change of every pack size whose value of a
+// per-size setting differs, in ascending size order
+func appendSizeChanges[V comparable](changes []PackSizesChange, field string, from, to map[int]V) []PackSizesChange {
+	sizes := slices.Sorted(maps.Keys(from))
+	sizes = slices.Compact(slices.Sorted(slices.Values(append(sizes, slices.Collect(maps.Keys(to))...))))
+
+	for _, size := range sizes {
+		fromValue, inFrom := from[size]
+		toValue, inTo := to[size]
+		if inFrom == inTo && fromValue == toValue {
+			continue
+		}
+
+		change := PackSizesChange{Field: field, Size: size}
+		if inFrom {
+			change.From = fromValue
+		}
+		if inTo {
+			change.To = toValue
+		}
+		changes = append(changes, change)
+	}
+
+	return changes
+}
@@ -0,0 +1,298 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/luisfernandomoraes/order-packing-api/internal/domain"
+)
+
+// newVersionedPackSizesHandler returns a handler whose history holds the initial
+// pack sizes as version 1 and each of the updates as the following versions
+func newVersionedPackSizesHandler(t *testing.T, initial []int, updates ...string) (*PackSizesHandler, *domain.PackCalculator) {
+	t.Helper()
+
+	calculator := domain.NewPackCalculator(initial)
+	handler := NewPackSizesHandler(calculator)
+
+	for _, update := range updates {
+		req := httptest.NewRequest(http.MethodPost, "/api/pack-sizes", bytes.NewBufferString(update))
+		w := httptest.NewRecorder()
+		handler.Handle(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+	}
+
+	return handler, calculator
+}
+
+func TestPackSizesHandler_HandleVersions(t *testing.T) {
+	t.Run("should list every version oldest first", func(t *testing.T) {
+		handler, _ := newVersionedPackSizesHandler(t, []int{250, 500},
+			`{"pack_sizes": [100, 250], "author": "jane.doe", "reason": "Small box"}`,
+		)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/pack-sizes/versions", nil)
+		w := httptest.NewRecorder()
+
+		handler.HandleVersions(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var responseData PackSizesVersionsResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&responseData))
+		assert.Equal(t, 2, responseData.Current)
+		require.Len(t, responseData.Versions, 2)
+
+		assert.Equal(t, 1, responseData.Versions[0].Version)
+		assert.Equal(t, []int{250, 500}, responseData.Versions[0].PackSizes)
+		assert.Equal(t, "system", responseData.Versions[0].Author)
+
+		assert.Equal(t, 2, responseData.Versions[1].Version)
+		assert.Equal(t, []int{100, 250}, responseData.Versions[1].PackSizes)
+		assert.Equal(t, "jane.doe", responseData.Versions[1].Author)
+		assert.Equal(t, "Small box", responseData.Versions[1].Reason)
+		assert.False(t, responseData.Versions[1].CreatedAt.IsZero())
+	})
+
+	t.Run("should list the costs, parcel limits and rules of every version", func(t *testing.T) {
+		handler, _ := newVersionedPackSizesHandler(t, []int{250, 500},
+			`{"pack_sizes": [250, 500], "costs": {"250": 120}, "shipment_cost": 500, "volumes": {"500": 7500}, "max_parcel_volume": 60000, "rules": {"500": {"min_order": 1000}}}`,
+		)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/pack-sizes/versions", nil)
+		w := httptest.NewRecorder()
+
+		handler.HandleVersions(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var responseData PackSizesVersionsResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&responseData))
+		require.Len(t, responseData.Versions, 2)
+
+		version := responseData.Versions[1]
+		assert.Equal(t, map[int]int{250: 120}, version.Costs)
+		assert.Equal(t, 500, version.ShipmentCost)
+		assert.Equal(t, map[int]int{500: 7500}, version.Volumes)
+		assert.Equal(t, 60000, version.MaxParcelVolume)
+		assert.Equal(t, map[int]PackSizeRule{500: {MinOrder: 1000}}, version.Rules)
+		assert.Empty(t, responseData.Versions[0].Costs)
+	})
+
+	t.Run("should report repository failures", func(t *testing.T) {
+		handler := NewPackSizesHandler(domain.NewPackCalculator([]int{250}), WithPackSizeRepository(failingRepository{}))
+
+		req := httptest.NewRequest(http.MethodGet, "/api/pack-sizes/versions", nil)
+		w := httptest.NewRecorder()
+
+		handler.HandleVersions(w, req)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+
+	t.Run("should reject non GET methods", func(t *testing.T) {
+		handler := NewPackSizesHandler(domain.NewPackCalculator([]int{250}))
+
+		req := httptest.NewRequest(http.MethodPost, "/api/pack-sizes/versions", nil)
+		w := httptest.NewRecorder()
+
+		handler.HandleVersions(w, req)
+
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	})
+}
+
+func TestPackSizesHandler_HandleDiff(t *testing.T) {
+	handler, _ := newVersionedPackSizesHandler(t, []int{250, 500, 1000, 2000, 5000},
+		`{"pack_sizes": [100, 250, 500, 1000, 2000]}`,
+	)
+
+	t.Run("should report added, removed and unchanged sizes", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/pack-sizes/diff?from=1&to=2", nil)
+		w := httptest.NewRecorder()
+
+		handler.HandleDiff(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var responseData PackSizesDiffResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&responseData))
+		assert.Equal(t, PackSizesDiffResponse{
+			From:      1,
+			To:        2,
+			Added:     []int{100},
+			Removed:   []int{5000},
+			Unchanged: []int{250, 500, 1000, 2000},
+			Changes:   []PackSizesChange{},
+		}, responseData)
+	})
+
+	t.Run("should answer empty lists for identical versions", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/pack-sizes/diff?from=2&to=2", nil)
+		w := httptest.NewRecorder()
+
+		handler.HandleDiff(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"from":2,"to":2,"added":[],"removed":[],"unchanged":[100,250,500,1000,2000],"changes":[]}`, w.Body.String())
+	})
+
+	t.Run("should report changed costs, parcel limits and rules", func(t *testing.T) {
+		handler, _ := newVersionedPackSizesHandler(t, []int{250, 500},
+			`{"pack_sizes": [250, 500], "costs": {"250": 120, "500": 200}, "weights": {"500": 5500}, "max_parcel_weight": 31500, "rules": {"250": {"max_packs": 2}}}`,
+			`{"pack_sizes": [250, 500], "costs": {"250": 100, "500": 200}, "shipment_cost": 500, "rules": {"250": {"max_packs": 3}}}`,
+		)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/pack-sizes/diff?from=2&to=3", nil)
+		w := httptest.NewRecorder()
+
+		handler.HandleDiff(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"from":2,"to":3,"added":[],"removed":[],"unchanged":[250,500],"changes":[
+			{"field":"costs","size":250,"from":120,"to":100},
+			{"field":"shipment_cost","from":null,"to":500},
+			{"field":"weights","size":500,"from":5500,"to":null},
+			{"field":"max_parcel_weight","from":31500,"to":null},
+			{"field":"rules","size":250,"from":{"max_packs":2},"to":{"max_packs":3}}
+		]}`, w.Body.String())
+	})
+
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:           "should reject missing versions",
+			query:          "from=1",
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Query parameters from and to must be version numbers",
+		},
+		{
+			name:           "should reject non numeric versions",
+			query:          "from=one&to=2",
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Query parameters from and to must be version numbers",
+		},
+		{
+			name:           "should report unknown versions",
+			query:          "from=1&to=7",
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "Version 7 not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/pack-sizes/diff?"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			handler.HandleDiff(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			var errorResponse map[string]string
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&errorResponse))
+			assert.Equal(t, tt.expectedError, errorResponse["error"])
+		})
+	}
+}
+
+func TestPackSizesHandler_HandleRollback(t *testing.T) {
+	t.Run("should restore a previous version as a new version", func(t *testing.T) {
+		handler, calculator := newVersionedPackSizesHandler(t, []int{250, 500},
+			`{"pack_sizes": [7]}`,
+		)
+
+		body := `{"version": 1, "author": "jane.doe"}`
+		req := httptest.NewRequest(http.MethodPost, "/api/pack-sizes/rollback", bytes.NewBufferString(body))
+		w := httptest.NewRecorder()
+
+		handler.HandleRollback(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var responseData PackSizesUpdateResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&responseData))
+		assert.Equal(t, PackSizesUpdateResponse{
+			Message:   "Pack sizes rolled back to version 1",
+			Version:   3,
+			PackSizes: []int{250, 500},
+		}, responseData)
+		assert.Equal(t, []int{250, 500}, calculator.GetPackSizes())
+
+		current, err := handler.repository.Current()
+		require.NoError(t, err)
+		assert.Equal(t, "jane.doe", current.Author)
+		assert.Equal(t, "Rollback to version 1", current.Reason)
+	})
+
+	t.Run("should restore the costs and parcel limits of the version", func(t *testing.T) {
+		handler, calculator := newVersionedPackSizesHandler(t, []int{250, 500},
+			`{"pack_sizes": [250, 500], "costs": {"250": 100}, "shipment_cost": 50, "weights": {"500": 5500}, "max_parcel_weight": 31500}`,
+			`{"pack_sizes": [7]}`,
+		)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/pack-sizes/rollback", bytes.NewBufferString(`{"version": 2}`))
+		w := httptest.NewRecorder()
+
+		handler.HandleRollback(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, domain.Costs{PerPack: map[int]int{250: 100}, PerShipment: 50}, calculator.GetCosts())
+		assert.Equal(t, domain.ParcelSpec{Weights: map[int]int{500: 5500}, MaxWeight: 31500}, calculator.GetParcelSpec())
+	})
+
+	tests := []struct {
+		name           string
+		method         string
+		body           string
+		expectedStatus int
+	}{
+		{
+			name:           "should reject invalid JSON",
+			method:         http.MethodPost,
+			body:           `{"version":`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "should report unknown versions",
+			method:         http.MethodPost,
+			body:           `{"version": 9}`,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "should report a missing version as not found",
+			method:         http.MethodPost,
+			body:           `{}`,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "should reject non POST methods",
+			method:         http.MethodGet,
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, calculator := newVersionedPackSizesHandler(t, []int{250, 500})
+
+			req := httptest.NewRequest(tt.method, "/api/pack-sizes/rollback", bytes.NewBufferString(tt.body))
+			w := httptest.NewRecorder()
+
+			handler.HandleRollback(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, []int{250, 500}, calculator.GetPackSizes())
+		})
+	}
+}
@@ -1,38 +1,130 @@
 package handlers
 
 import (
+	"fmt"
+	"log"
 	"net/http"
+	"slices"
+	"sync"
 
 	"github.com/luisfernandomoraes/order-packing-api/internal/domain"
 	"github.com/luisfernandomoraes/order-packing-api/internal/response"
+	"github.com/luisfernandomoraes/order-packing-api/internal/storage"
 )
 
-// PackSizesHandler handles the /api/pack-sizes endpoint
+// PackSizesHandler handles the /api/pack-sizes endpoints
 type PackSizesHandler struct {
 	calculator *domain.PackCalculator
+	repository storage.PackSizeRepository
+
+	// updateMu keeps the stored and the in-memory pack sizes in the same order
+	// when updates race
+	updateMu sync.Mutex
+}
+
+// PackSizesOption configures a PackSizesHandler
+type PackSizesOption func(*PackSizesHandler)
+
+// WithPackSizeRepository records every pack sizes update in the repository
+// before applying it to the calculator
+func WithPackSizeRepository(repository storage.PackSizeRepository) PackSizesOption {
+	return func(h *PackSizesHandler) {
+		h.repository = repository
+	}
 }
 
-// NewPackSizesHandler creates a new PackSizesHandler
-func NewPackSizesHandler(calculator *domain.PackCalculator) *PackSizesHandler {
-	return &PackSizesHandler{
+// NewPackSizesHandler creates a new PackSizesHandler. Without a repository the
+// history is kept in memory, starting with the calculator's current pack sizes
+// as version 1.
+func NewPackSizesHandler(calculator *domain.PackCalculator, opts ...PackSizesOption) *PackSizesHandler {
+	h := &PackSizesHandler{
 		calculator: calculator,
 	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	if h.repository == nil {
+		h.repository = storage.NewMemoryRepository()
+		costs := calculator.GetCosts()
+		spec := calculator.GetParcelSpec()
+		_, _ = h.repository.Append(storage.PackSizeChange{
+			PackSizes:       calculator.GetPackSizes(),
+			Costs:           costs.PerPack,
+			ShipmentCost:    costs.PerShipment,
+			Weights:         spec.Weights,
+			Volumes:         spec.Volumes,
+			MaxParcelWeight: spec.MaxWeight,
+			MaxParcelVolume: spec.MaxVolume,
+			Rules:           storedRules(calculator.GetRules()),
+			Author:          "system",
+			Reason:          "Initial pack sizes",
+		})
+	}
+
+	return h
 }
 
-// PackSizesRequest represents the request body for updating pack sizes
+// maxCost caps a pack or shipment cost, so the cost of the largest table still
+// fits an int
+const maxCost = 1_000_000_000
+
+// maxMeasure caps a pack weight or volume and a parcel limit
+const maxMeasure = 1_000_000_000
+
+// PackSizesRequest represents the request body for updating pack sizes.
+// Costs maps a pack size to the cost of one pack and ShipmentCost is added once
+// per shipment, both in the smallest currency unit; sizes without a cost cost nothing.
+// Weights (grams) and Volumes (cubic centimetres) describe one pack of a size, and
+// MaxParcelWeight and MaxParcelVolume limit a shipping parcel; zero is unlimited.
+// Rules restrict how each pack size may be used.
 type PackSizesRequest struct {
-	PackSizes []int `json:"pack_sizes" example:"100,250,500,1000"`
+	PackSizes       []int                `json:"pack_sizes" example:"100,250,500,1000"`
+	Costs           map[int]int          `json:"costs,omitempty" example:"100:60,250:120"`
+	ShipmentCost    int                  `json:"shipment_cost,omitempty" example:"500"`
+	Weights         map[int]int          `json:"weights,omitempty" example:"100:1200,250:3000"`
+	Volumes         map[int]int          `json:"volumes,omitempty" example:"100:1500,250:4000"`
+	MaxParcelWeight int                  `json:"max_parcel_weight,omitempty" example:"31500"`
+	MaxParcelVolume int                  `json:"max_parcel_volume,omitempty" example:"60000"`
+	Rules           map[int]PackSizeRule `json:"rules,omitempty"`
+	Author          string               `json:"author,omitempty" example:"jane.doe"`
+	Reason          string               `json:"reason,omitempty" example:"New 100 item box"`
+}
+
+// PackSizeRule restricts how a pack size may be used: MaxPacks caps the packs of
+// the size in one order, and MinOrder and MaxOrder bound the orders it may be
+// used for. Zero fields do not restrict.
+type PackSizeRule struct {
+	MaxPacks int `json:"max_packs,omitempty" example:"2"`
+	MinOrder int `json:"min_order,omitempty" example:"10001"`
+	MaxOrder int `json:"max_order,omitempty" example:"0"`
 }
 
 // PackSizesResponse represents the response from pack sizes endpoints
 type PackSizesResponse struct {
-	PackSizes []int `json:"pack_sizes" example:"250,500,1000,2000,5000"`
+	PackSizes       []int                `json:"pack_sizes" example:"250,500,1000,2000,5000"`
+	Costs           map[int]int          `json:"costs,omitempty" example:"250:120,500:200"`
+	ShipmentCost    int                  `json:"shipment_cost,omitempty" example:"500"`
+	Weights         map[int]int          `json:"weights,omitempty" example:"250:3000,500:5500"`
+	Volumes         map[int]int          `json:"volumes,omitempty" example:"250:4000,500:7500"`
+	MaxParcelWeight int                  `json:"max_parcel_weight,omitempty" example:"31500"`
+	MaxParcelVolume int                  `json:"max_parcel_volume,omitempty" example:"60000"`
+	Rules           map[int]PackSizeRule `json:"rules,omitempty"`
 }
 
 // PackSizesUpdateResponse represents the response from update pack sizes endpoint
 type PackSizesUpdateResponse struct {
-	Message   string `json:"message" example:"Pack sizes updated successfully"`
-	PackSizes []int  `json:"pack_sizes" example:"250,500,1000,2000,5000"`
+	Message         string               `json:"message" example:"Pack sizes updated successfully"`
+	Version         int                  `json:"version" example:"2"`
+	PackSizes       []int                `json:"pack_sizes" example:"250,500,1000,2000,5000"`
+	Costs           map[int]int          `json:"costs,omitempty" example:"250:120,500:200"`
+	ShipmentCost    int                  `json:"shipment_cost,omitempty" example:"500"`
+	Weights         map[int]int          `json:"weights,omitempty" example:"250:3000,500:5500"`
+	Volumes         map[int]int          `json:"volumes,omitempty" example:"250:4000,500:7500"`
+	MaxParcelWeight int                  `json:"max_parcel_weight,omitempty" example:"31500"`
+	MaxParcelVolume int                  `json:"max_parcel_volume,omitempty" example:"60000"`
+	Rules           map[int]PackSizeRule `json:"rules,omitempty"`
 }
 
 // Handle acts as a router for GET and POST methods
@@ -49,27 +141,41 @@ func (h *PackSizesHandler) Handle(w http.ResponseWriter, r *http.Request) {
 
 // handleGet godoc
 // @Summary Get current package sizes
-// @Description Returns the currently configured package sizes
+// @Description Returns the currently configured package sizes, their costs, weights, volumes and rules, and the parcel limits
 // @Tags pack-sizes
 // @Produce json
 // @Success 200 {object} PackSizesResponse
 // @Router /api/pack-sizes [get]
 func (h *PackSizesHandler) handleGet(w http.ResponseWriter, _ *http.Request) {
+	costs := h.calculator.GetCosts()
+	spec := h.calculator.GetParcelSpec()
+
 	responseData := PackSizesResponse{
-		PackSizes: h.calculator.GetPackSizes(),
+		PackSizes:       h.calculator.GetPackSizes(),
+		Costs:           costs.PerPack,
+		ShipmentCost:    costs.PerShipment,
+		Weights:         spec.Weights,
+		Volumes:         spec.Volumes,
+		MaxParcelWeight: spec.MaxWeight,
+		MaxParcelVolume: spec.MaxVolume,
+		Rules:           newPackSizeRules(h.calculator.GetRules()),
 	}
 	response.JSON(w, http.StatusOK, responseData)
 }
 
 // handlePost godoc
 // @Summary Update package sizes
-// @Description Updates the available package sizes used for calculations
+// @Description Updates the available package sizes used for calculations and records them as a new version.
+// @Description Costs and shipment_cost replace the current costs; sizes without a cost cost nothing.
+// @Description Weights, volumes, max_parcel_weight and max_parcel_volume replace the parcel limits; with a limit, calculations group the packs into parcels.
+// @Description Rules replace the pack size rules: max_packs caps the packs of a size per order, and min_order and max_order bound the orders it may be used for.
 // @Tags pack-sizes
 // @Accept json
 // @Produce json
 // @Param request body PackSizesRequest true "New pack sizes"
 // @Success 200 {object} PackSizesUpdateResponse
-// @Failure 400 {object} map[string]string "Bad Request - Empty array or non-positive values"
+// @Failure 400 {object} map[string]string "Bad Request - Empty array, non-positive values, invalid costs, invalid parcel limits or contradictory rules"
+// @Failure 500 {object} map[string]string "Pack sizes could not be saved"
 // @Router /api/pack-sizes [post]
 func (h *PackSizesHandler) handlePost(w http.ResponseWriter, r *http.Request) {
 	var req PackSizesRequest
@@ -79,24 +185,235 @@ func (h *PackSizesHandler) handlePost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if len(req.PackSizes) == 0 {
-		response.Error(w, http.StatusBadRequest, "Pack sizes cannot be empty")
+	if message := validatePackSizes(req.PackSizes); message != "" {
+		response.Error(w, http.StatusBadRequest, message)
 		return
 	}
 
-	for _, size := range req.PackSizes {
-		if size <= 0 {
-			response.Error(w, http.StatusBadRequest, "All pack sizes must be positive")
-			return
-		}
+	if message := validateCosts(req.PackSizes, req.Costs, req.ShipmentCost); message != "" {
+		response.Error(w, http.StatusBadRequest, message)
+		return
+	}
+
+	if message := validateParcelLimits(req); message != "" {
+		response.Error(w, http.StatusBadRequest, message)
+		return
 	}
 
-	h.calculator.UpdatePackSizes(req.PackSizes)
+	if message := validateRules(req.PackSizes, req.Rules); message != "" {
+		response.Error(w, http.StatusBadRequest, message)
+		return
+	}
+
+	h.applyChange(w, storage.PackSizeChange{
+		PackSizes:       slices.Sorted(slices.Values(req.PackSizes)),
+		Costs:           req.Costs,
+		ShipmentCost:    req.ShipmentCost,
+		Weights:         req.Weights,
+		Volumes:         req.Volumes,
+		MaxParcelWeight: req.MaxParcelWeight,
+		MaxParcelVolume: req.MaxParcelVolume,
+		Rules:           requestedRules(req.Rules),
+		Author:          req.Author,
+		Reason:          req.Reason,
+	}, "Pack sizes updated successfully")
+}
+
+// applyChange records the change as a new version and applies it to the
+// calculator, answering with the new version
+func (h *PackSizesHandler) applyChange(w http.ResponseWriter, change storage.PackSizeChange, message string) {
+	h.updateMu.Lock()
+	defer h.updateMu.Unlock()
+
+	version, err := h.repository.Append(change)
+	if err != nil {
+		log.Printf("Failed to save pack sizes: %v", err)
+		response.Error(w, http.StatusInternalServerError, "Failed to save pack sizes")
+		return
+	}
+
+	h.calculator.UpdateConfiguration(domain.Configuration{
+		PackSizes: version.PackSizes,
+		Costs:     domain.Costs{PerPack: version.Costs, PerShipment: version.ShipmentCost},
+		ParcelSpec: domain.ParcelSpec{
+			Weights:   version.Weights,
+			Volumes:   version.Volumes,
+			MaxWeight: version.MaxParcelWeight,
+			MaxVolume: version.MaxParcelVolume,
+		},
+		Rules: loadedRules(version.Rules),
+	})
+
+	costs := h.calculator.GetCosts()
+	spec := h.calculator.GetParcelSpec()
 
 	responseData := PackSizesUpdateResponse{
-		Message:   "Pack sizes updated successfully",
-		PackSizes: h.calculator.GetPackSizes(),
+		Message:         message,
+		Version:         version.Version,
+		PackSizes:       h.calculator.GetPackSizes(),
+		Costs:           costs.PerPack,
+		ShipmentCost:    costs.PerShipment,
+		Weights:         spec.Weights,
+		Volumes:         spec.Volumes,
+		MaxParcelWeight: spec.MaxWeight,
+		MaxParcelVolume: spec.MaxVolume,
+		Rules:           newPackSizeRules(h.calculator.GetRules()),
 	}
 
 	response.JSON(w, http.StatusOK, responseData)
 }
+
+// validatePackSizes returns the client-facing message describing why the pack
+// sizes cannot be used, or an empty string if they are valid
+func validatePackSizes(packSizes []int) string {
+	if len(packSizes) == 0 {
+		return "Pack sizes cannot be empty"
+	}
+
+	for _, size := range packSizes {
+		if size <= 0 {
+			return "All pack sizes must be positive"
+		}
+	}
+
+	return ""
+}
+
+// validateCosts returns the client-facing message describing why the costs
+// cannot be used with the pack sizes, or an empty string if they are valid
+func validateCosts(packSizes []int, costs map[int]int, shipmentCost int) string {
+	if shipmentCost < 0 || shipmentCost > maxCost {
+		return fmt.Sprintf("Costs must be between 0 and %d", maxCost)
+	}
+
+	for size, cost := range costs {
+		if !slices.Contains(packSizes, size) {
+			return fmt.Sprintf("Cost given for unknown pack size %d", size)
+		}
+		if cost < 0 || cost > maxCost {
+			return fmt.Sprintf("Costs must be between 0 and %d", maxCost)
+		}
+	}
+
+	return ""
+}
+
+// validateParcelLimits returns the client-facing message describing why the
+// weights, volumes and parcel limits cannot be used with the pack sizes, or an
+// empty string if they are valid. Every pack must fit in an empty parcel.
+func validateParcelLimits(req PackSizesRequest) string {
+	if req.MaxParcelWeight < 0 || req.MaxParcelWeight > maxMeasure ||
+		req.MaxParcelVolume < 0 || req.MaxParcelVolume > maxMeasure {
+		return fmt.Sprintf("Weights and volumes must be between 0 and %d", maxMeasure)
+	}
+
+	measures := []struct {
+		label  string
+		name   string
+		values map[int]int
+		limit  int
+	}{
+		{label: "Weight", name: "weight", values: req.Weights, limit: req.MaxParcelWeight},
+		{label: "Volume", name: "volume", values: req.Volumes, limit: req.MaxParcelVolume},
+	}
+
+	for _, measure := range measures {
+		for size, value := range measure.values {
+			if !slices.Contains(req.PackSizes, size) {
+				return fmt.Sprintf("%s given for unknown pack size %d", measure.label, size)
+			}
+			if value < 0 || value > maxMeasure {
+				return fmt.Sprintf("Weights and volumes must be between 0 and %d", maxMeasure)
+			}
+			if measure.limit > 0 && value > measure.limit {
+				return fmt.Sprintf("Pack size %d exceeds the parcel %s limit", size, measure.name)
+			}
+		}
+	}
+
+	return ""
+}
+
+// validateRules returns the client-facing message describing why the rules
+// cannot be used with the pack sizes, or an empty string if they are valid.
+// Rules contradict each other when some order may use no pack size at all.
+func validateRules(packSizes []int, rules map[int]PackSizeRule) string {
+	for size, rule := range rules {
+		if !slices.Contains(packSizes, size) {
+			return fmt.Sprintf("Rule given for unknown pack size %d", size)
+		}
+		if rule.MaxPacks < 0 || rule.MinOrder < 0 || rule.MaxOrder < 0 {
+			return "Rules must not be negative"
+		}
+		if rule.MaxOrder > 0 && rule.MinOrder > rule.MaxOrder {
+			return fmt.Sprintf("Pack size %d has a min_order above its max_order", size)
+		}
+	}
+
+	// Walk the order ranges of the sizes from the smallest minimum order and
+	// report the first order none of them covers.
+	ranges := make([]PackSizeRule, 0, len(packSizes))
+	for _, size := range packSizes {
+		rule := rules[size]
+		ranges = append(ranges, PackSizeRule{MinOrder: max(rule.MinOrder, 1), MaxOrder: rule.MaxOrder})
+	}
+	slices.SortFunc(ranges, func(a, b PackSizeRule) int { return a.MinOrder - b.MinOrder })
+
+	covered := 0
+	for _, r := range ranges {
+		if r.MinOrder > covered+1 {
+			return fmt.Sprintf("Rules leave orders %d to %d without a pack size", covered+1, r.MinOrder-1)
+		}
+		if r.MaxOrder == 0 {
+			return ""
+		}
+		covered = max(covered, r.MaxOrder)
+	}
+
+	return fmt.Sprintf("Rules leave orders above %d without a pack size", covered)
+}
+
+// requestedRules converts the requested rules for the repository
+func requestedRules(rules map[int]PackSizeRule) map[int]storage.PackSizeRule {
+	converted := make(map[int]storage.PackSizeRule, len(rules))
+	for size, rule := range rules {
+		converted[size] = storage.PackSizeRule(rule)
+	}
+	return converted
+}
+
+// newPackSizeRules converts the calculator rules for a response
+func newPackSizeRules(rules domain.PackRules) map[int]PackSizeRule {
+	converted := make(map[int]PackSizeRule, len(rules))
+	for size, rule := range rules {
+		converted[size] = PackSizeRule(rule)
+	}
+	return converted
+}
+
+// versionRules converts the rules of a recorded version for a response
+func versionRules(rules map[int]storage.PackSizeRule) map[int]PackSizeRule {
+	converted := make(map[int]PackSizeRule, len(rules))
+	for size, rule := range rules {
+		converted[size] = PackSizeRule(rule)
+	}
+	return converted
+}
+
+// storedRules converts the calculator rules for the repository
+func storedRules(rules domain.PackRules) map[int]storage.PackSizeRule {
+	converted := make(map[int]storage.PackSizeRule, len(rules))
+	for size, rule := range rules {
+		converted[size] = storage.PackSizeRule(rule)
+	}
+	return converted
+}
+
+// loadedRules converts the rules of a stored version for the calculator
+func loadedRules(rules map[int]storage.PackSizeRule) domain.PackRules {
+	converted := make(domain.PackRules, len(rules))
+	for size, rule := range rules {
+		converted[size] = domain.PackRule(rule)
+	}
+	return converted
+}
@@ -3,14 +3,17 @@ package handlers
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/luisfernandomoraes/order-packing-api/internal/domain"
+	"github.com/luisfernandomoraes/order-packing-api/internal/storage"
 )
 
 func TestNewPackSizesHandler(t *testing.T) {
@@ -253,6 +256,341 @@ func TestPackSizesHandler_HandlePost(t *testing.T) {
 	}
 }
 
+func TestPackSizesHandler_HandlePost_Repository(t *testing.T) {
+	t.Run("should record the sorted pack sizes as a new version", func(t *testing.T) {
+		repository := storage.NewFileRepository(filepath.Join(t.TempDir(), "pack-sizes.json"))
+		calculator := domain.NewPackCalculator([]int{250, 500, 1000})
+		handler := NewPackSizesHandler(calculator, WithPackSizeRepository(repository))
+
+		body := `{"pack_sizes": [53, 23, 31], "author": "jane.doe", "reason": "Edge case sizes"}`
+		req := httptest.NewRequest(http.MethodPost, "/pack-sizes", bytes.NewBufferString(body))
+		w := httptest.NewRecorder()
+
+		handler.Handle(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var responseData PackSizesUpdateResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&responseData))
+		assert.Equal(t, 1, responseData.Version)
+
+		current, err := repository.Current()
+		require.NoError(t, err)
+		assert.Equal(t, 1, current.Version)
+		assert.Equal(t, []int{23, 31, 53}, current.PackSizes)
+		assert.Equal(t, "jane.doe", current.Author)
+		assert.Equal(t, "Edge case sizes", current.Reason)
+		assert.Equal(t, current.PackSizes, calculator.GetPackSizes())
+	})
+
+	t.Run("should keep the current pack sizes when saving fails", func(t *testing.T) {
+		calculator := domain.NewPackCalculator([]int{250, 500, 1000})
+		handler := NewPackSizesHandler(calculator, WithPackSizeRepository(failingRepository{}))
+
+		req := httptest.NewRequest(http.MethodPost, "/pack-sizes", bytes.NewBufferString(`{"pack_sizes": [23, 31, 53]}`))
+		w := httptest.NewRecorder()
+
+		handler.Handle(w, req)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+		var errorResponse map[string]string
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&errorResponse))
+		assert.Equal(t, "Failed to save pack sizes", errorResponse["error"])
+		assert.Equal(t, []int{250, 500, 1000}, calculator.GetPackSizes())
+	})
+
+	t.Run("should not record rejected pack sizes", func(t *testing.T) {
+		repository := storage.NewFileRepository(filepath.Join(t.TempDir(), "pack-sizes.json"))
+		handler := NewPackSizesHandler(domain.NewPackCalculator([]int{250}), WithPackSizeRepository(repository))
+
+		req := httptest.NewRequest(http.MethodPost, "/pack-sizes", bytes.NewBufferString(`{"pack_sizes": [250, -1]}`))
+		w := httptest.NewRecorder()
+
+		handler.Handle(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		_, err := repository.Current()
+		assert.ErrorIs(t, err, storage.ErrNotFound)
+	})
+}
+
+func TestPackSizesHandler_HandlePost_Costs(t *testing.T) {
+	t.Run("should apply and record the costs", func(t *testing.T) {
+		repository := storage.NewMemoryRepository()
+		calculator := domain.NewPackCalculator([]int{250, 500})
+		handler := NewPackSizesHandler(calculator, WithPackSizeRepository(repository))
+
+		body := `{"pack_sizes": [250, 500], "costs": {"250": 120, "500": 200}, "shipment_cost": 500}`
+		req := httptest.NewRequest(http.MethodPost, "/pack-sizes", bytes.NewBufferString(body))
+		w := httptest.NewRecorder()
+
+		handler.Handle(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var responseData PackSizesUpdateResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&responseData))
+		assert.Equal(t, map[int]int{250: 120, 500: 200}, responseData.Costs)
+		assert.Equal(t, 500, responseData.ShipmentCost)
+
+		assert.Equal(t, domain.Costs{PerPack: map[int]int{250: 120, 500: 200}, PerShipment: 500}, calculator.GetCosts())
+
+		current, err := repository.Current()
+		require.NoError(t, err)
+		assert.Equal(t, map[int]int{250: 120, 500: 200}, current.Costs)
+		assert.Equal(t, 500, current.ShipmentCost)
+
+		req = httptest.NewRequest(http.MethodGet, "/pack-sizes", nil)
+		w = httptest.NewRecorder()
+
+		handler.Handle(w, req)
+
+		assert.JSONEq(t, `{"pack_sizes": [250, 500], "costs": {"250": 120, "500": 200}, "shipment_cost": 500}`, w.Body.String())
+	})
+
+	tests := []struct {
+		name          string
+		body          string
+		expectedError string
+	}{
+		{
+			name:          "should reject negative costs",
+			body:          `{"pack_sizes": [250], "costs": {"250": -1}}`,
+			expectedError: "Costs must be between 0 and 1000000000",
+		},
+		{
+			name:          "should reject negative shipment cost",
+			body:          `{"pack_sizes": [250], "shipment_cost": -1}`,
+			expectedError: "Costs must be between 0 and 1000000000",
+		},
+		{
+			name:          "should reject costs above the limit",
+			body:          `{"pack_sizes": [250], "costs": {"250": 1000000001}}`,
+			expectedError: "Costs must be between 0 and 1000000000",
+		},
+		{
+			name:          "should reject costs of unknown pack sizes",
+			body:          `{"pack_sizes": [250], "costs": {"500": 10}}`,
+			expectedError: "Cost given for unknown pack size 500",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calculator := domain.NewPackCalculator([]int{250})
+			handler := NewPackSizesHandler(calculator)
+
+			req := httptest.NewRequest(http.MethodPost, "/pack-sizes", bytes.NewBufferString(tt.body))
+			w := httptest.NewRecorder()
+
+			handler.Handle(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+
+			var errorResponse map[string]string
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&errorResponse))
+			assert.Equal(t, tt.expectedError, errorResponse["error"])
+			assert.Equal(t, domain.Costs{}, calculator.GetCosts())
+		})
+	}
+}
+
+func TestPackSizesHandler_HandlePost_ParcelLimits(t *testing.T) {
+	t.Run("should apply and record the weights, volumes and parcel limits", func(t *testing.T) {
+		repository := storage.NewMemoryRepository()
+		calculator := domain.NewPackCalculator([]int{250, 500})
+		handler := NewPackSizesHandler(calculator, WithPackSizeRepository(repository))
+
+		body := `{"pack_sizes": [250, 500], "weights": {"250": 3000, "500": 5500}, "volumes": {"500": 7500}, "max_parcel_weight": 31500, "max_parcel_volume": 60000}`
+		req := httptest.NewRequest(http.MethodPost, "/pack-sizes", bytes.NewBufferString(body))
+		w := httptest.NewRecorder()
+
+		handler.Handle(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var responseData PackSizesUpdateResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&responseData))
+		assert.Equal(t, map[int]int{250: 3000, 500: 5500}, responseData.Weights)
+		assert.Equal(t, 31500, responseData.MaxParcelWeight)
+
+		assert.Equal(t, domain.ParcelSpec{
+			Weights:   map[int]int{250: 3000, 500: 5500},
+			Volumes:   map[int]int{500: 7500},
+			MaxWeight: 31500,
+			MaxVolume: 60000,
+		}, calculator.GetParcelSpec())
+
+		current, err := repository.Current()
+		require.NoError(t, err)
+		assert.Equal(t, map[int]int{250: 3000, 500: 5500}, current.Weights)
+		assert.Equal(t, map[int]int{500: 7500}, current.Volumes)
+		assert.Equal(t, 31500, current.MaxParcelWeight)
+		assert.Equal(t, 60000, current.MaxParcelVolume)
+
+		req = httptest.NewRequest(http.MethodGet, "/pack-sizes", nil)
+		w = httptest.NewRecorder()
+
+		handler.Handle(w, req)
+
+		assert.JSONEq(t, `{"pack_sizes": [250, 500], "weights": {"250": 3000, "500": 5500}, "volumes": {"500": 7500}, "max_parcel_weight": 31500, "max_parcel_volume": 60000}`, w.Body.String())
+	})
+
+	tests := []struct {
+		name          string
+		body          string
+		expectedError string
+	}{
+		{
+			name:          "should reject negative weights",
+			body:          `{"pack_sizes": [250], "weights": {"250": -1}}`,
+			expectedError: "Weights and volumes must be between 0 and 1000000000",
+		},
+		{
+			name:          "should reject negative parcel limits",
+			body:          `{"pack_sizes": [250], "max_parcel_volume": -1}`,
+			expectedError: "Weights and volumes must be between 0 and 1000000000",
+		},
+		{
+			name:          "should reject volumes of unknown pack sizes",
+			body:          `{"pack_sizes": [250], "volumes": {"500": 10}}`,
+			expectedError: "Volume given for unknown pack size 500",
+		},
+		{
+			name:          "should reject packs heavier than a parcel",
+			body:          `{"pack_sizes": [250, 5000], "weights": {"5000": 52000}, "max_parcel_weight": 31500}`,
+			expectedError: "Pack size 5000 exceeds the parcel weight limit",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calculator := domain.NewPackCalculator([]int{250})
+			handler := NewPackSizesHandler(calculator)
+
+			req := httptest.NewRequest(http.MethodPost, "/pack-sizes", bytes.NewBufferString(tt.body))
+			w := httptest.NewRecorder()
+
+			handler.Handle(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+
+			var errorResponse map[string]string
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&errorResponse))
+			assert.Equal(t, tt.expectedError, errorResponse["error"])
+			assert.Equal(t, domain.ParcelSpec{}, calculator.GetParcelSpec())
+		})
+	}
+}
+
+func TestPackSizesHandler_HandlePost_Rules(t *testing.T) {
+	t.Run("should apply and record the rules", func(t *testing.T) {
+		repository := storage.NewMemoryRepository()
+		calculator := domain.NewPackCalculator([]int{250, 5000})
+		handler := NewPackSizesHandler(calculator, WithPackSizeRepository(repository))
+
+		body := `{"pack_sizes": [250, 5000], "rules": {"250": {"max_packs": 2}, "5000": {"min_order": 10001}}}`
+		req := httptest.NewRequest(http.MethodPost, "/pack-sizes", bytes.NewBufferString(body))
+		w := httptest.NewRecorder()
+
+		handler.Handle(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+
+		assert.Equal(t, domain.PackRules{250: {MaxPacks: 2}, 5000: {MinOrder: 10001}}, calculator.GetRules())
+
+		current, err := repository.Current()
+		require.NoError(t, err)
+		assert.Equal(t, map[int]storage.PackSizeRule{250: {MaxPacks: 2}, 5000: {MinOrder: 10001}}, current.Rules)
+
+		req = httptest.NewRequest(http.MethodGet, "/pack-sizes", nil)
+		w = httptest.NewRecorder()
+
+		handler.Handle(w, req)
+
+		assert.JSONEq(t, `{"pack_sizes": [250, 5000], "rules": {"250": {"max_packs": 2}, "5000": {"min_order": 10001}}}`, w.Body.String())
+	})
+
+	tests := []struct {
+		name          string
+		body          string
+		expectedError string
+	}{
+		{
+			name:          "should reject rules of unknown pack sizes",
+			body:          `{"pack_sizes": [250], "rules": {"500": {"max_packs": 1}}}`,
+			expectedError: "Rule given for unknown pack size 500",
+		},
+		{
+			name:          "should reject negative rules",
+			body:          `{"pack_sizes": [250], "rules": {"250": {"max_packs": -1}}}`,
+			expectedError: "Rules must not be negative",
+		},
+		{
+			name:          "should reject a minimum order above the maximum order",
+			body:          `{"pack_sizes": [250, 500], "rules": {"250": {"min_order": 1000, "max_order": 10}}}`,
+			expectedError: "Pack size 250 has a min_order above its max_order",
+		},
+		{
+			name:          "should reject small orders without a pack size",
+			body:          `{"pack_sizes": [250, 500], "rules": {"250": {"min_order": 100}, "500": {"min_order": 200}}}`,
+			expectedError: "Rules leave orders 1 to 99 without a pack size",
+		},
+		{
+			name:          "should reject gaps between order ranges",
+			body:          `{"pack_sizes": [250, 500], "rules": {"250": {"max_order": 1000}, "500": {"min_order": 5000}}}`,
+			expectedError: "Rules leave orders 1001 to 4999 without a pack size",
+		},
+		{
+			name:          "should reject large orders without a pack size",
+			body:          `{"pack_sizes": [250, 500], "rules": {"250": {"max_order": 1000}, "500": {"max_order": 2000}}}`,
+			expectedError: "Rules leave orders above 2000 without a pack size",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calculator := domain.NewPackCalculator([]int{250})
+			handler := NewPackSizesHandler(calculator)
+
+			req := httptest.NewRequest(http.MethodPost, "/pack-sizes", bytes.NewBufferString(tt.body))
+			w := httptest.NewRecorder()
+
+			handler.Handle(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+
+			var errorResponse map[string]string
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&errorResponse))
+			assert.Equal(t, tt.expectedError, errorResponse["error"])
+			assert.Empty(t, calculator.GetRules())
+		})
+	}
+}
+
+// failingRepository is a PackSizeRepository whose reads and writes always fail
+type failingRepository struct{}
+
+func (failingRepository) Current() (storage.PackSizeVersion, error) {
+	return storage.PackSizeVersion{}, errors.New("disk failure")
+}
+
+func (failingRepository) Version(int) (storage.PackSizeVersion, error) {
+	return storage.PackSizeVersion{}, errors.New("disk failure")
+}
+
+func (failingRepository) Versions() ([]storage.PackSizeVersion, error) {
+	return nil, errors.New("disk failure")
+}
+
+func (failingRepository) Append(storage.PackSizeChange) (storage.PackSizeVersion, error) {
+	return storage.PackSizeVersion{}, errors.New("disk full")
+}
+
+func (failingRepository) Close() error { return nil }
+
 func TestPackSizesHandler_HandlePost_InvalidJSON(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -333,8 +671,9 @@ func TestPackSizesHandler_ResponseFormat(t *testing.T) {
 		require.NoError(t, err)
 
 		assert.Contains(t, response, "message")
+		assert.Contains(t, response, "version")
 		assert.Contains(t, response, "pack_sizes")
-		assert.Len(t, response, 2)
+		assert.Len(t, response, 3)
 	})
 }
 
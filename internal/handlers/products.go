@@ -0,0 +1,251 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"slices"
+
+	"github.com/luisfernandomoraes/order-packing-api/internal/domain"
+	"github.com/luisfernandomoraes/order-packing-api/internal/response"
+)
+
+// skuPattern restricts SKUs to characters that are safe in URL paths
+var skuPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// ProductsHandler handles the /api/products endpoints
+type ProductsHandler struct {
+	catalog *domain.Catalog
+}
+
+// NewProductsHandler creates a new ProductsHandler
+func NewProductsHandler(catalog *domain.Catalog) *ProductsHandler {
+	return &ProductsHandler{
+		catalog: catalog,
+	}
+}
+
+// ProductRequest represents the request body for creating a product
+type ProductRequest struct {
+	SKU       string `json:"sku" example:"WIDGET-01"`
+	Name      string `json:"name,omitempty" example:"Widget"`
+	PackSizes []int  `json:"pack_sizes" example:"23,31,53"`
+}
+
+// ProductPackSizesRequest represents the request body for replacing the pack
+// sizes of a product. Costs, parcel limits and rules only apply to the global
+// pack sizes.
+type ProductPackSizesRequest struct {
+	PackSizes []int `json:"pack_sizes" example:"23,31,53"`
+}
+
+// ProductResponse represents a product in the catalog
+type ProductResponse struct {
+	SKU       string `json:"sku" example:"WIDGET-01"`
+	Name      string `json:"name,omitempty" example:"Widget"`
+	PackSizes []int  `json:"pack_sizes" example:"23,31,53"`
+}
+
+// ProductsResponse represents the response from the list products endpoint
+type ProductsResponse struct {
+	Products []ProductResponse `json:"products"`
+}
+
+// Handle acts as a router for GET and POST methods on /api/products
+func (h *ProductsHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.handleList(w, r)
+	case http.MethodPost:
+		h.handleCreate(w, r)
+	default:
+		response.Error(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// HandleProduct acts as a router for GET and DELETE methods on /api/products/{sku}
+func (h *ProductsHandler) HandleProduct(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.handleGet(w, r)
+	case http.MethodDelete:
+		h.handleDelete(w, r)
+	default:
+		response.Error(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// HandlePackSizes acts as a router for GET and POST methods on /api/products/{sku}/pack-sizes
+func (h *ProductsHandler) HandlePackSizes(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.handleGetPackSizes(w, r)
+	case http.MethodPost:
+		h.handleUpdatePackSizes(w, r)
+	default:
+		response.Error(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// handleList godoc
+// @Summary List products
+// @Description Returns every product in the catalog with its pack sizes, sorted by SKU
+// @Tags products
+// @Produce json
+// @Success 200 {object} ProductsResponse
+// @Router /api/products [get]
+func (h *ProductsHandler) handleList(w http.ResponseWriter, _ *http.Request) {
+	products := h.catalog.Products()
+
+	responseData := ProductsResponse{
+		Products: make([]ProductResponse, len(products)),
+	}
+	for i, product := range products {
+		responseData.Products[i] = newProductResponse(product)
+	}
+
+	response.JSON(w, http.StatusOK, responseData)
+}
+
+// handleCreate godoc
+// @Summary Create a product
+// @Description Adds a product to the catalog with its own pack sizes
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param request body ProductRequest true "New product"
+// @Success 201 {object} ProductResponse
+// @Failure 400 {object} map[string]string "Bad Request - Invalid SKU or pack sizes"
+// @Failure 409 {object} map[string]string "Product already exists"
+// @Router /api/products [post]
+func (h *ProductsHandler) handleCreate(w http.ResponseWriter, r *http.Request) {
+	var req ProductRequest
+
+	if err := response.DecodeJSON(r, &req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if !skuPattern.MatchString(req.SKU) {
+		response.Error(w, http.StatusBadRequest, "SKU must be 1 to 64 letters, digits, dots, dashes or underscores")
+		return
+	}
+
+	if message := validatePackSizes(req.PackSizes); message != "" {
+		response.Error(w, http.StatusBadRequest, message)
+		return
+	}
+
+	product, err := h.catalog.Add(req.SKU, req.Name, req.PackSizes)
+	if errors.Is(err, domain.ErrProductExists) {
+		response.Error(w, http.StatusConflict, "Product already exists")
+		return
+	}
+
+	response.JSON(w, http.StatusCreated, newProductResponse(product))
+}
+
+// handleGet godoc
+// @Summary Get a product
+// @Description Returns a product and its pack sizes
+// @Tags products
+// @Produce json
+// @Param sku path string true "Product SKU"
+// @Success 200 {object} ProductResponse
+// @Failure 404 {object} map[string]string "Product not found"
+// @Router /api/products/{sku} [get]
+func (h *ProductsHandler) handleGet(w http.ResponseWriter, r *http.Request) {
+	product, err := h.catalog.Product(r.PathValue("sku"))
+	if err != nil {
+		response.Error(w, http.StatusNotFound, "Product not found")
+		return
+	}
+
+	response.JSON(w, http.StatusOK, newProductResponse(product))
+}
+
+// handleDelete godoc
+// @Summary Delete a product
+// @Description Removes a product from the catalog
+// @Tags products
+// @Param sku path string true "Product SKU"
+// @Success 204
+// @Failure 404 {object} map[string]string "Product not found"
+// @Router /api/products/{sku} [delete]
+func (h *ProductsHandler) handleDelete(w http.ResponseWriter, r *http.Request) {
+	if err := h.catalog.Remove(r.PathValue("sku")); err != nil {
+		response.Error(w, http.StatusNotFound, "Product not found")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleGetPackSizes godoc
+// @Summary Get product package sizes
+// @Description Returns the package sizes of a product
+// @Tags products
+// @Produce json
+// @Param sku path string true "Product SKU"
+// @Success 200 {object} PackSizesResponse
+// @Failure 404 {object} map[string]string "Product not found"
+// @Router /api/products/{sku}/pack-sizes [get]
+func (h *ProductsHandler) handleGetPackSizes(w http.ResponseWriter, r *http.Request) {
+	calculator, err := h.catalog.Calculator(r.PathValue("sku"))
+	if err != nil {
+		response.Error(w, http.StatusNotFound, "Product not found")
+		return
+	}
+
+	responseData := PackSizesResponse{
+		PackSizes: calculator.GetPackSizes(),
+	}
+	response.JSON(w, http.StatusOK, responseData)
+}
+
+// handleUpdatePackSizes godoc
+// @Summary Update product package sizes
+// @Description Replaces the package sizes of a product. Other products and the global pack sizes are not affected. Products have no costs, parcel limits or rules.
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param sku path string true "Product SKU"
+// @Param request body ProductPackSizesRequest true "New pack sizes"
+// @Success 200 {object} PackSizesResponse
+// @Failure 400 {object} map[string]string "Bad Request - Empty array or non-positive values"
+// @Failure 404 {object} map[string]string "Product not found"
+// @Router /api/products/{sku}/pack-sizes [post]
+func (h *ProductsHandler) handleUpdatePackSizes(w http.ResponseWriter, r *http.Request) {
+	calculator, err := h.catalog.Calculator(r.PathValue("sku"))
+	if err != nil {
+		response.Error(w, http.StatusNotFound, "Product not found")
+		return
+	}
+
+	var req ProductPackSizesRequest
+
+	if err := response.DecodeJSON(r, &req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if message := validatePackSizes(req.PackSizes); message != "" {
+		response.Error(w, http.StatusBadRequest, message)
+		return
+	}
+
+	calculator.UpdatePackSizes(slices.Sorted(slices.Values(req.PackSizes)))
+
+	responseData := PackSizesResponse{
+		PackSizes: calculator.GetPackSizes(),
+	}
+	response.JSON(w, http.StatusOK, responseData)
+}
+
+func newProductResponse(product domain.Product) ProductResponse {
+	return ProductResponse{
+		SKU:       product.SKU,
+		Name:      product.Name,
+		PackSizes: product.PackSizes,
+	}
+}
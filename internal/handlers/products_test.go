@@ -0,0 +1,282 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/luisfernandomoraes/order-packing-api/internal/domain"
+)
+
+// serveProducts routes a request through the product patterns so that path
+// values are populated as they are by the server
+func serveProducts(handler *ProductsHandler, req *http.Request) *httptest.ResponseRecorder {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/products", handler.Handle)
+	mux.HandleFunc("/api/products/{sku}", handler.HandleProduct)
+	mux.HandleFunc("/api/products/{sku}/pack-sizes", handler.HandlePackSizes)
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	return w
+}
+
+func newTestCatalog(t *testing.T) *domain.Catalog {
+	t.Helper()
+
+	catalog := domain.NewCatalog()
+	_, err := catalog.Add("WIDGET", "Widget", []int{23, 31, 53})
+	require.NoError(t, err)
+
+	return catalog
+}
+
+func TestProductsHandler_Handle(t *testing.T) {
+	t.Run("should list products", func(t *testing.T) {
+		handler := NewProductsHandler(newTestCatalog(t))
+
+		w := serveProducts(handler, httptest.NewRequest(http.MethodGet, "/api/products", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"products":[{"sku":"WIDGET","name":"Widget","pack_sizes":[23,31,53]}]}`, w.Body.String())
+	})
+
+	t.Run("should list an empty catalog as an empty array", func(t *testing.T) {
+		handler := NewProductsHandler(domain.NewCatalog())
+
+		w := serveProducts(handler, httptest.NewRequest(http.MethodGet, "/api/products", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"products":[]}`, w.Body.String())
+	})
+
+	t.Run("should create products", func(t *testing.T) {
+		catalog := domain.NewCatalog()
+		handler := NewProductsHandler(catalog)
+
+		body := `{"sku": "GADGET", "name": "Gadget", "pack_sizes": [500, 250]}`
+		w := serveProducts(handler, httptest.NewRequest(http.MethodPost, "/api/products", bytes.NewBufferString(body)))
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+
+		var responseData ProductResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&responseData))
+		assert.Equal(t, ProductResponse{SKU: "GADGET", Name: "Gadget", PackSizes: []int{250, 500}}, responseData)
+
+		product, err := catalog.Product("GADGET")
+		require.NoError(t, err)
+		assert.Equal(t, []int{250, 500}, product.PackSizes)
+	})
+
+	tests := []struct {
+		name           string
+		method         string
+		body           string
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:           "should reject invalid JSON",
+			method:         http.MethodPost,
+			body:           `{"sku":`,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Invalid request body",
+		},
+		{
+			name:           "should reject a missing SKU",
+			method:         http.MethodPost,
+			body:           `{"pack_sizes": [250]}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "SKU must be 1 to 64 letters, digits, dots, dashes or underscores",
+		},
+		{
+			name:           "should reject SKUs that are unsafe in paths",
+			method:         http.MethodPost,
+			body:           `{"sku": "A/B", "pack_sizes": [250]}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "SKU must be 1 to 64 letters, digits, dots, dashes or underscores",
+		},
+		{
+			name:           "should reject empty pack sizes",
+			method:         http.MethodPost,
+			body:           `{"sku": "GADGET", "pack_sizes": []}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Pack sizes cannot be empty",
+		},
+		{
+			name:           "should reject non-positive pack sizes",
+			method:         http.MethodPost,
+			body:           `{"sku": "GADGET", "pack_sizes": [250, 0]}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "All pack sizes must be positive",
+		},
+		{
+			name:           "should reject duplicate SKUs",
+			method:         http.MethodPost,
+			body:           `{"sku": "WIDGET", "pack_sizes": [250]}`,
+			expectedStatus: http.StatusConflict,
+			expectedError:  "Product already exists",
+		},
+		{
+			name:           "should reject other methods",
+			method:         http.MethodPut,
+			expectedStatus: http.StatusMethodNotAllowed,
+			expectedError:  "Method not allowed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewProductsHandler(newTestCatalog(t))
+
+			w := serveProducts(handler, httptest.NewRequest(tt.method, "/api/products", bytes.NewBufferString(tt.body)))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			var errorResponse map[string]string
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&errorResponse))
+			assert.Equal(t, tt.expectedError, errorResponse["error"])
+		})
+	}
+}
+
+func TestProductsHandler_HandleProduct(t *testing.T) {
+	t.Run("should get a product", func(t *testing.T) {
+		handler := NewProductsHandler(newTestCatalog(t))
+
+		w := serveProducts(handler, httptest.NewRequest(http.MethodGet, "/api/products/WIDGET", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"sku":"WIDGET","name":"Widget","pack_sizes":[23,31,53]}`, w.Body.String())
+	})
+
+	t.Run("should delete a product", func(t *testing.T) {
+		catalog := newTestCatalog(t)
+		handler := NewProductsHandler(catalog)
+
+		w := serveProducts(handler, httptest.NewRequest(http.MethodDelete, "/api/products/WIDGET", nil))
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Empty(t, catalog.Products())
+	})
+
+	tests := []struct {
+		name           string
+		method         string
+		expectedStatus int
+	}{
+		{name: "should report unknown products on GET", method: http.MethodGet, expectedStatus: http.StatusNotFound},
+		{name: "should report unknown products on DELETE", method: http.MethodDelete, expectedStatus: http.StatusNotFound},
+		{name: "should reject other methods", method: http.MethodPost, expectedStatus: http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewProductsHandler(newTestCatalog(t))
+
+			w := serveProducts(handler, httptest.NewRequest(tt.method, "/api/products/MISSING", nil))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestProductsHandler_HandlePackSizes(t *testing.T) {
+	t.Run("should get the product pack sizes", func(t *testing.T) {
+		handler := NewProductsHandler(newTestCatalog(t))
+
+		w := serveProducts(handler, httptest.NewRequest(http.MethodGet, "/api/products/WIDGET/pack-sizes", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"pack_sizes":[23,31,53]}`, w.Body.String())
+	})
+
+	t.Run("should update only the product pack sizes", func(t *testing.T) {
+		catalog := newTestCatalog(t)
+		_, err := catalog.Add("GADGET", "Gadget", []int{250})
+		require.NoError(t, err)
+		handler := NewProductsHandler(catalog)
+
+		body := `{"pack_sizes": [100, 50]}`
+		w := serveProducts(handler, httptest.NewRequest(http.MethodPost, "/api/products/WIDGET/pack-sizes", bytes.NewBufferString(body)))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"pack_sizes":[50,100]}`, w.Body.String())
+
+		gadget, err := catalog.Product("GADGET")
+		require.NoError(t, err)
+		assert.Equal(t, []int{250}, gadget.PackSizes)
+	})
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:           "should report unknown products",
+			method:         http.MethodGet,
+			path:           "/api/products/MISSING/pack-sizes",
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "Product not found",
+		},
+		{
+			name:           "should report unknown products on update",
+			method:         http.MethodPost,
+			path:           "/api/products/MISSING/pack-sizes",
+			body:           `{"pack_sizes": [100]}`,
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "Product not found",
+		},
+		{
+			name:           "should reject invalid JSON",
+			method:         http.MethodPost,
+			path:           "/api/products/WIDGET/pack-sizes",
+			body:           `{"pack_sizes":`,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Invalid request body",
+		},
+		{
+			name:           "should reject empty pack sizes",
+			method:         http.MethodPost,
+			path:           "/api/products/WIDGET/pack-sizes",
+			body:           `{"pack_sizes": []}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Pack sizes cannot be empty",
+		},
+		{
+			name:           "should reject other methods",
+			method:         http.MethodDelete,
+			path:           "/api/products/WIDGET/pack-sizes",
+			expectedStatus: http.StatusMethodNotAllowed,
+			expectedError:  "Method not allowed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			catalog := newTestCatalog(t)
+			handler := NewProductsHandler(catalog)
+
+			w := serveProducts(handler, httptest.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body)))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			var errorResponse map[string]string
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&errorResponse))
+			assert.Equal(t, tt.expectedError, errorResponse["error"])
+
+			product, err := catalog.Product("WIDGET")
+			require.NoError(t, err)
+			assert.Equal(t, []int{23, 31, 53}, product.PackSizes)
+		})
+	}
+}
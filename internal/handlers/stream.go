@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/luisfernandomoraes/order-packing-api/internal/response"
+)
+
+// maxStreamLineSize is the longest request line accepted by the stream endpoint
+const maxStreamLineSize = 64 * 1024
+
+// StreamCalculateError represents a failed line in the streaming response
+type StreamCalculateError struct {
+	Line  int    `json:"line" example:"3"`
+	Error string `json:"error" example:"Order must be positive"`
+	Code  string `json:"code,omitempty" example:"no_exact_fit"`
+}
+
+// HandleStream godoc
+// @Summary Calculate optimal package combinations from an NDJSON stream
+// @Description Reads one CalculateRequest per line (newline-delimited JSON) and streams back one line per non-blank input line, in the same order, flushing each as soon as it is calculated. Successful lines are CalculateResponse objects; failed lines carry the input line number and the same error message the single calculate endpoint would return.
+// @Tags calculate
+// @Accept application/x-ndjson
+// @Produce application/x-ndjson
+// @Param request body CalculateRequest true "One order per line"
+// @Success 200 {object} CalculateResponse "One line per order; failed lines are StreamCalculateError"
+// @Failure 405 {object} map[string]string "Method Not Allowed"
+// @Router /api/calculate/stream [post]
+func (h *CalculateHandler) HandleStream(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		response.Error(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	// Streams can run far longer than the server's read and write timeouts,
+	// so lift them for this request only. Without full duplex, the HTTP/1
+	// server discards the unread request body on the first flushed line.
+	controller := http.NewResponseController(w)
+	_ = controller.SetReadDeadline(time.Time{})
+	_ = controller.SetWriteDeadline(time.Time{})
+	_ = controller.EnableFullDuplex()
+
+	stream := response.NewNDJSONStream(w, http.StatusOK)
+
+	scanner := bufio.NewScanner(r.Body)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxStreamLineSize)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+
+		payload := bytes.TrimSpace(scanner.Bytes())
+		if len(payload) == 0 {
+			continue
+		}
+
+		if err := stream.Write(h.calculateLine(r, line, payload)); err != nil {
+			return
+		}
+
+		if r.Context().Err() != nil {
+			return
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		_ = stream.Write(StreamCalculateError{Line: line + 1, Error: "Invalid request body"})
+	}
+}
+
+// calculateLine decodes and calculates a single stream line, returning either the
+// CalculateResponse or a StreamCalculateError to write back
+func (h *CalculateHandler) calculateLine(r *http.Request, line int, payload []byte) interface{} {
+	var req CalculateRequest
+
+	if err := json.Unmarshal(payload, &req); err != nil {
+		return StreamCalculateError{Line: line, Error: "Invalid request body"}
+	}
+
+	responseData, err := h.calculate(r.Context(), req)
+	if err != nil {
+		_, message := calculationError(err)
+		return StreamCalculateError{Line: line, Error: message, Code: calculationErrorCode(err)}
+	}
+
+	return responseData
+}
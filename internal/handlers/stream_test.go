@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/luisfernandomoraes/order-packing-api/internal/domain"
+)
+
+func TestCalculateHandler_HandleStream_MethodRouting(t *testing.T) {
+	for _, method := range []string{http.MethodGet, http.MethodPut, http.MethodDelete, http.MethodPatch} {
+		t.Run("should reject "+method+" method", func(t *testing.T) {
+			handler := NewCalculateHandler(domain.NewPackCalculator([]int{250, 500, 1000}))
+			req := httptest.NewRequest(method, "/calculate/stream", nil)
+			w := httptest.NewRecorder()
+
+			handler.HandleStream(w, req)
+
+			assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+		})
+	}
+}
+
+func TestCalculateHandler_HandleStream(t *testing.T) {
+	t.Run("should stream one line per order with per-line errors", func(t *testing.T) {
+		handler := NewCalculateHandler(domain.NewPackCalculator([]int{250, 500, 1000, 2000, 5000}))
+
+		body := strings.Join([]string{
+			`{"order": 501}`,
+			``,
+			`{"order": -1}`,
+			`{invalid json}`,
+			`{"order": 12001}`,
+		}, "\n")
+		req := httptest.NewRequest(http.MethodPost, "/calculate/stream", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/x-ndjson")
+		w := httptest.NewRecorder()
+
+		handler.HandleStream(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+		assert.True(t, w.Flushed)
+
+		lines := readNDJSONLines(t, w.Body.String())
+		require.Len(t, lines, 4)
+
+		assert.Equal(t, float64(501), lines[0]["order"])
+		assert.Equal(t, float64(750), lines[0]["total_items"])
+		assert.Equal(t, float64(2), lines[0]["total_packs"])
+
+		assert.Equal(t, float64(3), lines[1]["line"])
+		assert.Equal(t, "Order must be positive", lines[1]["error"])
+
+		assert.Equal(t, float64(4), lines[2]["line"])
+		assert.Equal(t, "Invalid request body", lines[2]["error"])
+
+		assert.Equal(t, float64(12001), lines[3]["order"])
+		assert.Equal(t, float64(12250), lines[3]["total_items"])
+	})
+
+	t.Run("should report calculation errors per line", func(t *testing.T) {
+		handler := NewCalculateHandler(domain.NewPackCalculator([]int{}))
+
+		req := httptest.NewRequest(http.MethodPost, "/calculate/stream", strings.NewReader(`{"order": 0}`+"\n"+`{"order": 10}`))
+		w := httptest.NewRecorder()
+
+		handler.HandleStream(w, req)
+
+		lines := readNDJSONLines(t, w.Body.String())
+		require.Len(t, lines, 2)
+		assert.Equal(t, float64(0), lines[0]["total_items"])
+		assert.Equal(t, "No pack sizes configured", lines[1]["error"])
+	})
+
+	t.Run("should report lines longer than the limit", func(t *testing.T) {
+		handler := NewCalculateHandler(domain.NewPackCalculator([]int{250, 500}))
+
+		body := `{"order": 1}` + "\n" + strings.Repeat(" ", maxStreamLineSize+1)
+		req := httptest.NewRequest(http.MethodPost, "/calculate/stream", strings.NewReader(body))
+		w := httptest.NewRecorder()
+
+		handler.HandleStream(w, req)
+
+		lines := readNDJSONLines(t, w.Body.String())
+		require.Len(t, lines, 2)
+		assert.Equal(t, float64(250), lines[0]["total_items"])
+		assert.Equal(t, float64(2), lines[1]["line"])
+		assert.Equal(t, "Invalid request body", lines[1]["error"])
+	})
+}
+
+func readNDJSONLines(t *testing.T, body string) []map[string]interface{} {
+	t.Helper()
+
+	var lines []map[string]interface{}
+	scanner := bufio.NewScanner(strings.NewReader(body))
+	for scanner.Scan() {
+		var line map[string]interface{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+		lines = append(lines, line)
+	}
+	require.NoError(t, scanner.Err())
+
+	return lines
+}
@@ -20,7 +20,7 @@ func TestCORS(t *testing.T) {
 		handler(rr, req)
 
 		assert.Equal(t, "*", rr.Header().Get("Access-Control-Allow-Origin"))
-		assert.Equal(t, "GET, POST, OPTIONS", rr.Header().Get("Access-Control-Allow-Methods"))
+		assert.Equal(t, "GET, POST, DELETE, OPTIONS", rr.Header().Get("Access-Control-Allow-Methods"))
 		assert.Equal(t, "Content-Type", rr.Header().Get("Access-Control-Allow-Headers"))
 	})
 
@@ -104,7 +104,7 @@ func TestCORS(t *testing.T) {
 
 		// CORS headers should be present
 		assert.Equal(t, "*", rr.Header().Get("Access-Control-Allow-Origin"))
-		assert.Equal(t, "GET, POST, OPTIONS", rr.Header().Get("Access-Control-Allow-Methods"))
+		assert.Equal(t, "GET, POST, DELETE, OPTIONS", rr.Header().Get("Access-Control-Allow-Methods"))
 		// Custom header should also be present
 		assert.Equal(t, "custom-value", rr.Header().Get("X-Custom-Header"))
 	})
@@ -186,6 +186,20 @@ func TestLogging(t *testing.T) {
 		assert.Equal(t, "value", rr.Header().Get("X-Custom"))
 	})
 
+	t.Run("responseWriter lets handlers flush through the wrapper", func(t *testing.T) {
+		handler := Logging(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("partial"))
+			assert.NoError(t, http.NewResponseController(w).Flush())
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		rr := httptest.NewRecorder()
+
+		handler(rr, req)
+
+		assert.True(t, rr.Flushed)
+	})
+
 	t.Run("logs all HTTP methods", func(t *testing.T) {
 		methods := []string{
 			http.MethodGet,
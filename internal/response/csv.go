@@ -0,0 +1,46 @@
+package response
+
+import (
+	"encoding/csv"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// CSVContentType is the media type used for CSV requests and responses
+const CSVContentType = "text/csv"
+
+// CSV writes a CSV response with the given status code, header row and records
+func CSV(w http.ResponseWriter, statusCode int, header []string, records [][]string) {
+	w.Header().Set("Content-Type", CSVContentType+"; charset=utf-8")
+	w.WriteHeader(statusCode)
+
+	writer := csv.NewWriter(w)
+	_ = writer.Write(header)
+	_ = writer.WriteAll(records)
+}
+
+// DecodeCSV reads every record from the CSV body of the request.
+// All records must have the same number of fields as the first one.
+func DecodeCSV(r *http.Request) ([][]string, error) {
+	reader := csv.NewReader(r.Body)
+	reader.TrimLeadingSpace = true
+	return reader.ReadAll()
+}
+
+// IsCSV reports whether the request body is CSV according to its Content-Type header
+func IsCSV(r *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return err == nil && mediaType == CSVContentType
+}
+
+// AcceptsCSV reports whether the client asked for a CSV response in its Accept header
+func AcceptsCSV(r *http.Request) bool {
+	for _, accepted := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(accepted))
+		if err == nil && mediaType == CSVContentType {
+			return true
+		}
+	}
+	return false
+}
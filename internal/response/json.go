@@ -23,6 +23,20 @@ func Error(w http.ResponseWriter, statusCode int, message string) {
 	})
 }
 
+// ErrorWithCode writes a JSON error response that also carries a machine-readable
+// code. An empty code is left out, like Error.
+func ErrorWithCode(w http.ResponseWriter, statusCode int, code, message string) {
+	if code == "" {
+		Error(w, statusCode, message)
+		return
+	}
+
+	JSON(w, statusCode, map[string]string{
+		"error": message,
+		"code":  code,
+	})
+}
+
 // DecodeJSON decodes the JSON body from the request into the provided value
 func DecodeJSON(r *http.Request, v interface{}) error {
 	return json.NewDecoder(r.Body).Decode(v)
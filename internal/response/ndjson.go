@@ -0,0 +1,39 @@
+package response
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// NDJSONStream writes newline-delimited JSON values to a response, flushing each
+// value to the client as soon as it is written
+type NDJSONStream struct {
+	encoder    *json.Encoder
+	controller *http.ResponseController
+}
+
+// NewNDJSONStream writes the NDJSON content type and the given status code and
+// returns a stream for the response body
+func NewNDJSONStream(w http.ResponseWriter, statusCode int) *NDJSONStream {
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(statusCode)
+
+	return &NDJSONStream{
+		encoder:    json.NewEncoder(w),
+		controller: http.NewResponseController(w),
+	}
+}
+
+// Write encodes the value as a single line and flushes it to the client
+func (s *NDJSONStream) Write(v interface{}) error {
+	if err := s.encoder.Encode(v); err != nil {
+		return err
+	}
+
+	if err := s.controller.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 
 	httpSwagger "github.com/swaggo/http-swagger"
 
+	"github.com/luisfernandomoraes/order-packing-api/internal/domain"
 	"github.com/luisfernandomoraes/order-packing-api/internal/handlers"
 	"github.com/luisfernandomoraes/order-packing-api/internal/middleware"
 )
@@ -13,9 +14,22 @@ func (s *Server) setupRoutes() http.Handler {
 	mux := http.NewServeMux()
 
 	// Create handlers
-	calculateHandler := handlers.NewCalculateHandler(s.calculator)
-	packSizesHandler := handlers.NewPackSizesHandler(s.calculator)
+	// The default strategy was validated with the configuration; an empty one
+	// selects the fewest packs.
+	strategy, _ := domain.ParseStrategy(s.config.DefaultStrategy)
+
+	calculateHandler := handlers.NewCalculateHandler(s.calculator,
+		handlers.WithStrategy(strategy),
+		handlers.WithCatalog(s.catalog),
+		handlers.WithInventory(s.inventory),
+	)
+	batchCalculateHandler := handlers.NewBatchCalculateHandler(s.calculator, handlers.WithBatchStrategy(strategy))
+	packSizesHandler := handlers.NewPackSizesHandler(s.calculator, handlers.WithPackSizeRepository(s.repository))
+	ordersHandler := handlers.NewOrdersHandler(s.calculator, s.catalog, handlers.WithOrdersStrategy(strategy))
+	productsHandler := handlers.NewProductsHandler(s.catalog)
+	inventoryHandler := handlers.NewInventoryHandler(s.inventory)
 	healthHandler := handlers.NewHealthHandler()
+	metricsHandler := handlers.NewMetricsHandler(s.calculator)
 
 	// Swagger documentation
 	mux.HandleFunc("/swagger/", httpSwagger.WrapHandler)
@@ -28,6 +42,20 @@ func (s *Server) setupRoutes() http.Handler {
 		middleware.Recovery,
 	))
 
+	mux.HandleFunc("/api/calculate/stream", middleware.Chain(
+		calculateHandler.HandleStream,
+		middleware.CORS,
+		middleware.Logging,
+		middleware.Recovery,
+	))
+
+	mux.HandleFunc("/api/calculate/batch", middleware.Chain(
+		batchCalculateHandler.Handle,
+		middleware.CORS,
+		middleware.Logging,
+		middleware.Recovery,
+	))
+
 	mux.HandleFunc("/api/pack-sizes", middleware.Chain(
 		packSizesHandler.Handle,
 		middleware.CORS,
@@ -35,12 +63,102 @@ func (s *Server) setupRoutes() http.Handler {
 		middleware.Recovery,
 	))
 
+	mux.HandleFunc("/api/pack-sizes/versions", middleware.Chain(
+		packSizesHandler.HandleVersions,
+		middleware.CORS,
+		middleware.Logging,
+		middleware.Recovery,
+	))
+
+	mux.HandleFunc("/api/pack-sizes/diff", middleware.Chain(
+		packSizesHandler.HandleDiff,
+		middleware.CORS,
+		middleware.Logging,
+		middleware.Recovery,
+	))
+
+	mux.HandleFunc("/api/pack-sizes/rollback", middleware.Chain(
+		packSizesHandler.HandleRollback,
+		middleware.CORS,
+		middleware.Logging,
+		middleware.Recovery,
+	))
+
+	mux.HandleFunc("/api/orders", middleware.Chain(
+		ordersHandler.Handle,
+		middleware.CORS,
+		middleware.Logging,
+		middleware.Recovery,
+	))
+
+	mux.HandleFunc("/api/products", middleware.Chain(
+		productsHandler.Handle,
+		middleware.CORS,
+		middleware.Logging,
+		middleware.Recovery,
+	))
+
+	mux.HandleFunc("/api/products/{sku}", middleware.Chain(
+		productsHandler.HandleProduct,
+		middleware.CORS,
+		middleware.Logging,
+		middleware.Recovery,
+	))
+
+	mux.HandleFunc("/api/products/{sku}/pack-sizes", middleware.Chain(
+		productsHandler.HandlePackSizes,
+		middleware.CORS,
+		middleware.Logging,
+		middleware.Recovery,
+	))
+
+	mux.HandleFunc("/api/inventory", middleware.Chain(
+		inventoryHandler.HandleLevels,
+		middleware.CORS,
+		middleware.Logging,
+		middleware.Recovery,
+	))
+
+	mux.HandleFunc("/api/inventory/receive", middleware.Chain(
+		inventoryHandler.HandleReceive,
+		middleware.CORS,
+		middleware.Logging,
+		middleware.Recovery,
+	))
+
+	mux.HandleFunc("/api/inventory/reservations", middleware.Chain(
+		inventoryHandler.HandleReservations,
+		middleware.CORS,
+		middleware.Logging,
+		middleware.Recovery,
+	))
+
+	mux.HandleFunc("/api/inventory/reservations/{id}/confirm", middleware.Chain(
+		inventoryHandler.HandleConfirm,
+		middleware.CORS,
+		middleware.Logging,
+		middleware.Recovery,
+	))
+
+	mux.HandleFunc("/api/inventory/reservations/{id}/release", middleware.Chain(
+		inventoryHandler.HandleRelease,
+		middleware.CORS,
+		middleware.Logging,
+		middleware.Recovery,
+	))
+
 	mux.HandleFunc("/health", middleware.Chain(
 		healthHandler.Handle,
 		middleware.CORS,
 		middleware.Recovery,
 	))
 
+	mux.HandleFunc("/metrics", middleware.Chain(
+		metricsHandler.Handle,
+		middleware.CORS,
+		middleware.Recovery,
+	))
+
 	// Static files
 	fs := http.FileServer(http.Dir("./static"))
 	mux.Handle("/", fs)
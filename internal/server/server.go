@@ -7,22 +7,57 @@ import (
 
 	"github.com/luisfernandomoraes/order-packing-api/internal/config"
 	"github.com/luisfernandomoraes/order-packing-api/internal/domain"
+	"github.com/luisfernandomoraes/order-packing-api/internal/storage"
 )
 
 // Server represents the HTTP server
 type Server struct {
 	httpServer *http.Server
 	calculator *domain.PackCalculator
+	catalog    *domain.Catalog
+	inventory  *domain.Inventory
 	config     config.Config
+	repository storage.PackSizeRepository
+}
+
+// Option configures optional Server dependencies
+type Option func(*Server)
+
+// WithPackSizeRepository persists pack sizes updated through the API
+func WithPackSizeRepository(repository storage.PackSizeRepository) Option {
+	return func(s *Server) {
+		s.repository = repository
+	}
+}
+
+// WithCatalog serves the given product catalog instead of an empty one
+func WithCatalog(catalog *domain.Catalog) Option {
+	return func(s *Server) {
+		s.catalog = catalog
+	}
+}
+
+// WithInventory serves the given inventory instead of an empty one. Inventories
+// are kept in memory only, so the stock and reservations are lost on restart.
+func WithInventory(inventory *domain.Inventory) Option {
+	return func(s *Server) {
+		s.inventory = inventory
+	}
 }
 
 // New creates a new Server instance
-func New(cfg config.Config, calculator *domain.PackCalculator) *Server {
+func New(cfg config.Config, calculator *domain.PackCalculator, opts ...Option) *Server {
 	srv := &Server{
 		calculator: calculator,
+		catalog:    domain.NewCatalog(domain.WithLimits(calculator.GetLimits())),
+		inventory:  domain.NewInventory(),
 		config:     cfg,
 	}
 
+	for _, opt := range opts {
+		opt(srv)
+	}
+
 	srv.httpServer = &http.Server{
 		Addr:         ":" + cfg.Port,
 		Handler:      srv.setupRoutes(),
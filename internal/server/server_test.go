@@ -9,6 +9,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
 	"time"
 
@@ -116,6 +117,91 @@ func TestIntegration_Endpoints(t *testing.T) {
 		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
 	})
 
+	t.Run("calculate batch POST returns per-order results", func(t *testing.T) {
+		payload := map[string]interface{}{
+			"orders": []map[string]interface{}{
+				{"reference_id": "A", "order": 251},
+				{"reference_id": "B", "order": -1},
+			},
+		}
+		resp := doJSONRequest(t, client, http.MethodPost, ts.URL+"/api/calculate/batch", payload)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var body struct {
+			Results []struct {
+				ReferenceID string `json:"reference_id"`
+				Result      *struct {
+					TotalItems int `json:"total_items"`
+				} `json:"result"`
+				Error string `json:"error"`
+			} `json:"results"`
+			Succeeded int `json:"succeeded"`
+			Failed    int `json:"failed"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+
+		require.Len(t, body.Results, 2)
+		assert.Equal(t, 1, body.Succeeded)
+		assert.Equal(t, 1, body.Failed)
+		assert.Equal(t, "A", body.Results[0].ReferenceID)
+		require.NotNil(t, body.Results[0].Result)
+		assert.Equal(t, 500, body.Results[0].Result.TotalItems)
+		assert.Equal(t, "B", body.Results[1].ReferenceID)
+		assert.Equal(t, "Order must be positive", body.Results[1].Error)
+	})
+
+	t.Run("calculate stream POST returns NDJSON lines", func(t *testing.T) {
+		body := strings.NewReader("{\"order\": 251}\n{\"order\": -1}\n")
+		resp, err := client.Post(ts.URL+"/api/calculate/stream", "application/x-ndjson", body)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "application/x-ndjson", resp.Header.Get("Content-Type"))
+
+		decoder := json.NewDecoder(resp.Body)
+
+		var first map[string]interface{}
+		require.NoError(t, decoder.Decode(&first))
+		assert.Equal(t, float64(500), first["total_items"])
+
+		var second map[string]interface{}
+		require.NoError(t, decoder.Decode(&second))
+		assert.Equal(t, "Order must be positive", second["error"])
+	})
+
+	t.Run("calculate stream POST reads a piped body while responding", func(t *testing.T) {
+		const lines = 1000
+
+		reader, writer := io.Pipe()
+		go func() {
+			for range lines {
+				if _, err := io.WriteString(writer, "{\"order\": 251}\n"); err != nil {
+					return
+				}
+			}
+			_ = writer.Close()
+		}()
+
+		resp, err := client.Post(ts.URL+"/api/calculate/stream", "application/x-ndjson", reader)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		decoder := json.NewDecoder(resp.Body)
+		count := 0
+		for decoder.More() {
+			var line map[string]interface{}
+			require.NoError(t, decoder.Decode(&line))
+			require.Equal(t, float64(500), line["total_items"], "line %d: %v", count+1, line)
+			count++
+		}
+		assert.Equal(t, lines, count)
+	})
+
 	t.Run("calculate method not allowed", func(t *testing.T) {
 		req, err := http.NewRequest(http.MethodDelete, ts.URL+"/api/calculate", nil)
 		require.NoError(t, err)
@@ -173,6 +259,136 @@ func TestIntegration_Endpoints(t *testing.T) {
 		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
 	})
 
+	t.Run("pack sizes rollback restores a previous version", func(t *testing.T) {
+		resp := doJSONRequest(t, client, http.MethodPost, ts.URL+"/api/pack-sizes/rollback", map[string]int{"version": 1})
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var body struct {
+			Version   int   `json:"version"`
+			PackSizes []int `json:"pack_sizes"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+
+		assert.Equal(t, 3, body.Version)
+		assert.Equal(t, []int{250, 500, 1000}, body.PackSizes)
+	})
+
+	t.Run("pack sizes versions lists the history", func(t *testing.T) {
+		resp, err := client.Get(ts.URL + "/api/pack-sizes/versions")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var body struct {
+			Current  int `json:"current"`
+			Versions []struct {
+				PackSizes []int `json:"pack_sizes"`
+			} `json:"versions"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+
+		assert.Equal(t, 3, body.Current)
+		require.Len(t, body.Versions, 3)
+		assert.Equal(t, []int{250, 750}, body.Versions[1].PackSizes)
+	})
+
+	t.Run("pack sizes diff compares versions", func(t *testing.T) {
+		resp, err := client.Get(ts.URL + "/api/pack-sizes/diff?from=2&to=3")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"from":2,"to":3,"added":[500,1000],"removed":[750],"unchanged":[250],"changes":[]}`, string(body))
+	})
+
+	t.Run("products own their pack sizes", func(t *testing.T) {
+		product := map[string]interface{}{"sku": "WIDGET", "pack_sizes": []int{23, 31, 53}}
+		resp := doJSONRequest(t, client, http.MethodPost, ts.URL+"/api/products", product)
+		resp.Body.Close()
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		resp = doJSONRequest(t, client, http.MethodPost, ts.URL+"/api/calculate", map[string]interface{}{"order": 263, "sku": "WIDGET"})
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var body struct {
+			TotalItems int   `json:"total_items"`
+			PackSizes  []int `json:"pack_sizes"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+
+		assert.Equal(t, 263, body.TotalItems)
+		assert.Equal(t, []int{23, 31, 53}, body.PackSizes)
+
+		resp, err := client.Get(ts.URL + "/api/products/WIDGET/pack-sizes")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("orders pack every line", func(t *testing.T) {
+		payload := map[string]interface{}{"lines": []map[string]interface{}{
+			{"quantity": 263, "sku": "WIDGET"},
+			{"quantity": 12, "pack_sizes": []int{5, 10}},
+		}}
+		resp := doJSONRequest(t, client, http.MethodPost, ts.URL+"/api/orders", payload)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var body struct {
+			Lines      []json.RawMessage `json:"lines"`
+			TotalItems int               `json:"total_items"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+
+		assert.Len(t, body.Lines, 2)
+		assert.Equal(t, 263+15, body.TotalItems)
+	})
+
+	t.Run("inventory reservations ship packs", func(t *testing.T) {
+		resp := doJSONRequest(t, client, http.MethodPost, ts.URL+"/api/inventory/receive", map[string]int{"pack_size": 500, "count": 2})
+		resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		resp = doJSONRequest(t, client, http.MethodPost, ts.URL+"/api/calculate", map[string]interface{}{"order": 1000, "reserve": true})
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var calculated struct {
+			Packs         map[int]int `json:"packs"`
+			ReservationID string      `json:"reservation_id"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&calculated))
+		assert.Equal(t, map[int]int{500: 2}, calculated.Packs)
+
+		confirm := doJSONRequest(t, client, http.MethodPost, ts.URL+"/api/inventory/reservations/"+calculated.ReservationID+"/confirm", nil)
+		confirm.Body.Close()
+		assert.Equal(t, http.StatusOK, confirm.StatusCode)
+
+		levels, err := client.Get(ts.URL + "/api/inventory")
+		require.NoError(t, err)
+		defer levels.Body.Close()
+
+		var body struct {
+			Levels []struct {
+				PackSize int `json:"pack_size"`
+				OnHand   int `json:"on_hand"`
+			} `json:"levels"`
+		}
+		require.NoError(t, json.NewDecoder(levels.Body).Decode(&body))
+		require.Len(t, body.Levels, 1)
+		assert.Equal(t, 0, body.Levels[0].OnHand)
+	})
+
 	t.Run("pack sizes method not allowed", func(t *testing.T) {
 		req, err := http.NewRequest(http.MethodDelete, ts.URL+"/api/pack-sizes", nil)
 		require.NoError(t, err)
@@ -205,7 +421,7 @@ func TestIntegration_Endpoints(t *testing.T) {
 
 		assert.Equal(t, http.StatusOK, resp.StatusCode)
 		assert.Equal(t, "*", resp.Header.Get("Access-Control-Allow-Origin"))
-		assert.Equal(t, "GET, POST, OPTIONS", resp.Header.Get("Access-Control-Allow-Methods"))
+		assert.Equal(t, "GET, POST, DELETE, OPTIONS", resp.Header.Get("Access-Control-Allow-Methods"))
 	})
 }
 
@@ -0,0 +1,154 @@
+package storage
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// versionsBucket holds one JSON encoded PackSizeVersion per key. Keys are the
+// big-endian version numbers, so the cursor walks the history in order.
+var versionsBucket = []byte("pack_size_versions")
+
+// BoltRepository stores the pack sizes history in an embedded bbolt database
+type BoltRepository struct {
+	db *bolt.DB
+}
+
+// NewBoltRepository opens, or creates, the bbolt database at path, creating
+// its directory when missing.
+// It fails if another process holds the database open for more than a second.
+func NewBoltRepository(path string) (*BoltRepository, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("creating pack sizes directory: %w", err)
+	}
+
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("opening pack sizes database: %w", err)
+	}
+
+	return &BoltRepository{db: db}, nil
+}
+
+// Current returns the latest version, or ErrNotFound if nothing was saved yet
+func (r *BoltRepository) Current() (PackSizeVersion, error) {
+	var version PackSizeVersion
+
+	err := r.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(versionsBucket)
+		if bucket == nil {
+			return ErrNotFound
+		}
+
+		key, data := bucket.Cursor().Last()
+		if key == nil {
+			return ErrNotFound
+		}
+
+		return decodeVersion(data, &version)
+	})
+
+	return version, err
+}
+
+// Version returns the version with the given number
+func (r *BoltRepository) Version(number int) (PackSizeVersion, error) {
+	var version PackSizeVersion
+
+	if number < 1 {
+		return version, ErrVersionNotFound
+	}
+
+	err := r.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(versionsBucket)
+		if bucket == nil {
+			return ErrVersionNotFound
+		}
+
+		data := bucket.Get(versionKey(uint64(number)))
+		if data == nil {
+			return ErrVersionNotFound
+		}
+
+		return decodeVersion(data, &version)
+	})
+
+	return version, err
+}
+
+// Versions returns every version, oldest first
+func (r *BoltRepository) Versions() ([]PackSizeVersion, error) {
+	var versions []PackSizeVersion
+
+	err := r.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(versionsBucket)
+		if bucket == nil {
+			return nil
+		}
+
+		return bucket.ForEach(func(_, data []byte) error {
+			var version PackSizeVersion
+			if err := decodeVersion(data, &version); err != nil {
+				return err
+			}
+
+			versions = append(versions, version)
+			return nil
+		})
+	})
+
+	return versions, err
+}
+
+// Append adds the change as the next version in a single transaction
+func (r *BoltRepository) Append(change PackSizeChange) (PackSizeVersion, error) {
+	var version PackSizeVersion
+
+	err := r.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(versionsBucket)
+		if err != nil {
+			return err
+		}
+
+		sequence, err := bucket.NextSequence()
+		if err != nil {
+			return err
+		}
+
+		version = newVersion(int(sequence)-1, change)
+
+		data, err := json.Marshal(version)
+		if err != nil {
+			return fmt.Errorf("encoding pack sizes: %w", err)
+		}
+
+		return bucket.Put(versionKey(sequence), data)
+	})
+	if err != nil {
+		return PackSizeVersion{}, err
+	}
+
+	return version, nil
+}
+
+// Close closes the underlying database
+func (r *BoltRepository) Close() error {
+	return r.db.Close()
+}
+
+func versionKey(number uint64) []byte {
+	return binary.BigEndian.AppendUint64(nil, number)
+}
+
+func decodeVersion(data []byte, version *PackSizeVersion) error {
+	if err := json.Unmarshal(data, version); err != nil {
+		return fmt.Errorf("decoding pack sizes: %w", err)
+	}
+	return nil
+}
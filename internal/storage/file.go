@@ -0,0 +1,135 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// FileRepository stores the pack sizes history as a JSON document on disk
+type FileRepository struct {
+	path string
+
+	// mu serializes appends, which read and rewrite the whole document
+	mu sync.Mutex
+}
+
+// storedHistory is the JSON document written by FileRepository
+type storedHistory struct {
+	Versions []PackSizeVersion `json:"versions"`
+}
+
+// NewFileRepository creates a FileRepository backed by the file at path.
+// The file and its directory are created on the first Append.
+func NewFileRepository(path string) *FileRepository {
+	return &FileRepository{path: path}
+}
+
+// Current returns the latest version, or ErrNotFound if nothing was saved yet
+func (r *FileRepository) Current() (PackSizeVersion, error) {
+	versions, err := r.Versions()
+	if err != nil {
+		return PackSizeVersion{}, err
+	}
+
+	if len(versions) == 0 {
+		return PackSizeVersion{}, ErrNotFound
+	}
+	return versions[len(versions)-1], nil
+}
+
+// Version returns the version with the given number
+func (r *FileRepository) Version(number int) (PackSizeVersion, error) {
+	versions, err := r.Versions()
+	if err != nil {
+		return PackSizeVersion{}, err
+	}
+
+	return findVersion(versions, number)
+}
+
+// Versions returns every version, oldest first. A missing file is an empty history.
+func (r *FileRepository) Versions() ([]PackSizeVersion, error) {
+	data, err := os.ReadFile(r.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading pack sizes: %w", err)
+	}
+
+	var stored storedHistory
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, fmt.Errorf("decoding pack sizes from %s: %w", r.path, err)
+	}
+
+	return stored.Versions, nil
+}
+
+// Append adds the change as the next version and rewrites the document
+func (r *FileRepository) Append(change PackSizeChange) (PackSizeVersion, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	versions, err := r.Versions()
+	if err != nil {
+		return PackSizeVersion{}, err
+	}
+
+	version := newVersion(len(versions), change)
+	if err := r.write(storedHistory{Versions: append(versions, version)}); err != nil {
+		return PackSizeVersion{}, err
+	}
+
+	return version, nil
+}
+
+// write saves the document to a temporary file in the same directory, syncs it
+// and renames it over the previous file, so readers never observe a partially
+// written document.
+func (r *FileRepository) write(stored storedHistory) error {
+	data, err := json.MarshalIndent(stored, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding pack sizes: %w", err)
+	}
+
+	dir := filepath.Dir(r.path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("creating pack sizes directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(r.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("creating temporary pack sizes file: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
+
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("writing pack sizes: %w", err)
+	}
+
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("syncing pack sizes: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("closing pack sizes file: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), r.path); err != nil {
+		return fmt.Errorf("replacing pack sizes file: %w", err)
+	}
+
+	return nil
+}
+
+// Close is a no-op; the file is only open while reading or writing
+func (r *FileRepository) Close() error {
+	return nil
+}
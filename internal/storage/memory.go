@@ -0,0 +1,61 @@
+package storage
+
+import (
+	"slices"
+	"sync"
+)
+
+// MemoryRepository keeps the pack sizes history in memory; it is lost on restart
+type MemoryRepository struct {
+	mu       sync.RWMutex
+	versions []PackSizeVersion
+}
+
+// NewMemoryRepository creates an empty MemoryRepository
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{}
+}
+
+// Current returns the latest version, or ErrNotFound if the history is empty
+func (r *MemoryRepository) Current() (PackSizeVersion, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if len(r.versions) == 0 {
+		return PackSizeVersion{}, ErrNotFound
+	}
+	return r.versions[len(r.versions)-1], nil
+}
+
+// Version returns the version with the given number
+func (r *MemoryRepository) Version(number int) (PackSizeVersion, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return findVersion(r.versions, number)
+}
+
+// Versions returns every version, oldest first
+func (r *MemoryRepository) Versions() ([]PackSizeVersion, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return slices.Clone(r.versions), nil
+}
+
+// Append adds the change as the next version
+func (r *MemoryRepository) Append(change PackSizeChange) (PackSizeVersion, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	version := newVersion(len(r.versions), change)
+	version.PackSizes = slices.Clone(version.PackSizes)
+	r.versions = append(r.versions, version)
+
+	return version, nil
+}
+
+// Close is a no-op
+func (r *MemoryRepository) Close() error {
+	return nil
+}
@@ -0,0 +1,116 @@
+// Package storage persists the configured pack sizes across restarts.
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Supported values for the PACK_SIZES_STORE setting
+const (
+	DriverNone = "none"
+	DriverFile = "file"
+	DriverBolt = "bolt"
+)
+
+var (
+	// ErrNotFound is returned by Current when no pack sizes have been saved yet.
+	ErrNotFound = errors.New("no pack sizes stored")
+
+	// ErrVersionNotFound is returned by Version for unknown version numbers.
+	ErrVersionNotFound = errors.New("pack sizes version not found")
+)
+
+// PackSizeVersion is one numbered revision of the pack sizes configuration.
+// Costs and ShipmentCost are in the smallest currency unit, weights in grams
+// and volumes in cubic centimetres.
+type PackSizeVersion struct {
+	Version         int                  `json:"version"`
+	PackSizes       []int                `json:"pack_sizes"`
+	Costs           map[int]int          `json:"costs,omitempty"`
+	ShipmentCost    int                  `json:"shipment_cost,omitempty"`
+	Weights         map[int]int          `json:"weights,omitempty"`
+	Volumes         map[int]int          `json:"volumes,omitempty"`
+	MaxParcelWeight int                  `json:"max_parcel_weight,omitempty"`
+	MaxParcelVolume int                  `json:"max_parcel_volume,omitempty"`
+	Rules           map[int]PackSizeRule `json:"rules,omitempty"`
+	CreatedAt       time.Time            `json:"created_at"`
+	Author          string               `json:"author,omitempty"`
+	Reason          string               `json:"reason,omitempty"`
+}
+
+// PackSizeRule restricts how a pack size may be used; zero fields do not restrict
+type PackSizeRule struct {
+	MaxPacks int `json:"max_packs,omitempty"`
+	MinOrder int `json:"min_order,omitempty"`
+	MaxOrder int `json:"max_order,omitempty"`
+}
+
+// PackSizeChange describes a new pack sizes configuration to append to the history
+type PackSizeChange struct {
+	PackSizes       []int
+	Costs           map[int]int
+	ShipmentCost    int
+	Weights         map[int]int
+	Volumes         map[int]int
+	MaxParcelWeight int
+	MaxParcelVolume int
+	Rules           map[int]PackSizeRule
+	Author          string
+	Reason          string
+}
+
+// PackSizeRepository keeps the full history of pack sizes configurations.
+// Versions are numbered from 1 in the order they were appended and are never
+// modified or removed; the latest version is the current configuration.
+// Append must be atomic: after a crash the history contains either the
+// previous versions or the previous versions plus the new one.
+type PackSizeRepository interface {
+	Current() (PackSizeVersion, error)
+	Version(number int) (PackSizeVersion, error)
+	Versions() ([]PackSizeVersion, error)
+	Append(change PackSizeChange) (PackSizeVersion, error)
+	Close() error
+}
+
+// Open returns the repository for the given driver, storing its data at path.
+// The none driver keeps the history in memory only.
+func Open(driver, path string) (PackSizeRepository, error) {
+	switch driver {
+	case DriverNone:
+		return NewMemoryRepository(), nil
+	case DriverFile:
+		return NewFileRepository(path), nil
+	case DriverBolt:
+		return NewBoltRepository(path)
+	default:
+		return nil, fmt.Errorf("unknown pack sizes store %q", driver)
+	}
+}
+
+// newVersion numbers the change as the version following previous
+func newVersion(previous int, change PackSizeChange) PackSizeVersion {
+	return PackSizeVersion{
+		Version:         previous + 1,
+		PackSizes:       change.PackSizes,
+		Costs:           change.Costs,
+		ShipmentCost:    change.ShipmentCost,
+		Weights:         change.Weights,
+		Volumes:         change.Volumes,
+		MaxParcelWeight: change.MaxParcelWeight,
+		MaxParcelVolume: change.MaxParcelVolume,
+		Rules:           change.Rules,
+		CreatedAt:       time.Now().UTC(),
+		Author:          change.Author,
+		Reason:          change.Reason,
+	}
+}
+
+// findVersion returns the version with the given number from an ordered history
+func findVersion(versions []PackSizeVersion, number int) (PackSizeVersion, error) {
+	if number < 1 || number > len(versions) {
+		return PackSizeVersion{}, ErrVersionNotFound
+	}
+	return versions[number-1], nil
+}
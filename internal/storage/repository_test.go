@@ -0,0 +1,241 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var repositories = []struct {
+	name       string
+	persistent bool
+	open       func(t *testing.T, path string) PackSizeRepository
+}{
+	{
+		name: "memory",
+		open: func(_ *testing.T, _ string) PackSizeRepository {
+			return NewMemoryRepository()
+		},
+	},
+	{
+		name:       "file",
+		persistent: true,
+		open: func(_ *testing.T, path string) PackSizeRepository {
+			return NewFileRepository(path)
+		},
+	},
+	{
+		name:       "bolt",
+		persistent: true,
+		open: func(t *testing.T, path string) PackSizeRepository {
+			repository, err := NewBoltRepository(path)
+			require.NoError(t, err)
+			return repository
+		},
+	},
+}
+
+func TestPackSizeRepositories(t *testing.T) {
+	for _, tt := range repositories {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Run("should start with an empty history", func(t *testing.T) {
+				repository := tt.open(t, filepath.Join(t.TempDir(), "pack-sizes"))
+				defer repository.Close()
+
+				_, err := repository.Current()
+				assert.ErrorIs(t, err, ErrNotFound)
+
+				versions, err := repository.Versions()
+				require.NoError(t, err)
+				assert.Empty(t, versions)
+
+				_, err = repository.Version(1)
+				assert.ErrorIs(t, err, ErrVersionNotFound)
+			})
+
+			t.Run("should number appended versions", func(t *testing.T) {
+				repository := tt.open(t, filepath.Join(t.TempDir(), "pack-sizes"))
+				defer repository.Close()
+
+				first, err := repository.Append(PackSizeChange{PackSizes: []int{250, 500}, Author: "ana", Reason: "launch"})
+				require.NoError(t, err)
+				second, err := repository.Append(PackSizeChange{PackSizes: []int{23, 31, 53}})
+				require.NoError(t, err)
+
+				assert.Equal(t, 1, first.Version)
+				assert.Equal(t, "ana", first.Author)
+				assert.Equal(t, "launch", first.Reason)
+				assert.False(t, first.CreatedAt.IsZero())
+				assert.Equal(t, 2, second.Version)
+
+				current, err := repository.Current()
+				require.NoError(t, err)
+				assert.Equal(t, second, current)
+
+				version, err := repository.Version(1)
+				require.NoError(t, err)
+				assert.Equal(t, first, version)
+
+				versions, err := repository.Versions()
+				require.NoError(t, err)
+				assert.Equal(t, []PackSizeVersion{first, second}, versions)
+
+				for _, number := range []int{0, 3, -1} {
+					_, err := repository.Version(number)
+					assert.ErrorIs(t, err, ErrVersionNotFound)
+				}
+			})
+
+			t.Run("should number concurrent appends without gaps", func(t *testing.T) {
+				repository := tt.open(t, filepath.Join(t.TempDir(), "pack-sizes"))
+				defer repository.Close()
+
+				var wg sync.WaitGroup
+				for i := 1; i <= 10; i++ {
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						_, err := repository.Append(PackSizeChange{PackSizes: []int{i}})
+						assert.NoError(t, err)
+					}()
+				}
+				wg.Wait()
+
+				versions, err := repository.Versions()
+				require.NoError(t, err)
+				require.Len(t, versions, 10)
+				for i, version := range versions {
+					assert.Equal(t, i+1, version.Version)
+				}
+			})
+
+			if !tt.persistent {
+				return
+			}
+
+			t.Run("should keep the history across reopening", func(t *testing.T) {
+				path := filepath.Join(t.TempDir(), "pack-sizes")
+
+				repository := tt.open(t, path)
+				_, err := repository.Append(PackSizeChange{
+					PackSizes:    []int{100, 200},
+					Costs:        map[int]int{100: 40, 200: 70},
+					ShipmentCost: 500,
+				})
+				require.NoError(t, err)
+				require.NoError(t, repository.Close())
+
+				reopened := tt.open(t, path)
+				defer reopened.Close()
+
+				current, err := reopened.Current()
+				require.NoError(t, err)
+				assert.Equal(t, 1, current.Version)
+				assert.Equal(t, []int{100, 200}, current.PackSizes)
+				assert.Equal(t, map[int]int{100: 40, 200: 70}, current.Costs)
+				assert.Equal(t, 500, current.ShipmentCost)
+			})
+		})
+	}
+}
+
+func TestFileRepository_Append(t *testing.T) {
+	t.Run("should create missing directories", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "data", "pack-sizes.json")
+
+		_, err := NewFileRepository(path).Append(PackSizeChange{PackSizes: []int{250}})
+		require.NoError(t, err)
+		assert.FileExists(t, path)
+	})
+
+	t.Run("should not leave temporary files behind", func(t *testing.T) {
+		dir := t.TempDir()
+		repository := NewFileRepository(filepath.Join(dir, "pack-sizes.json"))
+
+		_, err := repository.Append(PackSizeChange{PackSizes: []int{250}})
+		require.NoError(t, err)
+		_, err = repository.Append(PackSizeChange{PackSizes: []int{500}})
+		require.NoError(t, err)
+
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Equal(t, "pack-sizes.json", entries[0].Name())
+	})
+
+	t.Run("should keep the previous history when the write fails", func(t *testing.T) {
+		dir := t.TempDir()
+		repository := NewFileRepository(filepath.Join(dir, "pack-sizes.json"))
+		_, err := repository.Append(PackSizeChange{PackSizes: []int{250}})
+		require.NoError(t, err)
+
+		require.NoError(t, os.Chmod(dir, 0o500))
+		defer os.Chmod(dir, 0o700)
+
+		if _, err := repository.Append(PackSizeChange{PackSizes: []int{500}}); err == nil {
+			t.Skip("directory permissions are not enforced for this user")
+		}
+
+		versions, err := repository.Versions()
+		require.NoError(t, err)
+		require.Len(t, versions, 1)
+		assert.Equal(t, []int{250}, versions[0].PackSizes)
+	})
+}
+
+func TestNewBoltRepository(t *testing.T) {
+	t.Run("should create missing directories", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "data", "pack-sizes.db")
+
+		repository, err := NewBoltRepository(path)
+		require.NoError(t, err)
+		defer repository.Close()
+
+		_, err = repository.Append(PackSizeChange{PackSizes: []int{250}})
+		require.NoError(t, err)
+		assert.FileExists(t, path)
+	})
+}
+
+func TestFileRepository_Current(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pack-sizes.json")
+	require.NoError(t, os.WriteFile(path, []byte("{not json"), 0o600))
+
+	_, err := NewFileRepository(path).Current()
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrNotFound)
+}
+
+func TestOpen(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name     string
+		driver   string
+		expected PackSizeRepository
+		wantErr  bool
+	}{
+		{name: "should keep pack sizes in memory only", driver: DriverNone, expected: &MemoryRepository{}},
+		{name: "should open a file repository", driver: DriverFile, expected: &FileRepository{}},
+		{name: "should open a bolt repository", driver: DriverBolt, expected: &BoltRepository{}},
+		{name: "should reject unknown drivers", driver: "redis", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repository, err := Open(tt.driver, filepath.Join(dir, tt.driver))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			defer repository.Close()
+			assert.IsType(t, tt.expected, repository)
+		})
+	}
+}